@@ -0,0 +1,148 @@
+package batch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+
+	"infra/engine"
+	"infra/game/rng"
+	"infra/logging"
+)
+
+// Options describes a batch of games that share a configuration.
+type Options struct {
+	// Games is the number of games to play.
+	Games int
+	// Workers bounds how many games run at once.
+	Workers int
+	// Seed derives the seed of every game in the batch.
+	Seed int64
+	// Dir is the run directory. Each game writes its logs to Dir/games/<index>
+	// and the aggregate summary is written to Dir/summary.json.
+	Dir string
+	// Config is the template for each game. Its Seed, OutputPath and CSVPath
+	// are set per game.
+	Config   engine.Config
+	Registry engine.Registry
+}
+
+// GameSummary is the outcome of a single game in a batch. Seed reproduces the
+// game on its own.
+type GameSummary struct {
+	Index     int
+	Seed      int64
+	Outcome   logging.Outcome
+	Level     uint
+	Survivors uint
+}
+
+// Run plays every game in the batch and writes the per-game logs and the
+// aggregate summary into the run directory.
+func Run(ctx context.Context, opts Options) (Summary, error) {
+	if opts.Games <= 0 {
+		return Summary{}, errors.New("batch: number of games must be positive")
+	}
+	if opts.Workers <= 0 {
+		opts.Workers = 1
+	}
+	if err := os.MkdirAll(opts.Dir, 0777); err != nil {
+		return Summary{}, fmt.Errorf("batch: failed to create run directory: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	seeds := rng.NewSource(opts.Seed).Stream("batch")
+	jobs := make(chan GameSummary)
+	go func() {
+		defer close(jobs)
+		for i := 0; i < opts.Games; i++ {
+			select {
+			case jobs <- GameSummary{Index: i, Seed: seeds.Int63()}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	results := make([]GameSummary, opts.Games)
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+	)
+	for w := 0; w < opts.Workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				res, err := runGame(ctx, opts, job)
+				if err != nil {
+					errOnce.Do(func() {
+						firstErr = err
+						cancel()
+					})
+					return
+				}
+				results[job.Index] = res
+			}
+		}()
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return Summary{}, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return Summary{}, err
+	}
+
+	summary := Summarise(results)
+	if err := writeSummary(filepath.Join(opts.Dir, "summary.json"), summary); err != nil {
+		return Summary{}, err
+	}
+	return summary, nil
+}
+
+func runGame(ctx context.Context, opts Options, job GameSummary) (GameSummary, error) {
+	gameDir := filepath.Join(opts.Dir, "games", strconv.Itoa(job.Index))
+
+	cfg := opts.Config
+	cfg.Seed = job.Seed
+	if cfg.RunID != "" {
+		cfg.RunID = fmt.Sprintf("%s/%d", cfg.RunID, job.Index)
+	}
+	cfg.OutputPath = filepath.Join(gameDir, "output.json")
+	cfg.CSVPath = filepath.Join(gameDir, "gameLog.csv")
+
+	game, err := engine.New(cfg, opts.Registry)
+	if err != nil {
+		return job, fmt.Errorf("batch: game %d: %w", job.Index, err)
+	}
+	result, err := game.Run(ctx)
+	if err != nil {
+		return job, fmt.Errorf("batch: game %d: %w", job.Index, err)
+	}
+
+	job.Outcome = result.Outcome
+	job.Level = result.Level
+	job.Survivors = result.Survivors
+	return job, nil
+}
+
+func writeSummary(path string, summary Summary) error {
+	jsonBuf, err := json.MarshalIndent(summary, "", "\t")
+	if err != nil {
+		return fmt.Errorf("batch: failed to marshal summary: %w", err)
+	}
+	if err := os.WriteFile(path, jsonBuf, 0666); err != nil {
+		return fmt.Errorf("batch: failed to write summary: %w", err)
+	}
+	return nil
+}
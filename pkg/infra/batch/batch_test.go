@@ -0,0 +1,124 @@
+package batch_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"infra/batch"
+	cmdline "infra/cmdLine"
+	"infra/config"
+	"infra/engine"
+	"infra/logging"
+	"infra/teams/team3"
+)
+
+func TestRun(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	summary, err := batch.Run(context.Background(), batch.Options{
+		Games:   4,
+		Workers: 2,
+		Seed:    1,
+		Dir:     dir,
+		Config: engine.Config{
+			Game: config.GameConfig{
+				NumLevels:              2,
+				StartingHealthPoints:   1000,
+				StartingAttackStrength: 20,
+				StartingShieldStrength: 20,
+				ThresholdPercentage:    0.01,
+				Stamina:                2000,
+				VotingPreferences:      2,
+				MaxScore:               10,
+			},
+			Sanctions: cmdline.CmdLine{FixedSanctionDuration: 1},
+		},
+		Registry: engine.Registry{"COLLECTIVE": team3.NewAgentThreeNeutral},
+	})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if summary.Games != 4 || len(summary.Results) != 4 {
+		t.Errorf("Run() summarised %d games with %d results, want 4", summary.Games, len(summary.Results))
+	}
+	seeds := make(map[int64]struct{})
+	for i, res := range summary.Results {
+		if res.Index != i {
+			t.Errorf("Results[%d].Index = %d", i, res.Index)
+		}
+		seeds[res.Seed] = struct{}{}
+		for _, name := range []string{"output.json", "gameLog.csv"} {
+			if _, err := os.Stat(filepath.Join(dir, "games", strconv.Itoa(i), name)); err != nil {
+				t.Errorf("missing per-game output: %v", err)
+			}
+		}
+	}
+	if len(seeds) != 4 {
+		t.Errorf("games share seeds: %v", seeds)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "summary.json")); err != nil {
+		t.Errorf("missing summary: %v", err)
+	}
+}
+
+func TestSummarise(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		results       []batch.GameSummary
+		wantWinRate   float64
+		wantMeanLevel float64
+		wantMedian    float64
+	}{
+		{
+			name: "single loss",
+			results: []batch.GameSummary{
+				{Outcome: logging.Loss, Level: 4},
+			},
+			wantWinRate:   0,
+			wantMeanLevel: 4,
+			wantMedian:    0,
+		},
+		{
+			name: "mixed",
+			results: []batch.GameSummary{
+				{Outcome: logging.Win, Level: 60, Survivors: 10},
+				{Outcome: logging.Loss, Level: 20},
+				{Outcome: logging.Win, Level: 60, Survivors: 30},
+				{Outcome: logging.Loss, Level: 40},
+			},
+			wantWinRate:   0.5,
+			wantMeanLevel: 45,
+			wantMedian:    5,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := batch.Summarise(tt.results)
+			if got.WinRate != tt.wantWinRate {
+				t.Errorf("WinRate = %v, want %v", got.WinRate, tt.wantWinRate)
+			}
+			if got.MeanLevel != tt.wantMeanLevel {
+				t.Errorf("MeanLevel = %v, want %v", got.MeanLevel, tt.wantMeanLevel)
+			}
+			if got.Survivors.Median != tt.wantMedian {
+				t.Errorf("Survivors.Median = %v, want %v", got.Survivors.Median, tt.wantMedian)
+			}
+			if !(got.WinRateCI.Low <= got.WinRate && got.WinRate <= got.WinRateCI.High) {
+				t.Errorf("WinRateCI = %+v does not contain %v", got.WinRateCI, got.WinRate)
+			}
+			if got.WinRateCI.Low < 0 || got.WinRateCI.High > 1 {
+				t.Errorf("WinRateCI = %+v outside [0, 1]", got.WinRateCI)
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package batch
+
+import (
+	"math"
+	"sort"
+
+	"infra/logging"
+
+	"gonum.org/v1/gonum/stat"
+)
+
+// z-score for a two-sided 95% confidence interval
+const z95 = 1.959964
+
+// Interval is a two-sided 95% confidence interval.
+type Interval struct {
+	Low  float64
+	High float64
+}
+
+// Distribution describes how many agents survived each game.
+type Distribution struct {
+	Min    uint
+	Max    uint
+	Mean   float64
+	StdDev float64
+	Median float64
+	// Counts maps a number of survivors to the number of games that ended with it.
+	Counts map[uint]int
+}
+
+// Summary aggregates the results of a batch.
+type Summary struct {
+	Games       int
+	Wins        int
+	WinRate     float64
+	WinRateCI   Interval
+	MeanLevel   float64
+	MeanLevelCI Interval
+	Survivors   Distribution
+	Results     []GameSummary
+}
+
+// Summarise aggregates the results of a batch of games.
+func Summarise(results []GameSummary) Summary {
+	summary := Summary{
+		Games:   len(results),
+		Results: results,
+	}
+	if len(results) == 0 {
+		return summary
+	}
+
+	levels := make([]float64, len(results))
+	survivors := make([]float64, len(results))
+	counts := make(map[uint]int)
+	for i, r := range results {
+		if r.Outcome == logging.Win {
+			summary.Wins++
+		}
+		levels[i] = float64(r.Level)
+		survivors[i] = float64(r.Survivors)
+		counts[r.Survivors]++
+	}
+
+	n := float64(len(results))
+	summary.WinRate = float64(summary.Wins) / n
+	summary.WinRateCI = wilsonInterval(summary.Wins, len(results))
+
+	levelMean, levelStdDev := stat.MeanStdDev(levels, nil)
+	summary.MeanLevel = levelMean
+	summary.MeanLevelCI = meanInterval(levelMean, levelStdDev, len(results))
+
+	sort.Float64s(survivors)
+	survivorMean, survivorStdDev := stat.MeanStdDev(survivors, nil)
+	summary.Survivors = Distribution{
+		Min:    uint(survivors[0]),
+		Max:    uint(survivors[len(survivors)-1]),
+		Mean:   survivorMean,
+		StdDev: zeroIfNaN(survivorStdDev),
+		Median: median(survivors),
+		Counts: counts,
+	}
+	return summary
+}
+
+// median is the middle of sorted, or the mean of the two middle values when
+// there is an even number of them.
+func median(sorted []float64) float64 {
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
+
+// wilsonInterval is the Wilson score interval for a binomial proportion, which
+// stays inside [0, 1] even when every game is won or lost.
+func wilsonInterval(successes, trials int) Interval {
+	n := float64(trials)
+	p := float64(successes) / n
+	z2 := z95 * z95
+	centre := (p + z2/(2*n)) / (1 + z2/n)
+	margin := z95 * math.Sqrt(p*(1-p)/n+z2/(4*n*n)) / (1 + z2/n)
+	return Interval{Low: centre - margin, High: centre + margin}
+}
+
+// meanInterval is the normal approximation to the confidence interval of a mean.
+func meanInterval(mean, stdDev float64, n int) Interval {
+	margin := z95 * zeroIfNaN(stdDev) / math.Sqrt(float64(n))
+	return Interval{Low: mean - margin, High: mean + margin}
+}
+
+// the sample standard deviation of a single game is undefined
+func zeroIfNaN(x float64) float64 {
+	if math.IsNaN(x) {
+		return 0
+	}
+	return x
+}
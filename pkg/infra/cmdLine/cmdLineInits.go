@@ -7,5 +7,3 @@ type CmdLine struct {
 	GraduatedSanctions           bool
 	PersistentSanctions          bool
 }
-
-var CmdLineInits CmdLine = CmdLine{}
@@ -5,6 +5,7 @@ import (
 	"infra/logging"
 	"os"
 	"strconv"
+	"strings"
 )
 
 func EnvToUint(key string, def uint) uint {
@@ -49,3 +50,19 @@ func EnvToBool(key string, def bool) bool {
 	}
 	return b
 }
+
+// EnvToList reads a comma separated list, eg. "election,fight,loot".
+func EnvToList(key string, def []string) []string {
+	s := os.Getenv(key)
+	if s == "" {
+		logging.Log(logging.Warn, nil, fmt.Sprintf("%s unset, defaulting to %v\n", key, def))
+
+		return def
+	}
+
+	list := strings.Split(s, ",")
+	for i := range list {
+		list[i] = strings.TrimSpace(list[i])
+	}
+	return list
+}
@@ -0,0 +1,401 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	cmdline "infra/cmdLine"
+	"infra/game/decision"
+
+	"golang.org/x/exp/slices"
+	"gopkg.in/yaml.v3"
+)
+
+// File is everything a configuration file sets, eg. in YAML
+//
+//	Game:
+//	  NumLevels: 40
+//	  VotingStrategy: 1
+//	  PotionScarcity: 0.3
+//	  AgentQuantities: {COLLECTIVE: 30, SELFLESS: 30, SELFISH: 30}
+//	  Team3: {SelfishPersonality: 10}
+//	Sanctions:
+//	  GraduatedSanctions: true
+//
+// or the same in JSON. Field names are matched case insensitively, and
+// anything left out keeps its default (see Default).
+type File struct {
+	Game      GameConfig
+	Sanctions cmdline.CmdLine
+}
+
+// Default is the configuration used where neither a file nor the environment
+// sets a value.
+func Default() File {
+	return File{
+		Game: GameConfig{
+			NumLevels:              60,
+			StartingHealthPoints:   1000,
+			StartingAttackStrength: 20,
+			StartingShieldStrength: 20,
+			ThresholdPercentage:    0.01,
+			Stamina:                2000,
+			VotingStrategy:         decision.SingleChoicePlurality,
+			VotingPreferences:      2,
+			MaxScore:               10,
+			Defection:              true,
+			MessageRounds:          10,
+			AgentDeadlineMs:        1000,
+			CampaignRounds:         3,
+			MaxFightRounds:         100,
+			Stalemate:              Enrage,
+			EnrageRate:             0.1,
+			StalematePenalty:       0.25,
+			PotionScarcity:         0.2,
+			EquipmentScarcity:      0.15,
+			MonsterAbilityChance:   0.1,
+			DamagePolicy:           EvenSplit,
+			FocusFire:              3,
+			DeathDrop:              NoDrop,
+			ConfidenceAbstentions:  IgnoreAbstentions,
+			VoteWeighting:          EqualWeights,
+			VoteBudget:             100,
+			Cabinet:                NoCabinet,
+			Supermajority:          2.0 / 3,
+			Team3: Team3Settings{
+				SelfishPersonality:    25,
+				CollectivePersonality: 50,
+				SelflessPersonality:   75,
+				UpdatePersonality:     true,
+			},
+		},
+		Sanctions: cmdline.CmdLine{
+			FixedSanctionDuration:        1,
+			MaxGraduatedSanctionDuration: 5,
+		},
+	}
+}
+
+// Env is a set of environment variables.
+type Env map[string]string
+
+// Environ is the process's environment.
+func Environ() Env {
+	env := make(Env)
+	for _, kv := range os.Environ() {
+		key, value, _ := strings.Cut(kv, "=")
+		env[key] = value
+	}
+	return env
+}
+
+// Load builds a configuration in layers, each overriding the last: the
+// defaults, the variables in dotenv (eg. from a .env file), the configuration
+// file at path and the process's environment. The result is validated. An
+// empty path reads no file.
+func Load(path string, dotenv Env) (File, error) {
+	f := Default()
+	if err := f.ApplyEnv(dotenv); err != nil {
+		return File{}, err
+	}
+	if path != "" {
+		if err := f.readFile(path); err != nil {
+			return File{}, err
+		}
+	}
+	if err := f.ApplyEnv(Environ()); err != nil {
+		return File{}, err
+	}
+	return f, f.Validate()
+}
+
+// readFile decodes a YAML or JSON file over f. YAML is converted to JSON
+// first, so that both formats follow the same rules.
+func (f *File) readFile(path string) error {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("config: %w", err)
+	}
+	if ext := filepath.Ext(path); ext != ".json" {
+		var doc any
+		if err := yaml.Unmarshal(buf, &doc); err != nil {
+			return fmt.Errorf("config: failed to parse %s: %w", path, err)
+		}
+		if doc == nil {
+			return nil
+		}
+		if buf, err = json.Marshal(doc); err != nil {
+			return fmt.Errorf("config: %s: %w", path, err)
+		}
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(buf))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(f); err != nil {
+		return fmt.Errorf("config: failed to parse %s: %w", path, err)
+	}
+	return nil
+}
+
+// ApplyEnv overrides f with the variables set in env, eg. LEVELS=40 or
+// AGENT_SELFISH_QUANTITY=10.
+func (f *File) ApplyEnv(env Env) error {
+	g, s := &f.Game, &f.Sanctions
+	overrides := []struct {
+		key string
+		set func(string) error
+	}{
+		{"LEVELS", setUint(&g.NumLevels)},
+		{"STARTING_HP", setUint(&g.StartingHealthPoints)},
+		{"STARTING_ATTACK", setUint(&g.StartingAttackStrength)},
+		{"STARTING_SHIELD", setUint(&g.StartingShieldStrength)},
+		{"THRESHOLD_PCT", setFloat(&g.ThresholdPercentage)},
+		{"BASE_STAMINA", setUint(&g.Stamina)},
+		{"VOTING_STRATEGY", setUint(&g.VotingStrategy)},
+		{"VOTING_PREFERENCES", setUint(&g.VotingPreferences)},
+		{"MAX_BALLOT_SCORE", setUint(&g.MaxScore)},
+		{"DEFECTION", setBool(&g.Defection)},
+		{"MESSAGE_ROUNDS", setUint(&g.MessageRounds)},
+		{"AGENT_DEADLINE_MS", setUint(&g.AgentDeadlineMs)},
+		{"CAMPAIGN_ROUNDS", setUint(&g.CampaignRounds)},
+		{"MAX_FIGHT_ROUNDS", setUint(&g.MaxFightRounds)},
+		{"STALEMATE", func(s string) error { g.Stalemate = Stalemate(s); return nil }},
+		{"ENRAGE_RATE", setFloat(&g.EnrageRate)},
+		{"STALEMATE_PENALTY", setFloat(&g.StalematePenalty)},
+		{"STAGES", setList(&g.Stages)},
+		{"POTION_SCARCITY_PCT", setFloat(&g.PotionScarcity)},
+		{"EQUIPMENT_SCARCITY_PCT", setFloat(&g.EquipmentScarcity)},
+		{"MONSTER_ABILITY_PCT", setFloat(&g.MonsterAbilityChance)},
+		{"DAMAGE_POLICY", func(s string) error { g.DamagePolicy = DamagePolicy(s); return nil }},
+		{"FOCUS_FIRE", setUint(&g.FocusFire)},
+		{"DEATH_DROP", func(s string) error { g.DeathDrop = DeathDrop(s); return nil }},
+		{"DEATH_DROP_STAMINA", setFloat(&g.DeathDropStamina)},
+		{"CONFIDENCE_QUORUM", setFloat(&g.ConfidenceQuorum)},
+		{"CONFIDENCE_ABSTENTIONS", func(s string) error { g.ConfidenceAbstentions = Abstentions(s); return nil }},
+		{"PUBLIC_CONFIDENCE_VOTES", setBool(&g.PublicConfidenceVotes)},
+		{"CONFIDENCE_COOLDOWN", setUint(&g.ConfidenceCooldown)},
+		{"VOTE_WEIGHTING", func(s string) error { g.VoteWeighting = VoteWeighting(s); return nil }},
+		{"VOTE_BUDGET", setUint(&g.VoteBudget)},
+		{"CABINET", func(s string) error { g.Cabinet = Cabinet(s); return nil }},
+		{"MIN_TERM", setUint(&g.MinTermLength)},
+		{"MAX_TERM", setUint(&g.MaxTermLength)},
+		{"SUPERMAJORITY", setFloat(&g.Supermajority)},
+		{"SELFISH_PER", setUint(&g.Team3.SelfishPersonality)},
+		{"COLLECTIVE_PER", setUint(&g.Team3.CollectivePersonality)},
+		{"SELFLESS_PER", setUint(&g.Team3.SelflessPersonality)},
+		{"UPDATE_PERSONALITY", setBool(&g.Team3.UpdatePersonality)},
+		{"SANCTION_LEN", setInt(&s.FixedSanctionDuration)},
+		{"MAX_SANCTION_LEN", setInt(&s.MaxGraduatedSanctionDuration)},
+		{"DYNAMIC_SANCTIONS", setBool(&s.DynamicSanctions)},
+		{"GRADUATED_SANCTIONS", setBool(&s.GraduatedSanctions)},
+		{"PERSISTENT_SANCTIONS", setBool(&s.PersistentSanctions)},
+	}
+	for _, o := range overrides {
+		value := env[o.key]
+		if strings.TrimSpace(value) == "" {
+			continue
+		}
+		if err := o.set(strings.TrimSpace(value)); err != nil {
+			return fmt.Errorf("config: %s: %w", o.key, err)
+		}
+	}
+
+	for key, value := range env {
+		if !strings.HasPrefix(key, "AGENT_") || !strings.HasSuffix(key, "_QUANTITY") || len(key) <= len("AGENT__QUANTITY") {
+			continue
+		}
+		name := key[len("AGENT_") : len(key)-len("_QUANTITY")]
+		var quantity uint
+		if err := setUint(&quantity)(strings.TrimSpace(value)); err != nil {
+			return fmt.Errorf("config: %s: %w", key, err)
+		}
+		if g.AgentQuantities == nil {
+			g.AgentQuantities = make(map[string]uint)
+		}
+		g.AgentQuantities[name] = quantity
+	}
+	return nil
+}
+
+func setUint(dst *uint) func(string) error {
+	return func(s string) error {
+		n, err := strconv.ParseUint(s, 10, 0)
+		*dst = uint(n)
+		return err
+	}
+}
+
+func setInt(dst *int) func(string) error {
+	return func(s string) error {
+		n, err := strconv.Atoi(s)
+		*dst = n
+		return err
+	}
+}
+
+func setFloat(dst *float32) func(string) error {
+	return func(s string) error {
+		n, err := strconv.ParseFloat(s, 32)
+		*dst = float32(n)
+		return err
+	}
+}
+
+func setBool(dst *bool) func(string) error {
+	return func(s string) error {
+		b, err := strconv.ParseBool(s)
+		*dst = b
+		return err
+	}
+}
+
+func setList(dst *[]string) func(string) error {
+	return func(s string) error {
+		list := strings.Split(s, ",")
+		for i := range list {
+			list[i] = strings.TrimSpace(list[i])
+		}
+		*dst = list
+		return nil
+	}
+}
+
+// Amendable are the settings, by their environment variables, that agents
+// may amend in a referendum: the game's institutional rules.
+var Amendable = []string{
+	"VOTING_STRATEGY",
+	"VOTING_PREFERENCES",
+	"DYNAMIC_SANCTIONS",
+	"GRADUATED_SANCTIONS",
+	"MIN_TERM",
+	"MAX_TERM",
+	"DEFECTION",
+}
+
+// Amend returns f with the amendable setting named by its environment
+// variable set to value, failing if the setting is not amendable or the
+// amended configuration is invalid.
+func (f File) Amend(setting string, value string) (File, error) {
+	if !slices.Contains(Amendable, setting) {
+		return File{}, fmt.Errorf("config: %s is not amendable", setting)
+	}
+	if strings.TrimSpace(value) == "" {
+		return File{}, fmt.Errorf("config: %s: no value", setting)
+	}
+	if err := f.ApplyEnv(Env{setting: value}); err != nil {
+		return File{}, err
+	}
+	return f, f.Validate()
+}
+
+// Error lists every problem found with a configuration.
+type Error struct {
+	Problems []string
+}
+
+func (e *Error) Error() string {
+	return "config: " + strings.Join(e.Problems, "; ")
+}
+
+// Validate reports every setting that is out of range, or that conflicts with
+// another.
+func (f File) Validate() error {
+	var problems []string
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			problems = append(problems, fmt.Sprintf(format, args...))
+		}
+	}
+
+	g, s := f.Game, f.Sanctions
+	check(g.NumLevels > 0, "Game.NumLevels must be positive")
+	check(g.StartingHealthPoints > 0, "Game.StartingHealthPoints must be positive")
+	check(g.ThresholdPercentage >= 0 && g.ThresholdPercentage <= 1, "Game.ThresholdPercentage is %v, want between 0 and 1", g.ThresholdPercentage)
+	check(g.VotingStrategy < decision.NumVotingStrategies, "Game.VotingStrategy is %d, want below %d", g.VotingStrategy, decision.NumVotingStrategies)
+	check(g.VotingPreferences > 0, "Game.VotingPreferences must be positive")
+	check(g.MaxScore > 0, "Game.MaxScore must be positive")
+	check(g.PotionScarcity >= 0, "Game.PotionScarcity is %v, want at least 0", g.PotionScarcity)
+	check(g.EquipmentScarcity >= 0, "Game.EquipmentScarcity is %v, want at least 0", g.EquipmentScarcity)
+	check(g.MonsterAbilityChance >= 0 && g.MonsterAbilityChance <= 1, "Game.MonsterAbilityChance is %v, want between 0 and 1", g.MonsterAbilityChance)
+	if g.MaxFightRounds > 0 {
+		switch g.Stalemate {
+		case Enrage:
+			check(g.EnrageRate > 0, "Game.EnrageRate is %v, want above 0 for the monster to enrage", g.EnrageRate)
+		case FailLevel:
+			check(g.StalematePenalty >= 0 && g.StalematePenalty <= 1, "Game.StalematePenalty is %v, want between 0 and 1", g.StalematePenalty)
+		case Retreat:
+		default:
+			check(false, "Game.Stalemate is %q, want %q, %q or %q", g.Stalemate, Enrage, FailLevel, Retreat)
+		}
+	}
+	switch g.DamagePolicy {
+	case "", EvenSplit, Exposure, InverseDefence, ShieldWall:
+	case FocusFire:
+		check(g.FocusFire > 0, "Game.FocusFire must be positive for the %q damage policy", FocusFire)
+	default:
+		names := make([]string, len(DamagePolicies))
+		for i, p := range DamagePolicies {
+			names[i] = strconv.Quote(string(p))
+		}
+		check(false, "Game.DamagePolicy is %q, want one of %s", g.DamagePolicy, strings.Join(names, ", "))
+	}
+	switch g.DeathDrop {
+	case "", NoDrop, NextLoot, CorpseLoot:
+	default:
+		check(false, "Game.DeathDrop is %q, want %q, %q or %q", g.DeathDrop, NoDrop, NextLoot, CorpseLoot)
+	}
+	check(g.DeathDropStamina >= 0 && g.DeathDropStamina <= 1, "Game.DeathDropStamina is %v, want between 0 and 1", g.DeathDropStamina)
+	check(g.ConfidenceQuorum >= 0 && g.ConfidenceQuorum <= 1, "Game.ConfidenceQuorum is %v, want between 0 and 1", g.ConfidenceQuorum)
+	switch g.ConfidenceAbstentions {
+	case "", IgnoreAbstentions, AbstainFor, AbstainAgainst:
+	default:
+		check(false, "Game.ConfidenceAbstentions is %q, want %q, %q or %q", g.ConfidenceAbstentions, IgnoreAbstentions, AbstainFor, AbstainAgainst)
+	}
+	switch g.VoteWeighting {
+	case "", EqualWeights:
+	case ContributionWeights, HPWeights, ReputationWeights, QuadraticWeights:
+		check(g.VotingStrategy == decision.SingleChoicePlurality || g.VotingStrategy == decision.BordaCount,
+			"Game.VoteWeighting %q only weighs plurality and Borda count elections, not VotingStrategy %d", g.VoteWeighting, g.VotingStrategy)
+		check(g.VoteWeighting != QuadraticWeights || g.VoteBudget > 0, "Game.VoteBudget must be positive for %q vote weighting", QuadraticWeights)
+	default:
+		check(false, "Game.VoteWeighting is %q, want %q, %q, %q, %q or %q", g.VoteWeighting, EqualWeights, ContributionWeights, HPWeights, ReputationWeights, QuadraticWeights)
+	}
+	switch g.Cabinet {
+	case "", NoCabinet, AppointedCabinet, ElectedCabinet:
+	default:
+		check(false, "Game.Cabinet is %q, want %q, %q or %q", g.Cabinet, NoCabinet, AppointedCabinet, ElectedCabinet)
+	}
+	check(g.MaxTermLength == 0 || g.MinTermLength <= g.MaxTermLength, "Game.MinTermLength is %d, want at most Game.MaxTermLength %d", g.MinTermLength, g.MaxTermLength)
+	check(g.Supermajority == 0 || (g.Supermajority > 0.5 && g.Supermajority <= 1), "Game.Supermajority is %v, want above 0.5 and at most 1", g.Supermajority)
+	if len(g.AgentQuantities) > 0 {
+		total := uint(0)
+		for _, quantity := range g.AgentQuantities {
+			total += quantity
+		}
+		check(total > 0, "Game.AgentQuantities has no agents")
+	}
+	for _, p := range []struct {
+		name        string
+		personality uint
+	}{
+		{"SelfishPersonality", g.Team3.SelfishPersonality},
+		{"CollectivePersonality", g.Team3.CollectivePersonality},
+		{"SelflessPersonality", g.Team3.SelflessPersonality},
+	} {
+		check(p.personality <= 100, "Game.Team3.%s is %d, want at most 100", p.name, p.personality)
+	}
+
+	check(!(s.DynamicSanctions && s.GraduatedSanctions), "Sanctions.DynamicSanctions and Sanctions.GraduatedSanctions cannot both be set")
+	check(s.FixedSanctionDuration >= 0, "Sanctions.FixedSanctionDuration must not be negative")
+	check(!s.GraduatedSanctions || s.MaxGraduatedSanctionDuration > 0, "Sanctions.MaxGraduatedSanctionDuration must be positive for graduated sanctions")
+
+	if len(problems) == 0 {
+		return nil
+	}
+	return &Error{Problems: problems}
+}
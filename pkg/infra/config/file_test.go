@@ -0,0 +1,321 @@
+package config_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"infra/config"
+	"infra/game/decision"
+)
+
+func write(t *testing.T, name string, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0666); err != nil {
+		t.Fatalf("failed to write %s: %v", name, err)
+	}
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	want := config.Default()
+	want.Game.NumLevels = 40
+	want.Game.VotingStrategy = 1
+	want.Game.AgentQuantities = map[string]uint{"SELFISH": 10, "SELFLESS": 20}
+	want.Game.Team3.SelfishPersonality = 5
+	want.Sanctions.GraduatedSanctions = true
+
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		wantErr string
+	}{
+		{
+			name: "yaml",
+			file: "config.yaml",
+			content: `
+Game:
+  NumLevels: 40
+  votingStrategy: 1
+  AgentQuantities: {SELFISH: 10, SELFLESS: 20}
+  Team3:
+    SelfishPersonality: 5
+Sanctions:
+  GraduatedSanctions: true
+`,
+		},
+		{
+			name: "json",
+			file: "config.json",
+			content: `{
+	"Game": {
+		"NumLevels": 40,
+		"VotingStrategy": 1,
+		"AgentQuantities": {"SELFISH": 10, "SELFLESS": 20},
+		"Team3": {"SelfishPersonality": 5}
+	},
+	"Sanctions": {"GraduatedSanctions": true}
+}`,
+		},
+		{
+			name:    "unknown field",
+			file:    "config.yaml",
+			content: "Game:\n  NumLevel: 40\n",
+			wantErr: `unknown field "NumLevel"`,
+		},
+		{
+			name:    "wrong type",
+			file:    "config.json",
+			content: `{"Game": {"Defection": "yes"}}`,
+			wantErr: "Game.Defection",
+		},
+		{
+			name:    "invalid",
+			file:    "config.yaml",
+			content: "Game:\n  VotingStrategy: 9\n  AgentQuantities: {SELFISH: 0}\n",
+			wantErr: "Game.VotingStrategy is 9, want below 7; Game.AgentQuantities has no agents",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := config.Load(write(t, tt.file, tt.content), nil)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Load() error = %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("Load() = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestLoadLayers(t *testing.T) {
+	dotenv := config.Env{"LEVELS": "10", "DEFECTION": "false", "AGENT_SELFLESS_QUANTITY": "7"}
+	path := write(t, "config.yaml", "Game:\n  NumLevels: 40\n  PotionScarcity: 0.5\n")
+
+	got, err := config.Load(path, dotenv)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if got.Game.NumLevels != 40 || got.Game.Defection || got.Game.AgentQuantities["SELFLESS"] != 7 {
+		t.Errorf("Load() = %+v, want the file to override .env", got.Game)
+	}
+
+	t.Setenv("LEVELS", "20")
+	t.Setenv("AGENT_SELFISH_QUANTITY", "3")
+	t.Setenv("SELFISH_PER", " 15 ")
+	got, err = config.Load(path, dotenv)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if got.Game.NumLevels != 20 || got.Game.PotionScarcity != 0.5 || got.Game.AgentQuantities["SELFISH"] != 3 || got.Game.Team3.SelfishPersonality != 15 {
+		t.Errorf("Load() = %+v, want the environment to override the file", got.Game)
+	}
+
+	t.Setenv("LEVELS", "many")
+	if _, err := config.Load("", nil); err == nil || !strings.Contains(err.Error(), "LEVELS") {
+		t.Errorf("Load() error = %v, want one naming LEVELS", err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		modify func(*config.File)
+		want   []string
+	}{
+		{
+			name:   "default",
+			modify: func(*config.File) {},
+		},
+		{
+			name: "dynamic and graduated sanctions",
+			modify: func(f *config.File) {
+				f.Sanctions.DynamicSanctions = true
+				f.Sanctions.GraduatedSanctions = true
+			},
+			want: []string{"Sanctions.DynamicSanctions and Sanctions.GraduatedSanctions cannot both be set"},
+		},
+		{
+			name: "unknown stalemate",
+			modify: func(f *config.File) {
+				f.Game.Stalemate = "surrender"
+			},
+			want: []string{`Game.Stalemate is "surrender", want "enrage", "fail" or "retreat"`},
+		},
+		{
+			name: "damage policy",
+			modify: func(f *config.File) {
+				f.Game.DamagePolicy = "random"
+			},
+			want: []string{`Game.DamagePolicy is "random", want one of "even", "exposure", "inverse-defence", "focus-fire", "shield-wall"`},
+		},
+		{
+			name: "focus fire on no one",
+			modify: func(f *config.File) {
+				f.Game.DamagePolicy = config.FocusFire
+				f.Game.FocusFire = 0
+			},
+			want: []string{`Game.FocusFire must be positive for the "focus-fire" damage policy`},
+		},
+		{
+			name: "confidence rules",
+			modify: func(f *config.File) {
+				f.Game.ConfidenceQuorum = 1.5
+				f.Game.ConfidenceAbstentions = "abstain"
+			},
+			want: []string{
+				"Game.ConfidenceQuorum is 1.5, want between 0 and 1",
+				`Game.ConfidenceAbstentions is "abstain", want "ignore", "for" or "against"`,
+			},
+		},
+		{
+			name: "unknown vote weighting",
+			modify: func(f *config.File) {
+				f.Game.VoteWeighting = "wealth"
+			},
+			want: []string{`Game.VoteWeighting is "wealth", want "equal", "contribution", "hp", "reputation" or "quadratic"`},
+		},
+		{
+			name: "quadratic voting",
+			modify: func(f *config.File) {
+				f.Game.VoteWeighting = config.QuadraticWeights
+				f.Game.VotingStrategy = decision.InstantRunoff
+				f.Game.VoteBudget = 0
+			},
+			want: []string{
+				`Game.VoteWeighting "quadratic" only weighs plurality and Borda count elections, not VotingStrategy 2`,
+				`Game.VoteBudget must be positive for "quadratic" vote weighting`,
+			},
+		},
+		{
+			name: "unknown cabinet",
+			modify: func(f *config.File) {
+				f.Game.Cabinet = "council"
+			},
+			want: []string{`Game.Cabinet is "council", want "none", "appointed" or "elected"`},
+		},
+		{
+			name: "term bounds and supermajority",
+			modify: func(f *config.File) {
+				f.Game.MinTermLength = 5
+				f.Game.MaxTermLength = 3
+				f.Game.Supermajority = 0.5
+			},
+			want: []string{
+				"Game.MinTermLength is 5, want at most Game.MaxTermLength 3",
+				"Game.Supermajority is 0.5, want above 0.5 and at most 1",
+			},
+		},
+		{
+			name: "out of range",
+			modify: func(f *config.File) {
+				f.Game.NumLevels = 0
+				f.Game.ThresholdPercentage = 2
+				f.Game.Team3.SelflessPersonality = 101
+			},
+			want: []string{
+				"Game.NumLevels must be positive",
+				"Game.ThresholdPercentage is 2, want between 0 and 1",
+				"Game.Team3.SelflessPersonality is 101, want at most 100",
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			f := config.Default()
+			tt.modify(&f)
+			err := f.Validate()
+
+			var got []string
+			var configErr *config.Error
+			if errors.As(err, &configErr) {
+				got = configErr.Problems
+			} else if err != nil {
+				t.Fatalf("Validate() error = %v, want a *config.Error", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Validate() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAmend(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		setting string
+		value   string
+		check   func(config.File) bool
+		wantErr bool
+	}{
+		{
+			name:    "voting strategy",
+			setting: "VOTING_STRATEGY",
+			value:   "2",
+			check:   func(f config.File) bool { return f.Game.VotingStrategy == 2 },
+		},
+		{
+			name:    "sanctions",
+			setting: "DYNAMIC_SANCTIONS",
+			value:   "true",
+			check:   func(f config.File) bool { return f.Sanctions.DynamicSanctions },
+		},
+		{
+			name:    "not amendable",
+			setting: "LEVELS",
+			value:   "1",
+			wantErr: true,
+		},
+		{
+			name:    "unparsable",
+			setting: "DEFECTION",
+			value:   "sometimes",
+			wantErr: true,
+		},
+		{
+			name:    "invalid",
+			setting: "VOTING_PREFERENCES",
+			value:   "0",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			f := config.Default()
+			got, err := f.Amend(tt.setting, tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Amend() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && !tt.check(got) {
+				t.Errorf("Amend(%s, %s) = %+v, not amended", tt.setting, tt.value, got)
+			}
+			if !reflect.DeepEqual(f, config.Default()) {
+				t.Error("Amend() changed the configuration it amended")
+			}
+		})
+	}
+}
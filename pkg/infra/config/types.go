@@ -11,4 +11,190 @@ type GameConfig struct {
 	VotingStrategy         uint
 	VotingPreferences      uint
 	Defection              bool
+	// MaxScore is the highest score a ballot can give a candidate in score
+	// voting.
+	MaxScore uint
+	// MessageRounds is the most communication rounds a stage runs before it
+	// is closed. Zero uses the default.
+	MessageRounds uint
+	// AgentDeadlineMs is how long, in milliseconds, an agent has to handle a
+	// communication round before it is dropped from the stage. Zero waits
+	// for every agent.
+	AgentDeadlineMs uint
+	// CampaignRounds is the most communication rounds of the campaign before
+	// each election. Zero holds no campaign.
+	CampaignRounds uint
+	// Stages are the names of the stages played in every level, in order.
+	// Empty plays the default pipeline.
+	Stages []string
+	// MaxFightRounds is the most fight rounds played in a level before the
+	// Stalemate consequence applies. Zero leaves the rounds unbounded.
+	MaxFightRounds uint
+	// Stalemate is what happens when a level's fight rounds run out.
+	Stalemate Stalemate
+	// EnrageRate is how much the monster's attack grows each round past
+	// MaxFightRounds, as a fraction of its attack, when it enrages.
+	EnrageRate float32
+	// StalematePenalty is the fraction of their HP that agents lose when
+	// they fail a level.
+	StalematePenalty float32
+	// PotionScarcity and EquipmentScarcity scale the number of potions and
+	// items of equipment dropped after each level by the number of agents.
+	PotionScarcity    float32
+	EquipmentScarcity float32
+	// MonsterAbilityChance is the chance of the monster having each special
+	// ability on a level.
+	MonsterAbilityChance float32
+	// DamagePolicy is how the monster's damage is shared out among the
+	// agents. Empty is EvenSplit.
+	DamagePolicy DamagePolicy
+	// FocusFire is the number of agents the FocusFire policy picks.
+	FocusFire uint
+	// DeathDrop is what becomes of the inventory of agents killed in a fight.
+	// Empty is NoDrop.
+	DeathDrop DeathDrop
+	// DeathDropStamina is the fraction of a dead agent's stamina dropped as
+	// a stamina potion along with its inventory.
+	DeathDropStamina float32
+	// ConfidenceQuorum is the fraction of the agents that must vote for or
+	// against the leader, counting abstentions that are not ignored, for a
+	// no-confidence motion to stand.
+	ConfidenceQuorum float32
+	// ConfidenceAbstentions is how abstentions count in a no-confidence
+	// motion. Empty is IgnoreAbstentions.
+	ConfidenceAbstentions Abstentions
+	// PublicConfidenceVotes shows every agent, the leader included, how each
+	// voted in the last no-confidence motion. Votes are secret otherwise.
+	PublicConfidenceVotes bool
+	// ConfidenceCooldown is the number of levels after a failed
+	// no-confidence motion before the next is held.
+	ConfidenceCooldown uint
+	// VoteWeighting is how much each agent's vote counts in plurality and
+	// Borda count elections and in no-confidence motions. Empty is
+	// EqualWeights.
+	VoteWeighting VoteWeighting
+	// VoteBudget is the number of credits each agent has to spend on votes
+	// every term under QuadraticWeights.
+	VoteBudget uint
+	// Cabinet is how the fight marshal, quartermaster and treasurer are
+	// chosen. Empty is NoCabinet.
+	Cabinet Cabinet
+	// MinTermLength and MaxTermLength bound the number of levels a leader
+	// serves, whatever its manifesto says. Zero leaves a bound unset.
+	MinTermLength uint
+	MaxTermLength uint
+	// Supermajority is the fraction of the votes cast for and against an
+	// amendment that must be for it to pass. Zero is two thirds.
+	Supermajority float32
+	// Team3 are the settings of team 3's agents.
+	Team3 Team3Settings
+	// AgentQuantities is the number of agents created for each name in the
+	// strategy map. Names missing here get the mode's default quantity.
+	AgentQuantities map[string]uint
 }
+
+// Team3Settings are the tunable parameters of team 3's agents.
+type Team3Settings struct {
+	SelfishPersonality    uint
+	CollectivePersonality uint
+	SelflessPersonality   uint
+	UpdatePersonality     bool
+}
+
+// Stalemate is the consequence of a level's fight rounds running out with the
+// monster still alive.
+type Stalemate string
+
+const (
+	// Enrage keeps the fight going, with the monster's attack growing every
+	// round until it or the agents are dead.
+	Enrage Stalemate = "enrage"
+	// FailLevel ends the fight, and every agent loses StalematePenalty of its
+	// HP. The level's loot is lost.
+	FailLevel Stalemate = "fail"
+	// Retreat ends the fight, and the agents give up the HP pool to escape.
+	// The level's loot is lost.
+	Retreat Stalemate = "retreat"
+)
+
+// DamagePolicy is how the monster's damage is shared out among the agents it
+// falls on.
+type DamagePolicy string
+
+const (
+	// EvenSplit shares the damage out evenly.
+	EvenSplit DamagePolicy = "even"
+	// Exposure shares the damage out by exposure, attackers taking twice
+	// what shielding or cowering agents do.
+	Exposure DamagePolicy = "exposure"
+	// InverseDefence shares the damage out in inverse proportion to each
+	// agent's defence.
+	InverseDefence DamagePolicy = "inverse-defence"
+	// FocusFire splits the damage evenly among FocusFire agents picked at
+	// random.
+	FocusFire DamagePolicy = "focus-fire"
+	// ShieldWall has the shielding agents absorb the damage, until they die,
+	// before it reaches the others.
+	ShieldWall DamagePolicy = "shield-wall"
+)
+
+// DamagePolicies lists every damage policy.
+var DamagePolicies = []DamagePolicy{EvenSplit, Exposure, InverseDefence, FocusFire, ShieldWall}
+
+// DeathDrop is what becomes of the inventory of an agent killed in a fight.
+type DeathDrop string
+
+const (
+	// NoDrop destroys the dead agent's inventory.
+	NoDrop DeathDrop = "none"
+	// NextLoot adds the dead agent's inventory to the next loot pool.
+	NextLoot DeathDrop = "loot"
+	// CorpseLoot shares the dead agent's inventory out in the corpse-loot
+	// stage, straight after the fight.
+	CorpseLoot DeathDrop = "corpse"
+)
+
+// Abstentions is how abstentions count in a no-confidence motion.
+type Abstentions string
+
+const (
+	// IgnoreAbstentions leaves abstentions out of the count.
+	IgnoreAbstentions Abstentions = "ignore"
+	// AbstainFor counts abstentions as confidence in the leader.
+	AbstainFor Abstentions = "for"
+	// AbstainAgainst counts abstentions as votes against the leader.
+	AbstainAgainst Abstentions = "against"
+)
+
+// VoteWeighting is how much each agent's vote counts.
+type VoteWeighting string
+
+const (
+	// EqualWeights counts every vote once.
+	EqualWeights VoteWeighting = "equal"
+	// ContributionWeights weighs votes by the HP each agent has donated to
+	// the HP pool so far.
+	ContributionWeights VoteWeighting = "contribution"
+	// HPWeights weighs votes by each agent's HP.
+	HPWeights VoteWeighting = "hp"
+	// ReputationWeights weighs votes by each agent's reputation, the mean of
+	// what the last trust messages said of it.
+	ReputationWeights VoteWeighting = "reputation"
+	// QuadraticWeights has agents spend credits from their VoteBudget on
+	// each vote, a vote weighing the square root of the credits spent.
+	QuadraticWeights VoteWeighting = "quadratic"
+)
+
+// Cabinet is how the holders of the leader's delegated roles are chosen.
+type Cabinet string
+
+const (
+	// NoCabinet leaves the leader holding every role.
+	NoCabinet Cabinet = "none"
+	// AppointedCabinet has the leader appoint a holder to each role after
+	// its election.
+	AppointedCabinet Cabinet = "appointed"
+	// ElectedCabinet elects a holder to each role after every leader
+	// election, by the game's voting strategy.
+	ElectedCabinet Cabinet = "elected"
+)
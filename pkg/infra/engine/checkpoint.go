@@ -0,0 +1,226 @@
+package engine
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"infra/game/agent"
+	"infra/game/commons"
+	"infra/game/state"
+	"infra/logging"
+
+	"golang.org/x/exp/maps"
+)
+
+// Checkpoint is a game paused between levels. A game resumed from it (see
+// Resume) picks up at the start of State.CurrentLevel.
+type Checkpoint struct {
+	// Seed is the seed of the game the checkpoint was taken from.
+	Seed     int64
+	TermLeft uint
+	// ConfidenceCooldown is the number of levels left before the next
+	// no-confidence motion.
+	ConfidenceCooldown uint
+	InitialAgents      int
+	InitialNumAgents   uint
+	State              *state.State
+	// Agents are the living agents, in ID order.
+	Agents []CheckpointAgent
+	// Levels is the game log of the levels played so far.
+	Levels []logging.LevelStages
+	// CSV is the per-level summary of the levels played so far.
+	CSV [][]string
+	// Amendments are the amendments proposed so far. Those passed are
+	// applied again to the settings the game is resumed with.
+	Amendments []logging.Amendment `json:",omitempty"`
+}
+
+// CheckpointAgent is an agent and, if its strategy is an agent.Snapshotter,
+// the strategy's internal state.
+type CheckpointAgent struct {
+	ID       commons.ID
+	Name     string
+	Snapshot json.RawMessage `json:",omitempty"`
+}
+
+// Checkpoint captures the game as it stands between levels.
+func (g *Game) Checkpoint() (*Checkpoint, error) {
+	if g.done {
+		return nil, ErrGameOver
+	}
+
+	g.csv.Flush()
+	rows, err := csv.NewReader(bytes.NewReader(g.csvBuf.Bytes())).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("engine: failed reading csv log: %w", err)
+	}
+
+	cp := &Checkpoint{
+		Seed:               g.source.Seed(),
+		TermLeft:           g.termLeft,
+		ConfidenceCooldown: g.confidenceCooldown,
+		InitialAgents:      g.initialAgents,
+		InitialNumAgents:   g.gameConfig.InitialNumAgents,
+		State:              g.state,
+		Levels:             g.log.Levels,
+		Amendments:         g.log.Amendments,
+		// the first row is the title row, which a resumed game writes itself
+		CSV: rows[1:],
+	}
+
+	ids := maps.Keys(g.agentMap)
+	sort.Strings(ids)
+	for _, id := range ids {
+		a := g.agentMap[id]
+		entry := CheckpointAgent{ID: id, Name: a.BaseAgent.Name()}
+		if s, ok := a.Strategy.(agent.Snapshotter); ok {
+			if entry.Snapshot, err = s.Snapshot(*a.BaseAgent); err != nil {
+				return nil, fmt.Errorf("engine: failed snapshotting agent %s: %w", id, err)
+			}
+		}
+		cp.Agents = append(cp.Agents, entry)
+	}
+	// copy, so that the checkpoint does not change as the game goes on
+	return cp.clone()
+}
+
+// Resume sets up a game from a checkpoint, creating agents from registry by
+// name. The rest of the game is played with cfg, whose seed, sanctions and
+// game settings may differ from those of the checkpointed game, as amended by
+// the amendments passed before the checkpoint.
+func Resume(cp *Checkpoint, cfg Config, registry Registry) (*Game, error) {
+	g, err := newGame(cfg, registry)
+	if err != nil {
+		return nil, err
+	}
+	if cp.State == nil || len(cp.Agents) == 0 {
+		return nil, errors.New("engine: checkpoint has no game to resume")
+	}
+	// copy, so that cp can be resumed again
+	if cp, err = cp.clone(); err != nil {
+		return nil, err
+	}
+
+	g.gameConfig.InitialNumAgents = cp.InitialNumAgents
+	rules := g.rules()
+	for _, amendment := range cp.Amendments {
+		if !amendment.Passed {
+			continue
+		}
+		if rules, err = rules.Amend(amendment.Setting, amendment.Value); err != nil {
+			return nil, fmt.Errorf("engine: amendment passed on level %d: %w", amendment.Level, err)
+		}
+	}
+	g.initialAgents = cp.InitialAgents
+	g.termLeft = cp.TermLeft
+	g.confidenceCooldown = cp.ConfidenceCooldown
+	g.state = cp.State
+	g.amend(rules)
+
+	g.agentMap = make(map[commons.ID]agent.Agent, len(cp.Agents))
+	for _, entry := range cp.Agents {
+		strategy, ok := registry[entry.Name]
+		if !ok {
+			return nil, fmt.Errorf("engine: no strategy registered for agent %s of %s", entry.ID, entry.Name)
+		}
+		g.agentMap[entry.ID] = agent.Agent{
+			BaseAgent: agent.NewBaseAgent(nil, entry.ID, entry.Name, g.view, g.source.Stream("agent", entry.ID)),
+			Strategy:  strategy(),
+		}
+	}
+
+	g.wrapStrategies()
+	g.start()
+	g.log.Levels = cp.Levels
+	g.log.Amendments = cp.Amendments
+	for _, row := range cp.CSV {
+		_ = g.csv.Write(row)
+	}
+
+	// strategies are restored last, so that they can see the game they are
+	// resumed into
+	for _, entry := range cp.Agents {
+		a := g.agentMap[entry.ID]
+		s, ok := a.Strategy.(agent.Snapshotter)
+		if !ok || entry.Snapshot == nil {
+			continue
+		}
+		if err := s.Restore(*a.BaseAgent, entry.Snapshot); err != nil {
+			return nil, fmt.Errorf("engine: failed restoring agent %s: %w", entry.ID, err)
+		}
+	}
+
+	logging.Log(logging.Info, logging.LogField{"seed": cfg.Seed, "level": g.state.CurrentLevel}, "Resuming game")
+	return g, nil
+}
+
+func (cp *Checkpoint) clone() (*Checkpoint, error) {
+	buf, err := json.Marshal(cp)
+	if err != nil {
+		return nil, fmt.Errorf("engine: failed to marshal checkpoint: %w", err)
+	}
+	var clone Checkpoint
+	if err := json.Unmarshal(buf, &clone); err != nil {
+		return nil, fmt.Errorf("engine: failed to copy checkpoint: %w", err)
+	}
+	return &clone, nil
+}
+
+// ReadCheckpoint reads a checkpoint written by WriteFile.
+func ReadCheckpoint(path string) (*Checkpoint, error) {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("engine: failed reading checkpoint: %w", err)
+	}
+	var cp Checkpoint
+	if err := json.Unmarshal(buf, &cp); err != nil {
+		return nil, fmt.Errorf("engine: failed parsing checkpoint %s: %w", path, err)
+	}
+	return &cp, nil
+}
+
+// WriteFile writes the checkpoint as JSON to path, creating any missing
+// parent directories.
+func (cp *Checkpoint) WriteFile(path string) error {
+	buf, err := json.Marshal(cp)
+	if err != nil {
+		return fmt.Errorf("engine: failed to marshal checkpoint: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0777); err != nil {
+		return fmt.Errorf("engine: failed to create checkpoint directory: %w", err)
+	}
+	if err := os.WriteFile(path, buf, 0666); err != nil {
+		return fmt.Errorf("engine: failed to write checkpoint: %w", err)
+	}
+	return nil
+}
+
+// CheckpointPath is where a game configured with dir checkpoints level.
+func CheckpointPath(dir string, level uint) string {
+	return filepath.Join(dir, fmt.Sprintf("level-%d.json", level))
+}
+
+// writeCheckpoint checkpoints the game if the current level is one of
+// Config.CheckpointLevels.
+func (g *Game) writeCheckpoint() error {
+	if g.cfg.CheckpointDir == "" {
+		return nil
+	}
+	for _, level := range g.cfg.CheckpointLevels {
+		if level != g.state.CurrentLevel {
+			continue
+		}
+		cp, err := g.Checkpoint()
+		if err != nil {
+			return err
+		}
+		return cp.WriteFile(CheckpointPath(g.cfg.CheckpointDir, level))
+	}
+	return nil
+}
@@ -0,0 +1,322 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	cmdline "infra/cmdLine"
+	"infra/config"
+	"infra/game/agent"
+	"infra/game/commons"
+	gamemath "infra/game/math"
+	"infra/game/rng"
+	"infra/game/stages"
+	"infra/game/state"
+	"infra/logging"
+	sanctions "infra/sanctionUtils"
+)
+
+// ErrGameOver is returned by Step once the game has been won or lost.
+var ErrGameOver = errors.New("engine: game is over")
+
+// Registry maps an agent name to the constructor for its strategy. The number
+// of agents created for each name is set by config.GameConfig.AgentQuantities.
+type Registry map[commons.ID]func() agent.Strategy
+
+// Config is everything needed to set up a single game.
+type Config struct {
+	Game      config.GameConfig
+	Sanctions cmdline.CmdLine
+	Seed      int64
+	// RunID names the game in its log. Empty means the seed, so that games
+	// with the same seed and settings log the same.
+	RunID string
+	// OutputPath is where the JSON game log is written once the game ends.
+	// Nothing is written when it is empty.
+	OutputPath string
+	// CSVPath is where the per-level summary is written once the game ends.
+	// Nothing is written when it is empty.
+	CSVPath string
+	// Pipeline is the stages played in every level. When nil, it is built
+	// from Game.Stages (see ParsePipeline).
+	Pipeline Pipeline
+	// CheckpointDir is where checkpoints are written (see CheckpointPath).
+	// Nothing is written when it is empty.
+	CheckpointDir string
+	// CheckpointLevels are the levels checkpointed at the start of.
+	CheckpointLevels []uint
+	// WrapStrategy, if set, replaces every agent's strategy with the one it
+	// returns, eg. to record the agent's decisions (see package replay).
+	WrapStrategy func(baseAgent *agent.BaseAgent, strategy agent.Strategy) agent.Strategy
+	// OnStage, if set, is called before each stage is played.
+	OnStage func(level uint, stage string)
+	// Strict checks the game state's invariants after every stage, failing
+	// the game with an *InvariantError on the first stage to break them.
+	Strict bool
+	// Mode picks which team's implementation of each stage is played (see
+	// package stages). Empty plays the default.
+	Mode string
+}
+
+// Result summarises a game. Outcome is only meaningful once the game is done.
+type Result struct {
+	Outcome   logging.Outcome
+	Level     uint
+	Survivors uint
+	Log       *logging.GameLog
+}
+
+// Game holds all the state of a single game, so that any number of games can
+// run side by side in one process.
+type Game struct {
+	cfg           Config
+	gameConfig    config.GameConfig
+	source        *rng.Source
+	view          *state.View
+	state         *state.State
+	agentMap      map[commons.ID]agent.Agent
+	rounds        agent.Rounds
+	pipeline      Pipeline
+	initialAgents int
+	termLeft      uint
+	// confidenceCooldown is the number of levels left before the next
+	// no-confidence motion.
+	confidenceCooldown uint
+	// amended, unless nil, is the game's settings as amended in the level's
+	// referendums, which take effect from the next level.
+	amended *config.File
+	log     *logging.GameLog
+	csvBuf  *bytes.Buffer
+	csv     *csv.Writer
+	done    bool
+}
+
+// New sets up a game from cfg, creating agents from registry.
+func New(cfg Config, registry Registry) (*Game, error) {
+	g, err := newGame(cfg, registry)
+	if err != nil {
+		return nil, err
+	}
+
+	defStrategyMap := stages.ChooseDefaultStrategyMap(g.cfg.Mode, registry)
+	numAgents, agents, agentStateMap, inventoryMap := stages.InitAgents(g.cfg.Mode, defStrategyMap, g.gameConfig, g.view, g.source)
+	if numAgents == 0 {
+		return nil, errors.New("engine: no agents to play the game")
+	}
+	g.gameConfig.InitialNumAgents = numAgents
+
+	monsterRand := g.source.Stream("monster", 1)
+	g.state = &state.State{
+		CurrentLevel:   1,
+		MonsterHealth:  gamemath.CalculateMonsterHealth(monsterRand, g.gameConfig.InitialNumAgents, g.gameConfig.Stamina, g.gameConfig.NumLevels, 1),
+		MonsterAttack:  gamemath.CalculateMonsterDamage(monsterRand, g.gameConfig.InitialNumAgents, g.gameConfig.StartingHealthPoints, g.gameConfig.Stamina, g.gameConfig.ThresholdPercentage, g.gameConfig.NumLevels, 1),
+		AgentState:     agentStateMap,
+		InventoryMap:   inventoryMap,
+		Defection:      g.gameConfig.Defection,
+		SanctionConfig: cfg.Sanctions,
+		SanctionLedger: sanctions.NewLedger(),
+	}
+	g.state.MonsterAbilities = gamemath.MonsterAbilities(g.source.Stream("monster-abilities", 1), g.gameConfig, g.state.MonsterHealth)
+	g.agentMap = agents
+	g.initialAgents = len(agents)
+
+	g.wrapStrategies()
+	g.start()
+	logging.Log(logging.Info, logging.LogField{"seed": cfg.Seed}, "Seeding game")
+	return g, nil
+}
+
+// newGame checks cfg and sets up everything but the agents and the state.
+func newGame(cfg Config, registry Registry) (*Game, error) {
+	if len(registry) == 0 {
+		return nil, errors.New("engine: no strategies registered")
+	}
+	if err := (config.File{Game: cfg.Game, Sanctions: cfg.Sanctions}).Validate(); err != nil {
+		return nil, fmt.Errorf("engine: %w", err)
+	}
+
+	pipeline := cfg.Pipeline
+	if pipeline == nil {
+		var err error
+		if pipeline, err = ParsePipeline(cfg.Game.Stages); err != nil {
+			return nil, err
+		}
+	}
+
+	if cfg.RunID == "" {
+		cfg.RunID = fmt.Sprint(cfg.Seed)
+	}
+
+	return &Game{
+		cfg:        cfg,
+		gameConfig: cfg.Game,
+		source:     rng.NewSource(cfg.Seed),
+		view:       &state.View{},
+		pipeline:   pipeline,
+	}, nil
+}
+
+// wrapStrategies applies Config.WrapStrategy to every agent.
+func (g *Game) wrapStrategies() {
+	if g.cfg.WrapStrategy == nil {
+		return
+	}
+	for id, a := range g.agentMap {
+		a.Strategy = g.cfg.WrapStrategy(a.BaseAgent, a.Strategy)
+		g.agentMap[id] = a
+	}
+}
+
+// start sets up logging and communication once the agents and state exist.
+func (g *Game) start() {
+	g.log = logging.NewGameLog(logging.Config{
+		RunID:             g.cfg.RunID,
+		Seed:              g.cfg.Seed,
+		Mode:              logging.Mode(g.cfg.Mode),
+		Levels:            g.gameConfig.NumLevels,
+		StartingHP:        g.gameConfig.StartingHealthPoints,
+		StartingAttack:    g.gameConfig.StartingAttackStrength,
+		StartingShield:    g.gameConfig.StartingShieldStrength,
+		BaseStamina:       g.gameConfig.Stamina,
+		PassThreshold:     g.gameConfig.ThresholdPercentage,
+		VotingStrategy:    logging.VotingStrategy(g.gameConfig.VotingStrategy),
+		VotingPreferences: g.gameConfig.VotingPreferences,
+	})
+	g.initCsvLogging()
+
+	g.rounds = agent.Rounds{
+		Max:      g.gameConfig.MessageRounds,
+		Deadline: time.Duration(g.gameConfig.AgentDeadlineMs) * time.Millisecond,
+	}
+	g.addComms()
+	g.updateView()
+}
+
+// Run plays the game to the end, checking ctx between levels.
+func (g *Game) Run(ctx context.Context) (Result, error) {
+	for !g.done {
+		if err := ctx.Err(); err != nil {
+			return g.Result(), err
+		}
+		if err := g.Step(); err != nil {
+			return g.Result(), err
+		}
+	}
+	return g.Result(), nil
+}
+
+// Done reports whether the game has been won or lost.
+func (g *Game) Done() bool {
+	return g.done
+}
+
+// Result reports the game as it currently stands.
+func (g *Game) Result() Result {
+	return Result{
+		Outcome:   g.log.Outcome,
+		Level:     g.state.CurrentLevel,
+		Survivors: uint(len(g.agentMap)),
+		Log:       g.log,
+	}
+}
+
+// Step plays a single level, running each stage of the pipeline in turn.
+func (g *Game) Step() error {
+	if g.done {
+		return ErrGameOver
+	}
+
+	if err := g.writeCheckpoint(); err != nil {
+		return err
+	}
+	// agents draw from a fresh stream every level, so that a game resumed
+	// from a checkpoint plays out as the original did
+	for id, a := range g.agentMap {
+		a.SetRand(g.source.Stream("agent", id, g.state.CurrentLevel))
+	}
+
+	l := &Level{game: g, leaderBefore: g.state.CurrentLeader}
+	levelLog := logging.LevelStages{}
+	for _, stage := range g.pipeline {
+		if g.cfg.OnStage != nil {
+			g.cfg.OnStage(g.state.CurrentLevel, stage.Name())
+		}
+		var before snapshot
+		if g.cfg.Strict {
+			before = g.snapshot()
+		}
+		entry, err := stage.Run(l)
+		if err != nil {
+			return fmt.Errorf("engine: stage %s: %w", stage.Name(), err)
+		}
+		if g.cfg.Strict {
+			if err := g.checkInvariants(stage.Name(), before); err != nil {
+				return err
+			}
+		}
+		if entry != nil {
+			entry.Merge(&levelLog)
+		}
+		if l.outcome != nil {
+			g.logLevel(levelLog)
+			g.log.LogToFile(logging.Info, nil, "", levelLog)
+			return g.finish(*l.outcome)
+		}
+	}
+
+	logging.Log(logging.Info, nil, fmt.Sprintf("------------------------------ Level %d Ended ----------------------------", g.state.CurrentLevel))
+	g.log.LogToFile(logging.Info, nil, "", levelLog)
+	g.logLevel(levelLog)
+
+	if g.state.CurrentLevel == g.gameConfig.NumLevels {
+		logging.Log(logging.Info, nil, fmt.Sprintf("Congratulations, The Peasants have escaped the pit with %d remaining.", len(g.agentMap)))
+		return g.finish(logging.Win)
+	}
+
+	// End of level Updates
+	if g.amended != nil {
+		g.amend(*g.amended)
+		g.amended = nil
+	}
+	g.termLeft = commons.SaturatingSub(g.termLeft, 1)
+	g.state.CurrentLevel++
+	if g.gameConfig.DeathDrop != config.NextLoot {
+		g.state.Corpses = nil
+	}
+	g.state.MonsterHealth, g.state.MonsterAttack = gamemath.GetNextLevelMonsterValues(g.source.Stream("monster", g.state.CurrentLevel), g.gameConfig, g.state.CurrentLevel)
+	g.state.MonsterAbilities = gamemath.MonsterAbilities(g.source.Stream("monster-abilities", g.state.CurrentLevel), g.gameConfig, g.state.MonsterHealth)
+	g.updateView()
+	return nil
+}
+
+// finish marks the game as over and writes out its logs.
+func (g *Game) finish(outcome logging.Outcome) error {
+	g.done = true
+	g.log.Outcome = outcome
+
+	if g.cfg.OutputPath != "" {
+		if err := g.log.WriteFile(g.cfg.OutputPath); err != nil {
+			return err
+		}
+	}
+
+	if g.cfg.CSVPath != "" {
+		g.csv.Flush()
+		if err := g.csv.Error(); err != nil {
+			return fmt.Errorf("failed writing csv log: %w", err)
+		}
+		if err := os.MkdirAll(filepath.Dir(g.cfg.CSVPath), 0777); err != nil {
+			return fmt.Errorf("failed creating csv directory: %w", err)
+		}
+		if err := os.WriteFile(g.cfg.CSVPath, g.csvBuf.Bytes(), 0666); err != nil {
+			return fmt.Errorf("failed creating csv log: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,1046 @@
+package engine_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"sync"
+	"testing"
+
+	cmdline "infra/cmdLine"
+	"infra/config"
+	"infra/engine"
+	"infra/game/agent"
+	"infra/game/commons"
+	"infra/game/decision"
+	"infra/game/state"
+	"infra/logging"
+	"infra/teams/team3"
+)
+
+func testConfig(seed int64) engine.Config {
+	return engine.Config{
+		Game: config.GameConfig{
+			NumLevels:              3,
+			StartingHealthPoints:   1000,
+			StartingAttackStrength: 20,
+			StartingShieldStrength: 20,
+			ThresholdPercentage:    0.01,
+			Stamina:                2000,
+			VotingPreferences:      2,
+			MaxScore:               10,
+			Defection:              true,
+			PotionScarcity:         0.2,
+			EquipmentScarcity:      0.15,
+		},
+		Sanctions: cmdline.CmdLine{FixedSanctionDuration: 1, MaxGraduatedSanctionDuration: 5},
+		Seed:      seed,
+	}
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	conflicting := testConfig(1)
+	conflicting.Sanctions.DynamicSanctions = true
+	conflicting.Sanctions.GraduatedSanctions = true
+
+	tests := []struct {
+		name     string
+		cfg      engine.Config
+		registry engine.Registry
+		wantErr  bool
+	}{
+		{
+			name:     "valid",
+			cfg:      testConfig(1),
+			registry: engine.Registry{"COLLECTIVE": team3.NewAgentThreeNeutral},
+		},
+		{
+			name:     "empty registry",
+			cfg:      testConfig(1),
+			registry: engine.Registry{},
+			wantErr:  true,
+		},
+		{
+			name:     "dynamic and graduated sanctions",
+			cfg:      conflicting,
+			registry: engine.Registry{"COLLECTIVE": team3.NewAgentThreeNeutral},
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if _, err := engine.New(tt.cfg, tt.registry); (err != nil) != tt.wantErr {
+				t.Errorf("New() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGamesRunSideBySide(t *testing.T) {
+	t.Parallel()
+
+	var wg sync.WaitGroup
+	for seed := int64(1); seed <= 2; seed++ {
+		game, err := engine.New(testConfig(seed), engine.Registry{"COLLECTIVE": team3.NewAgentThreeNeutral})
+		if err != nil {
+			t.Fatalf("New() error = %v", err)
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			result, err := game.Run(context.Background())
+			if err != nil {
+				t.Errorf("Run() error = %v", err)
+				return
+			}
+			if !game.Done() {
+				t.Errorf("Done() = false after Run()")
+			}
+			if len(result.Log.Levels) != int(result.Level) {
+				t.Errorf("logged %d levels, game ended on level %d", len(result.Log.Levels), result.Level)
+			}
+			if err := game.Step(); !errors.Is(err, engine.ErrGameOver) {
+				t.Errorf("Step() after game over error = %v, want %v", err, engine.ErrGameOver)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestSameSeedSameGame(t *testing.T) {
+	t.Parallel()
+
+	registry := engine.Registry{
+		"COLLECTIVE": team3.NewAgentThreeNeutral,
+		"SELFLESS":   team3.NewAgentThreePassive,
+		"SELFISH":    team3.NewAgentThreeAggressive,
+	}
+	play := func() []byte {
+		cfg := testConfig(7)
+		cfg.Game.NumLevels = 60
+		cfg.Game.AgentQuantities = map[string]uint{"COLLECTIVE": 5, "SELFLESS": 5, "SELFISH": 5}
+		game, err := engine.New(cfg, registry)
+		if err != nil {
+			t.Fatalf("New() error = %v", err)
+		}
+		result, err := game.Run(context.Background())
+		if err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+		if result.Level < 5 {
+			t.Fatalf("game ended on level %d, too early to show the games agree", result.Level)
+		}
+		// the run ID is left at its default, so it must not differ between runs either
+		if result.Log.Config.RunID != "7" {
+			t.Errorf("logged run ID = %q, want the seed", result.Log.Config.RunID)
+		}
+		buf, err := json.Marshal(result.Log)
+		if err != nil {
+			t.Fatalf("failed to marshal game log: %v", err)
+		}
+		return buf
+	}
+
+	first, second := play(), play()
+	if !reflect.DeepEqual(first, second) {
+		t.Errorf("games with the same seed diverged:\n%s\n%s", first, second)
+	}
+}
+
+func TestResumeFromCheckpoint(t *testing.T) {
+	t.Parallel()
+
+	registry := engine.Registry{
+		"COLLECTIVE": team3.NewAgentThreeNeutral,
+		"SELFLESS":   team3.NewAgentThreePassive,
+		"SELFISH":    team3.NewAgentThreeAggressive,
+	}
+	cfg := testConfig(7)
+	cfg.Game.NumLevels = 60
+	cfg.Game.AgentQuantities = map[string]uint{"COLLECTIVE": 5, "SELFLESS": 5, "SELFISH": 5}
+	cfg.Sanctions.PersistentSanctions = true
+	cfg.CheckpointDir = t.TempDir()
+	cfg.CheckpointLevels = []uint{4}
+
+	play := func(game *engine.Game) (engine.Result, []byte) {
+		result, err := game.Run(context.Background())
+		if err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+		buf, err := json.Marshal(result.Log)
+		if err != nil {
+			t.Fatalf("failed to marshal game log: %v", err)
+		}
+		return result, buf
+	}
+
+	game, err := engine.New(cfg, registry)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	result, uninterrupted := play(game)
+	if result.Level < 6 {
+		t.Fatalf("game ended on level %d, too early to show the games agree", result.Level)
+	}
+
+	cp, err := engine.ReadCheckpoint(engine.CheckpointPath(cfg.CheckpointDir, 4))
+	if err != nil {
+		t.Fatalf("ReadCheckpoint() error = %v", err)
+	}
+	if cp.State.CurrentLevel != 4 || len(cp.Levels) != 3 {
+		t.Fatalf("checkpoint is at level %d with %d levels logged, want level 4 with 3", cp.State.CurrentLevel, len(cp.Levels))
+	}
+
+	cfg.CheckpointDir = ""
+	resumed, err := engine.Resume(cp, cfg, registry)
+	if err != nil {
+		t.Fatalf("Resume() error = %v", err)
+	}
+	if _, buf := play(resumed); !reflect.DeepEqual(uninterrupted, buf) {
+		t.Errorf("resumed game diverged from the uninterrupted one:\n%s\n%s", uninterrupted, buf)
+	}
+
+	// a checkpoint can branch into a game with a different seed
+	cfg.Seed = 8
+	branch, err := engine.Resume(cp, cfg, registry)
+	if err != nil {
+		t.Fatalf("Resume() with a new seed error = %v", err)
+	}
+	if result, _ := play(branch); result.Level < 4 {
+		t.Errorf("branched game ended on level %d, before the checkpoint", result.Level)
+	}
+
+	if _, err := engine.Resume(cp, cfg, engine.Registry{"COLLECTIVE": team3.NewAgentThreeNeutral}); err == nil {
+		t.Errorf("Resume() without every strategy registered succeeded")
+	}
+}
+
+func TestParsePipeline(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		stages    []string
+		wantNames []string
+		wantErr   bool
+	}{
+		{
+			name:      "default",
+			wantNames: engine.DefaultStages,
+		},
+		{
+			name:      "reordered and repeated",
+			stages:    []string{"fight", "trade", "loot", "trust", "trust"},
+			wantNames: []string{"fight", "trade", "loot", "trust", "trust"},
+		},
+		{
+			name:    "unknown stage",
+			stages:  []string{"fight", "parliament"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			pipeline, err := engine.ParsePipeline(tt.stages)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParsePipeline() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			names := make([]string, 0, len(pipeline))
+			for _, stage := range pipeline {
+				names = append(names, stage.Name())
+			}
+			if !tt.wantErr && !reflect.DeepEqual(names, tt.wantNames) {
+				t.Errorf("ParsePipeline() = %v, want %v", names, tt.wantNames)
+			}
+		})
+	}
+}
+
+func TestCustomPipeline(t *testing.T) {
+	t.Parallel()
+
+	cfg := testConfig(1)
+	cfg.Game.Stages = []string{"items", "fight", "trade", "loot", "trust", "trust", "hp-pool-donation", "update"}
+	game, err := engine.New(cfg, engine.Registry{"COLLECTIVE": team3.NewAgentThreeNeutral})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	result, err := game.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	for i, level := range result.Log.Levels {
+		if level.ElectionStage.Occurred || level.VONCStage.Occurred {
+			t.Errorf("level %d held a vote without election stages", i+1)
+		}
+		if !level.FightStage.Occurred {
+			t.Errorf("level %d has no fight", i+1)
+		}
+	}
+}
+
+// generous is a strategy that donates a tenth of its HP to the pool.
+type generous struct {
+	agent.Strategy
+}
+
+func (generous) DonateToHpPool(baseAgent agent.BaseAgent) uint {
+	return baseAgent.AgentState().Hp / 10
+}
+
+func TestStalemate(t *testing.T) {
+	t.Parallel()
+
+	// hpAtStalemate is each living agent's HP when the fight rounds ran out,
+	// from the HP it had before the fight and what the fight did to it.
+	hpAtStalemate := func(before map[commons.ID]uint, fight logging.FightStage, baseHealth uint) map[commons.ID]uint {
+		hp := make(map[commons.ID]uint, len(before))
+		for id, v := range before {
+			hp[id] = v
+		}
+		for _, round := range fight.Rounds {
+			for _, id := range round.CoweringAgents {
+				hp[id] += uint(math.Ceil(0.02 * float64(baseHealth)))
+			}
+			for id, taken := range round.DamageTaken {
+				hp[id] = commons.SaturatingSub(hp[id], taken)
+			}
+		}
+		for id, v := range hp {
+			if v == 0 {
+				delete(hp, id)
+			}
+		}
+		return hp
+	}
+
+	tests := []struct {
+		name      string
+		stalemate config.Stalemate
+		// maxRounds is the most fight rounds before the stalemate, zero for
+		// no limit
+		maxRounds uint
+		stages    []string
+		strategy  func() agent.Strategy
+		// check is given the state before the level, and after it unless
+		// the game is over
+		check func(t *testing.T, level logging.LevelStages, before, after *state.State, hpBefore map[commons.ID]uint)
+	}{
+		{
+			name:      "no limit",
+			stalemate: config.FailLevel,
+			stages:    []string{"election", "fight", "loot"},
+			check: func(t *testing.T, level logging.LevelStages, _, _ *state.State, _ map[commons.ID]uint) {
+				if level.FightStage.Stalemate != "" || level.FightStage.StalematePenalty != 0 {
+					t.Errorf("fight without a round limit logged stalemate %q costing %d", level.FightStage.Stalemate, level.FightStage.StalematePenalty)
+				}
+				if !level.LootStage.Occurred {
+					t.Error("loot was not shared out after the monster was killed")
+				}
+			},
+		},
+		{
+			name:      "enrage",
+			stalemate: config.Enrage,
+			maxRounds: 2,
+			stages:    []string{"election", "fight", "loot"},
+			check: func(t *testing.T, level logging.LevelStages, before, _ *state.State, _ map[commons.ID]uint) {
+				rounds := len(level.FightStage.Rounds)
+				if rounds <= 2 {
+					t.Fatalf("enraged monster fought %d rounds, want more than 2", rounds)
+				}
+				// the monster's attack grows by half, rounding up, every round after the second
+				want := before.MonsterAttack
+				for i := 2; i < rounds; i++ {
+					want = uint(math.Ceil(float64(want) * 1.5))
+				}
+				if got := level.LevelStats.MonsterAttack; got != want {
+					t.Errorf("monster attack after %d rounds = %d, want %d", rounds, got, want)
+				}
+				if level.FightStage.StalematePenalty != 0 {
+					t.Errorf("enraged monster cost a stalemate penalty of %d", level.FightStage.StalematePenalty)
+				}
+			},
+		},
+		{
+			name:      "fail",
+			stalemate: config.FailLevel,
+			maxRounds: 2,
+			stages:    []string{"election", "fight", "loot"},
+			check: func(t *testing.T, level logging.LevelStages, _, after *state.State, hpBefore map[commons.ID]uint) {
+				hp := hpAtStalemate(hpBefore, level.FightStage, 1000)
+				if after == nil || len(hp) == 0 {
+					t.Fatal("no agent survived the fight")
+				}
+				// every agent loses half its HP, rounding up
+				want := uint(0)
+				for id, hp := range hp {
+					penalty := (hp + 1) / 2
+					want += penalty
+					if got := after.AgentState[id].Hp; got != hp-penalty {
+						t.Errorf("agent %s has %d HP after losing half its %d, want %d", id, got, hp, hp-penalty)
+					}
+				}
+				if got := level.FightStage.StalematePenalty; got != want {
+					t.Errorf("stalemate penalty = %d, want %d", got, want)
+				}
+			},
+		},
+		{
+			name:      "retreat",
+			stalemate: config.Retreat,
+			maxRounds: 2,
+			stages:    []string{"election", "hp-pool-donation", "fight", "loot"},
+			strategy:  func() agent.Strategy { return generous{team3.NewAgentThreeNeutral()} },
+			check: func(t *testing.T, level logging.LevelStages, _, after *state.State, _ map[commons.ID]uint) {
+				if after == nil {
+					t.Fatal("no agent survived the fight")
+				}
+				if level.HPPoolStage.NewHPPool == 0 {
+					t.Fatal("the agents donated nothing to the HP pool")
+				}
+				if got, want := level.FightStage.StalematePenalty, level.HPPoolStage.NewHPPool; got != want {
+					t.Errorf("retreat cost %d, want the whole HP pool of %d", got, want)
+				}
+				if after.HpPool != 0 {
+					t.Errorf("HP pool after retreating = %d, want 0", after.HpPool)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// agents too weak to kill the monster in two rounds
+			cfg := testConfig(1)
+			cfg.Game.NumLevels = 60
+			cfg.Game.StartingAttackStrength = 2
+			cfg.Game.MaxFightRounds = tt.maxRounds
+			cfg.Game.Stalemate = tt.stalemate
+			cfg.Game.EnrageRate = 0.5
+			cfg.Game.StalematePenalty = 0.5
+			cfg.Game.Stages = tt.stages
+			cfg.Game.AgentQuantities = map[string]uint{"COLLECTIVE": 15}
+			strategy := tt.strategy
+			if strategy == nil {
+				strategy = team3.NewAgentThreeNeutral
+			}
+			game, err := engine.New(cfg, engine.Registry{"COLLECTIVE": strategy})
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			before, err := game.Checkpoint()
+			if err != nil {
+				t.Fatalf("Checkpoint() error = %v", err)
+			}
+			// the checkpoint shares the game's state, which the level changes
+			hpBefore := make(map[commons.ID]uint, len(before.State.AgentState))
+			for id, agentState := range before.State.AgentState {
+				hpBefore[id] = agentState.Hp
+			}
+			beforeState := *before.State
+			if err := game.Step(); err != nil {
+				t.Fatalf("Step() error = %v", err)
+			}
+			var after *state.State
+			if !game.Done() {
+				cp, err := game.Checkpoint()
+				if err != nil {
+					t.Fatalf("Checkpoint() error = %v", err)
+				}
+				after = cp.State
+			}
+
+			level := game.Result().Log.Levels[0]
+			if tt.maxRounds > 0 {
+				if level.FightStage.Stalemate != string(tt.stalemate) {
+					t.Fatalf("fight stalemate = %q, want %q", level.FightStage.Stalemate, tt.stalemate)
+				}
+				if tt.stalemate != config.Enrage {
+					if rounds := len(level.FightStage.Rounds); rounds != 2 {
+						t.Errorf("fight lasted %d rounds, want 2", rounds)
+					}
+					if level.LootStage.Occurred {
+						t.Errorf("loot was shared out after a %s", tt.stalemate)
+					}
+				}
+			}
+			tt.check(t, level, &beforeState, after, hpBefore)
+		})
+	}
+}
+
+func TestMonsterAbilities(t *testing.T) {
+	t.Parallel()
+
+	cfg := testConfig(1)
+	cfg.Game.MonsterAbilityChance = 1
+	cfg.Game.AgentQuantities = map[string]uint{"COLLECTIVE": 15}
+	game, err := engine.New(cfg, engine.Registry{"COLLECTIVE": team3.NewAgentThreeNeutral})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := game.Step(); err != nil {
+		t.Fatalf("Step() error = %v", err)
+	}
+
+	level := game.Result().Log.Levels[0]
+	if len(level.LevelStats.MonsterAbilities) != len(state.Abilities) {
+		t.Errorf("monster abilities = %v, want all of them", level.LevelStats.MonsterAbilities)
+	}
+	if len(level.FightStage.Rounds) == 0 {
+		t.Fatalf("no fight rounds were played")
+	}
+	for _, a := range []state.Ability{state.Armour, state.StaminaDrain, state.AreaDamage} {
+		if level.FightStage.Rounds[0].Abilities[string(a)] == 0 {
+			t.Errorf("%s had no effect in the first round: %v", a, level.FightStage.Rounds[0].Abilities)
+		}
+	}
+}
+
+func TestElectionResult(t *testing.T) {
+	t.Parallel()
+
+	for strategy := uint(0); strategy < decision.NumVotingStrategies; strategy++ {
+		strategy := strategy
+		t.Run(fmt.Sprint(strategy), func(t *testing.T) {
+			t.Parallel()
+
+			cfg := testConfig(1)
+			cfg.Game.VotingStrategy = strategy
+			cfg.Game.AgentQuantities = map[string]uint{"COLLECTIVE": 15}
+			game, err := engine.New(cfg, engine.Registry{"COLLECTIVE": team3.NewAgentThreeNeutral})
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			if err := game.Step(); err != nil {
+				t.Fatalf("Step() error = %v", err)
+			}
+
+			election := game.Result().Log.Levels[0].ElectionStage
+			if !election.Occurred {
+				t.Fatal("no election was held on the first level")
+			}
+			if election.Electorate != 15 || election.Ballots+election.Abstentions != election.Electorate {
+				t.Errorf("electorate %d cast %d ballots and %d abstentions, want 15 in all", election.Electorate, election.Ballots, election.Abstentions)
+			}
+			if election.Ballots == 0 {
+				return
+			}
+			if _, ok := election.Candidates[election.Winner]; !ok {
+				t.Errorf("winner %s is not among the candidates %v", election.Winner, election.Candidates)
+			}
+			if _, ok := election.Tally[election.Winner]; !ok {
+				t.Errorf("winner %s is not in the tally %v", election.Winner, election.Tally)
+			}
+		})
+	}
+}
+
+// opposed is a strategy that votes against every leader.
+type opposed struct {
+	agent.Strategy
+}
+
+func (opposed) HandleConfidencePoll(agent.BaseAgent) decision.Intent {
+	return decision.Negative
+}
+
+func TestConfidenceVote(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		strategy func() agent.Strategy
+		// quorum is the fraction of agents that must vote for or against
+		quorum     float32
+		wantPassed bool
+		wantFailed bool
+	}{
+		{
+			name:       "inquorate motions fail",
+			strategy:   team3.NewAgentThreeNeutral,
+			quorum:     1,
+			wantFailed: true,
+		},
+		{
+			name:       "every agent against",
+			strategy:   func() agent.Strategy { return opposed{team3.NewAgentThreeNeutral()} },
+			wantPassed: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := testConfig(1)
+			cfg.Game.NumLevels = 60
+			cfg.Game.PublicConfidenceVotes = true
+			cfg.Game.ConfidenceCooldown = 2
+			cfg.Game.ConfidenceQuorum = tt.quorum
+			cfg.Game.AgentQuantities = map[string]uint{"COLLECTIVE": 15}
+			game, err := engine.New(cfg, engine.Registry{"COLLECTIVE": tt.strategy})
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			result, err := game.Run(context.Background())
+			if err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+
+			passed, failed, cooldown := 0, 0, 0
+			for _, level := range result.Log.Levels {
+				vote, election := level.VONCStage, level.ElectionStage
+				if !vote.Occurred {
+					// an election ends the cooldown
+					if cooldown--; election.Occurred {
+						cooldown = 0
+					}
+					continue
+				}
+				if cooldown > 0 {
+					t.Errorf("level %d held a motion %d levels into the cooldown", level.LevelStats.CurrentLevel, 2-cooldown)
+				}
+				if n := uint(len(vote.Votes)); n != vote.For+vote.Against+vote.Abstain {
+					t.Errorf("level %d logged %d public votes of %d cast", level.LevelStats.CurrentLevel, n, vote.For+vote.Against+vote.Abstain)
+				}
+				if !vote.Passed {
+					failed++
+					cooldown = 2
+					if election.Occurred {
+						t.Errorf("level %d held an election after a failed motion", level.LevelStats.CurrentLevel)
+					}
+					continue
+				}
+				passed, cooldown = passed+1, 0
+				// the election held on the leader's overthrow is logged in full
+				if !election.Occurred || election.Winner != level.LevelStats.LeaderAfterElection || election.Electorate != vote.For+vote.Against+vote.Abstain {
+					t.Errorf("level %d ousted its leader but logged election %+v", level.LevelStats.CurrentLevel, election)
+				}
+				if _, ok := election.Tally[election.Winner]; !ok {
+					t.Errorf("level %d logged winner %s missing from the tally %v", level.LevelStats.CurrentLevel, election.Winner, election.Tally)
+				}
+			}
+			if (passed > 0) != tt.wantPassed || (failed > 0) != tt.wantFailed {
+				t.Errorf("%d motions passed and %d failed, want passed %v, failed %v", passed, failed, tt.wantPassed, tt.wantFailed)
+			}
+		})
+	}
+}
+
+// committed is a strategy whose manifestos commit it to policies.
+type committed struct {
+	agent.Strategy
+	policies decision.Policies
+}
+
+func (c committed) CreateManifesto(baseAgent agent.BaseAgent) *decision.Manifesto {
+	return c.Strategy.CreateManifesto(baseAgent).WithPolicies(c.policies)
+}
+
+func TestManifestoPolicies(t *testing.T) {
+	t.Parallel()
+
+	policies := decision.Policies{
+		HPPoolTax:          50,
+		LootAllocation:     decision.NeedsFirst,
+		Sanctions:          decision.GraduatedSanctions,
+		MaxLeaderLootShare: 10,
+	}
+	commit := func(newStrategy func() agent.Strategy) func() agent.Strategy {
+		return func() agent.Strategy { return committed{Strategy: newStrategy(), policies: policies} }
+	}
+	cfg := testConfig(7)
+	cfg.Game.NumLevels = 60
+	cfg.Game.AgentQuantities = map[string]uint{"COLLECTIVE": 5, "SELFLESS": 5, "SELFISH": 5}
+	game, err := engine.New(cfg, engine.Registry{
+		"COLLECTIVE": commit(team3.NewAgentThreeNeutral),
+		"SELFLESS":   commit(team3.NewAgentThreePassive),
+		"SELFISH":    commit(team3.NewAgentThreeAggressive),
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	result, err := game.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	elections, taxed := 0, uint(0)
+	for _, level := range result.Log.Levels {
+		if election := level.ElectionStage; election.Occurred {
+			elections++
+			got := election.Manifesto
+			if got.HPPoolTax != 50 || got.LootAllocation != "needs" || got.Sanctions != "graduated" || got.MaxLeaderLootShare != 10 {
+				t.Errorf("level %d logged manifesto %+v, want the committed policies", level.LevelStats.CurrentLevel, got)
+			}
+		}
+		taxed += level.HPPoolStage.Taxed
+	}
+	if elections == 0 {
+		t.Fatal("no elections were held")
+	}
+	if taxed == 0 {
+		t.Error("the HP pool tax took nothing beyond the donations offered")
+	}
+}
+
+func TestCampaign(t *testing.T) {
+	t.Parallel()
+
+	cfg := testConfig(7)
+	cfg.Game.NumLevels = 60
+	cfg.Game.CampaignRounds = 3
+	cfg.Game.AgentQuantities = map[string]uint{"COLLECTIVE": 5, "SELFLESS": 5, "SELFISH": 5}
+	game, err := engine.New(cfg, engine.Registry{
+		"COLLECTIVE": team3.NewAgentThreeNeutral,
+		"SELFLESS":   team3.NewAgentThreePassive,
+		"SELFISH":    team3.NewAgentThreeAggressive,
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	result, err := game.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	promises, endorsements := 0, 0
+	for _, level := range result.Log.Levels {
+		election := level.ElectionStage
+		if !election.Occurred {
+			continue
+		}
+		if election.Campaign == nil {
+			t.Fatalf("level %d held an election without a campaign", level.LevelStats.CurrentLevel)
+		}
+		for _, p := range election.Campaign.Promises {
+			promises++
+			if _, ok := election.Candidates[p.Candidate]; !ok {
+				t.Errorf("level %d logged a promise from %s, who did not stand", level.LevelStats.CurrentLevel, p.Candidate)
+			}
+			// team 3 promises what its manifesto commits to
+			if !p.Kept {
+				t.Errorf("level %d logged %s's promise as broken", level.LevelStats.CurrentLevel, p.Candidate)
+			}
+		}
+		for candidate, endorsers := range election.Campaign.Endorsements {
+			endorsements += len(endorsers)
+			if _, ok := election.Candidates[candidate]; !ok {
+				t.Errorf("level %d logged endorsements of %s, who did not stand", level.LevelStats.CurrentLevel, candidate)
+			}
+		}
+	}
+	if promises == 0 || endorsements == 0 {
+		t.Errorf("campaigns made %d promises and %d endorsements, want some of each", promises, endorsements)
+	}
+}
+
+func TestDeathDrop(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		deathDrop config.DeathDrop
+		want      bool
+	}{
+		{name: "none", deathDrop: config.NoDrop},
+		{name: "corpse", deathDrop: config.CorpseLoot, want: true},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// a long enough game for some agents to die in the fights
+			cfg := testConfig(1)
+			cfg.Game.NumLevels = 60
+			cfg.Game.DeathDrop = tt.deathDrop
+			cfg.Game.DeathDropStamina = 0.5
+			cfg.Game.AgentQuantities = map[string]uint{"COLLECTIVE": 15}
+			game, err := engine.New(cfg, engine.Registry{"COLLECTIVE": team3.NewAgentThreeNeutral})
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			result, err := game.Run(context.Background())
+			if err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+
+			looted := false
+			for _, level := range result.Log.Levels {
+				if stage := level.CorpseLootStage; stage.Occurred {
+					looted = true
+					if stage.Corpses == 0 || stage.Items < stage.Corpses {
+						t.Errorf("level %d looted %d items from %d corpses, want a stamina potion each at least", level.LevelStats.CurrentLevel, stage.Items, stage.Corpses)
+					}
+				}
+			}
+			if looted != tt.want {
+				t.Errorf("corpses looted = %v, want %v", looted, tt.want)
+			}
+		})
+	}
+}
+
+// martyr is a strategy that donates all its HP to the pool once it holds an
+// item.
+type martyr struct {
+	agent.Strategy
+}
+
+func (martyr) DonateToHpPool(baseAgent agent.BaseAgent) uint {
+	agentState := baseAgent.AgentState()
+	if agentState.Weapons.Len()+agentState.Shields.Len() == 0 {
+		return 0
+	}
+	return agentState.Hp
+}
+
+func TestStrict(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		seed    int64
+		martyrs bool
+	}{
+		{name: "seed 1", seed: 1},
+		{name: "seed 2", seed: 2},
+		{name: "seed 3", seed: 3},
+		// selfless agents that donate all their HP die holding items,
+		// which must leave the inventory map with them
+		{name: "martyrs", seed: 1, martyrs: true},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := testConfig(tt.seed)
+			cfg.Game.NumLevels = 60
+			cfg.Game.DeathDrop = config.CorpseLoot
+			cfg.Game.AgentQuantities = map[string]uint{"COLLECTIVE": 5, "SELFLESS": 5, "SELFISH": 5}
+			cfg.Strict = true
+			registry := engine.Registry{
+				"COLLECTIVE": team3.NewAgentThreeNeutral,
+				"SELFLESS":   team3.NewAgentThreePassive,
+				"SELFISH":    team3.NewAgentThreeAggressive,
+			}
+			if tt.martyrs {
+				registry["SELFLESS"] = func() agent.Strategy { return martyr{team3.NewAgentThreePassive()} }
+			}
+			game, err := engine.New(cfg, registry)
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			if _, err := game.Run(context.Background()); err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+		})
+	}
+}
+
+// delegating is a strategy that appoints other agents to its cabinet and
+// votes against every member of the cabinet.
+type delegating struct {
+	agent.Strategy
+}
+
+func (d delegating) AppointCabinet(baseAgent agent.BaseAgent, roles []decision.Role) map[decision.Role]commons.ID {
+	appointed := make(map[decision.Role]commons.ID, len(roles))
+	view := baseAgent.View()
+	agents := view.AgentState()
+	ids := agents.Iterator()
+	for _, role := range roles {
+		for !ids.Done() {
+			id, _, _ := ids.Next()
+			if id != baseAgent.ID() {
+				appointed[role] = id
+				break
+			}
+		}
+	}
+	return appointed
+}
+
+func (d delegating) HandleCabinetConfidencePoll(agent.BaseAgent, decision.Role, commons.ID) decision.Intent {
+	return decision.Negative
+}
+
+func TestCabinet(t *testing.T) {
+	t.Parallel()
+
+	for _, cabinet := range []config.Cabinet{config.AppointedCabinet, config.ElectedCabinet} {
+		cabinet := cabinet
+		t.Run(string(cabinet), func(t *testing.T) {
+			t.Parallel()
+
+			delegate := func(newStrategy func() agent.Strategy) func() agent.Strategy {
+				return func() agent.Strategy { return delegating{newStrategy()} }
+			}
+			cfg := testConfig(7)
+			cfg.Game.NumLevels = 60
+			cfg.Game.Cabinet = cabinet
+			// leaders survive motions that not every agent votes in, but
+			// their cabinets, which every agent votes against, do not
+			cfg.Game.ConfidenceQuorum = 1
+			cfg.Game.AgentQuantities = map[string]uint{"COLLECTIVE": 5, "SELFLESS": 5, "SELFISH": 5}
+			game, err := engine.New(cfg, engine.Registry{
+				"COLLECTIVE": delegate(team3.NewAgentThreeNeutral),
+				"SELFLESS":   delegate(team3.NewAgentThreePassive),
+				"SELFISH":    delegate(team3.NewAgentThreeAggressive),
+			})
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			result, err := game.Run(context.Background())
+			if err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+
+			elections, ousted := 0, 0
+			for _, level := range result.Log.Levels {
+				if election := level.ElectionStage; election.Occurred {
+					elections++
+					if len(election.Cabinet) != len(decision.Roles) {
+						t.Errorf("level %d filled cabinet %v, want every role", level.LevelStats.CurrentLevel, election.Cabinet)
+					}
+					for role, holder := range election.Cabinet {
+						if cabinet == config.AppointedCabinet && holder == election.Winner {
+							t.Errorf("level %d appointed leader %s as %s", level.LevelStats.CurrentLevel, holder, role)
+						}
+					}
+				}
+				for _, motion := range level.VONCStage.Cabinet {
+					if !motion.Passed {
+						continue
+					}
+					ousted++
+					if motion.Successor == motion.Holder {
+						t.Errorf("level %d gave %s the %s role straight back", level.LevelStats.CurrentLevel, motion.Holder, motion.Role)
+					}
+				}
+			}
+			if elections == 0 {
+				t.Fatal("no elections were held")
+			}
+			if ousted == 0 {
+				t.Error("no cabinet member was ousted")
+			}
+		})
+	}
+}
+
+// amending is a strategy that proposes to bound terms to a single level on
+// the first level and to shorten the game on the second, and votes for every
+// amendment.
+type amending struct {
+	agent.Strategy
+}
+
+func (a amending) ProposeAmendment(baseAgent agent.BaseAgent) *decision.Amendment {
+	view := baseAgent.View()
+	switch view.CurrentLevel() {
+	case 1:
+		return &decision.Amendment{Setting: "MAX_TERM", Value: "1"}
+	case 2:
+		return &decision.Amendment{Setting: "LEVELS", Value: "2"}
+	}
+	return nil
+}
+
+func (a amending) HandleReferendum(agent.BaseAgent, commons.ID, decision.Amendment) decision.Intent {
+	return decision.Positive
+}
+
+func TestReferendum(t *testing.T) {
+	t.Parallel()
+
+	amend := func(newStrategy func() agent.Strategy) func() agent.Strategy {
+		return func() agent.Strategy { return amending{newStrategy()} }
+	}
+	cfg := testConfig(7)
+	cfg.Game.NumLevels = 60
+	cfg.Game.Stages = append(append([]string{}, engine.DefaultStages...), "referendum")
+	cfg.Game.AgentQuantities = map[string]uint{"COLLECTIVE": 5, "SELFLESS": 5, "SELFISH": 5}
+	registry := engine.Registry{
+		"COLLECTIVE": amend(team3.NewAgentThreeNeutral),
+		"SELFLESS":   amend(team3.NewAgentThreePassive),
+		"SELFISH":    amend(team3.NewAgentThreeAggressive),
+	}
+	game, err := engine.New(cfg, registry)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	result, err := game.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	for _, amendment := range result.Log.Amendments {
+		switch amendment.Setting {
+		case "MAX_TERM":
+			if !amendment.Passed || amendment.Level != 1 {
+				t.Errorf("amendment %+v did not pass on level 1", amendment)
+			}
+		case "LEVELS":
+			if amendment.Passed || amendment.Rejected == "" {
+				t.Errorf("amendment %+v to a setting that is not amendable was put to a vote", amendment)
+			}
+		}
+	}
+	if len(result.Log.Amendments) == 0 {
+		t.Fatal("no amendments were logged")
+	}
+	if len(result.Log.Levels) <= 2 {
+		t.Fatalf("game ended after %d levels, want it to outlast the rejected amendment", len(result.Log.Levels))
+	}
+
+	// from the first election held under the amended rules, terms last a
+	// single level
+	bounded := false
+	for _, level := range result.Log.Levels[1:] {
+		if bounded && !level.ElectionStage.Occurred && !level.VONCStage.Passed {
+			t.Errorf("level %d held no election after a single level term", level.LevelStats.CurrentLevel)
+		}
+		bounded = bounded || level.ElectionStage.Occurred
+	}
+	if !bounded {
+		t.Error("no election was held after the first level")
+	}
+	// a game resumed after an amendment passed applies it again
+	game, err = engine.New(cfg, registry)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := game.Step(); err != nil {
+		t.Fatalf("Step() error = %v", err)
+	}
+	cp, err := game.Checkpoint()
+	if err != nil {
+		t.Fatalf("Checkpoint() error = %v", err)
+	}
+	if len(cp.Amendments) == 0 {
+		t.Error("checkpoint has no amendments")
+	}
+	if _, err := engine.Resume(cp, cfg, registry); err != nil {
+		t.Errorf("Resume() error = %v", err)
+	}
+}
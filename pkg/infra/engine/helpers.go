@@ -0,0 +1,692 @@
+package engine
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+
+	cmdline "infra/cmdLine"
+	"infra/config"
+	"infra/game/agent"
+	"infra/game/commons"
+	"infra/game/decision"
+	gamemath "infra/game/math"
+	"infra/game/rng"
+	"infra/game/stage/confidence"
+	"infra/game/stage/election"
+	"infra/game/stage/fight"
+	"infra/game/stage/loot"
+	"infra/game/stages"
+	"infra/game/state"
+	"infra/logging"
+
+	"golang.org/x/exp/maps"
+)
+
+/*
+	State Helpers
+*/
+
+func (g *Game) updateView() {
+	*g.view = g.state.ToView()
+}
+
+/*
+	Communication Helpers
+*/
+
+// addComms gives every living agent a fresh outbox and the other living
+// agents as its peers.
+func (g *Game) addComms() {
+	ids := maps.Keys(g.agentMap)
+	for id, a := range g.agentMap {
+		peers := make([]commons.ID, 0, len(ids)-1)
+		for _, peer := range ids {
+			if peer != id {
+				peers = append(peers, peer)
+			}
+		}
+		a.SetCommunication(agent.NewCommunication(peers))
+	}
+}
+
+/*
+	Election Helpers
+*/
+
+// runElection elects a leader, returning its term and the election's result.
+func (g *Game) runElection() (uint, *decision.ElectionResult) {
+	var campaign *agent.Rounds
+	if g.gameConfig.CampaignRounds > 0 {
+		campaign = &agent.Rounds{Max: g.gameConfig.CampaignRounds, Deadline: g.rounds.Deadline}
+	}
+	// every term has a fresh vote budget, the election that starts it included
+	g.state.VoteCredits = nil
+	result := election.HandleElection(g.state, g.agentMap, election.Rules{
+		Strategy:    decision.VotingStrategy(g.gameConfig.VotingStrategy),
+		Preferences: g.gameConfig.VotingPreferences,
+		MaxScore:    g.gameConfig.MaxScore,
+		Campaign:    campaign,
+		Weights:     g.voteWeights(),
+	}, g.source.Stream("election", g.state.CurrentLevel))
+	termLeft := g.termLength(result.Manifesto.TermLength())
+	// a failed motion against the last leader does not hold back one against the new
+	g.confidenceCooldown = 0
+	g.state.LeaderManifesto = result.Manifesto
+	g.state.CurrentLeader = result.Winner
+	g.state.SanctionConfig = g.sanctionConfig()
+	g.state.Cabinet = nil
+	g.updateView()
+	g.fillCabinet(decision.Roles)
+	return termLeft, result
+}
+
+// termLength is the number of levels a leader whose manifesto asks for term
+// serves, within the game's term length bounds.
+func (g *Game) termLength(term uint) uint {
+	if term < g.gameConfig.MinTermLength {
+		term = g.gameConfig.MinTermLength
+	}
+	if max := g.gameConfig.MaxTermLength; max > 0 && term > max {
+		term = max
+	}
+	return term
+}
+
+// fillCabinet has the leader appoint, or the agents elect, a holder to each
+// of roles, as the game's cabinet setting says.
+func (g *Game) fillCabinet(roles []decision.Role) {
+	switch g.gameConfig.Cabinet {
+	case config.AppointedCabinet:
+		leader, alive := g.agentMap[g.state.CurrentLeader]
+		if !alive {
+			return
+		}
+		appointed := leader.HandleAppointCabinet(g.state.AgentState[leader.ID()], roles)
+		for _, role := range roles {
+			if _, alive := g.agentMap[appointed[role]]; alive {
+				g.setHolder(role, appointed[role])
+			}
+		}
+	case config.ElectedCabinet:
+		for _, role := range roles {
+			result := election.HandleElection(g.state, g.agentMap, election.Rules{
+				Strategy:    decision.VotingStrategy(g.gameConfig.VotingStrategy),
+				Preferences: g.gameConfig.VotingPreferences,
+				MaxScore:    g.gameConfig.MaxScore,
+				Weights:     g.voteWeights(),
+			}, g.source.Stream("cabinet-election", role, g.state.CurrentLevel))
+			g.setHolder(role, result.Winner)
+		}
+	default:
+		return
+	}
+	g.updateView()
+	logging.Log(logging.Info, logging.LogField{"cabinet": g.state.Cabinet, "leader": g.state.CurrentLeader}, "Cabinet Filled")
+}
+
+func (g *Game) setHolder(role decision.Role, id commons.ID) {
+	if g.state.Cabinet == nil {
+		g.state.Cabinet = make(map[decision.Role]commons.ID, len(decision.Roles))
+	}
+	g.state.Cabinet[role] = id
+}
+
+// holder is the living agent holding role, the leader if nobody else does.
+func (g *Game) holder(role decision.Role) commons.ID {
+	if id, ok := g.state.Cabinet[role]; ok {
+		if _, alive := g.agentMap[id]; alive {
+			return id
+		}
+	}
+	return g.state.CurrentLeader
+}
+
+// cabinetLog is the cabinet as it is logged.
+func (g *Game) cabinetLog() map[string]commons.ID {
+	if len(g.state.Cabinet) == 0 {
+		return nil
+	}
+	cabinet := make(map[string]commons.ID, len(g.state.Cabinet))
+	for role, id := range g.state.Cabinet {
+		cabinet[string(role)] = id
+	}
+	return cabinet
+}
+
+func (g *Game) electionLog(result *decision.ElectionResult) logging.ElectionStage {
+	candidates := make(map[commons.ID]logging.ManifestoLog, len(result.Candidates))
+	for id, manifesto := range result.Candidates {
+		candidates[id] = manifestoLog(manifesto)
+	}
+	return logging.ElectionStage{
+		Occurred:    true,
+		Winner:      g.state.CurrentLeader,
+		Team:        g.agentMap[g.state.CurrentLeader].BaseAgent.Name(),
+		Manifesto:   manifestoLog(g.state.LeaderManifesto),
+		Candidates:  candidates,
+		Tally:       result.Tally,
+		Electorate:  result.Electorate,
+		Ballots:     result.Ballots,
+		Abstentions: result.Abstentions,
+		Turnout:     result.Turnout(),
+		Weights:     result.Weights,
+		Margin:      result.Margin,
+		Rounds:      result.Rounds,
+		Revised:     result.Revised,
+		Condorcet:   result.Condorcet,
+		Campaign:    result.Campaign,
+		Cabinet:     g.cabinetLog(),
+	}
+}
+
+func manifestoLog(manifesto decision.Manifesto) logging.ManifestoLog {
+	return logging.ManifestoLog{
+		FightImposition:     manifesto.FightDecisionPower(),
+		LootImposition:      manifesto.LootDecisionPower(),
+		TermLength:          manifesto.TermLength(),
+		ThresholdPercentage: manifesto.OverthrowThreshold(),
+		PoliciesLog:         manifesto.Policies().Log(),
+	}
+}
+
+func (g *Game) electionFields() logging.LogField {
+	return logging.LogField{
+		"Fight Imp": g.state.LeaderManifesto.FightDecisionPower(),
+		"Loot Imp":  g.state.LeaderManifesto.LootDecisionPower(),
+		"Term":      g.state.LeaderManifesto.TermLength(),
+		"Threshold": g.state.LeaderManifesto.OverthrowThreshold(),
+		"Winner":    g.state.CurrentLeader,
+		"Team":      g.agentMap[g.state.CurrentLeader].BaseAgent.Name(),
+	}
+}
+
+// runConfidenceVote holds a no-confidence motion on the leader, making the
+// votes public if the game is configured to.
+func (g *Game) runConfidenceVote() confidence.Result {
+	result := confidence.HandleConfidenceVote(g.state, g.agentMap, confidence.Rules{
+		Threshold:   g.state.LeaderManifesto.OverthrowThreshold(),
+		Quorum:      g.gameConfig.ConfidenceQuorum,
+		Abstentions: g.gameConfig.ConfidenceAbstentions,
+		Weights:     g.voteWeights(),
+	})
+	if g.gameConfig.PublicConfidenceVotes {
+		g.state.ConfidenceVotes = result.Votes
+		g.updateView()
+	}
+
+	logging.Log(logging.Info, logging.LogField{
+		"positive":  result.Tally[decision.Positive],
+		"negative":  result.Tally[decision.Negative],
+		"abstain":   result.Tally[decision.Abstain],
+		"quorate":   result.Quorate,
+		"threshold": g.state.LeaderManifesto.OverthrowThreshold(),
+		"leader":    g.state.CurrentLeader,
+		"team":      g.agentMap[g.state.CurrentLeader].BaseAgent.Name(),
+	}, "Confidence Vote")
+	return result
+}
+
+// runCabinetVotes holds a no-confidence motion on each member of the
+// cabinet, other than the leader, replacing those ousted.
+func (g *Game) runCabinetVotes() []logging.CabinetMotion {
+	var motions []logging.CabinetMotion
+	for _, role := range decision.Roles {
+		holder, ok := g.state.Cabinet[role]
+		if _, alive := g.agentMap[holder]; !ok || !alive || holder == g.state.CurrentLeader {
+			continue
+		}
+		result := confidence.HandleCabinetVote(g.state, g.agentMap, role, holder, confidence.Rules{
+			Threshold:   g.state.LeaderManifesto.OverthrowThreshold(),
+			Quorum:      g.gameConfig.ConfidenceQuorum,
+			Abstentions: g.gameConfig.ConfidenceAbstentions,
+			Weights:     g.voteWeights(),
+		})
+		motion := logging.CabinetMotion{
+			Role:    string(role),
+			Holder:  holder,
+			For:     result.Tally[decision.Positive],
+			Against: result.Tally[decision.Negative],
+			Abstain: result.Tally[decision.Abstain],
+			Quorate: result.Quorate,
+			Passed:  result.Passed,
+		}
+		if result.Passed {
+			logging.Log(logging.Info, logging.LogField{"role": role}, fmt.Sprintf("%s got ousted", holder))
+			delete(g.state.Cabinet, role)
+			g.fillCabinet([]decision.Role{role})
+			// an ousted holder is not given the role straight back
+			if g.state.Cabinet[role] == holder {
+				delete(g.state.Cabinet, role)
+				g.updateView()
+			}
+			motion.Successor = g.holder(role)
+		}
+		motions = append(motions, motion)
+	}
+	return motions
+}
+
+// voteWeights is how much each living agent's vote counts by the game's vote
+// weighting, or nil if every vote counts once. Under quadratic voting, each
+// agent spends the credits it chooses from its budget for the term.
+func (g *Game) voteWeights() map[commons.ID]float64 {
+	ids := maps.Keys(g.agentMap)
+	sort.Strings(ids)
+	weights := make(map[commons.ID]float64, len(ids))
+	switch g.gameConfig.VoteWeighting {
+	case config.ContributionWeights:
+		for _, id := range ids {
+			weights[id] = float64(g.state.Contributions[id])
+		}
+	case config.HPWeights:
+		for _, id := range ids {
+			weights[id] = float64(g.state.AgentState[id].Hp)
+		}
+	case config.ReputationWeights:
+		for _, id := range ids {
+			// agents spoken ill of have no say, rather than a say against
+			weights[id] = math.Max(g.state.Reputation[id], 0)
+		}
+	case config.QuadraticWeights:
+		if g.state.VoteCredits == nil {
+			g.state.VoteCredits = make(map[commons.ID]uint, len(ids))
+			for _, id := range ids {
+				g.state.VoteCredits[id] = g.gameConfig.VoteBudget
+			}
+		}
+		for _, id := range ids {
+			a := g.agentMap[id]
+			credits := a.HandleVoteCredits(g.state.AgentState[id], g.state.VoteCredits[id])
+			g.state.VoteCredits[id] -= credits
+			weights[id] = math.Sqrt(float64(credits))
+		}
+	default:
+		return nil
+	}
+	return weights
+}
+
+/*
+	Fight Helpers
+*/
+
+// damageCalculation settles a fight round, sharing the monster's damage out
+// by policy. It returns the damage each agent took and what each of the
+// monster's abilities did.
+func (g *Game) damageCalculation(fightRoundResult decision.FightResult, policy fight.DamagePolicy) (map[commons.ID]uint, map[string]uint) {
+	abilities := g.state.MonsterAbilities
+	effects := make(map[string]uint)
+	damage := make(map[commons.ID]uint)
+	deal := func(p fight.DamagePolicy, damageToDeal uint, ids []commons.ID) {
+		for id, taken := range fight.DealDamage(p, damageToDeal, ids, fightRoundResult.Choices, g.agentMap, g.state) {
+			damage[id] += taken
+		}
+	}
+	if len(fightRoundResult.CoweringAgents) != len(g.agentMap) {
+		attackSum := fightRoundResult.AttackSum
+		if strength, ok := abilities[state.Armour]; ok {
+			absorbed := uint(float64(attackSum) * strength)
+			attackSum -= absorbed
+			effects[string(state.Armour)] = absorbed
+		}
+		g.state.MonsterHealth = commons.SaturatingSub(g.state.MonsterHealth, attackSum)
+		agentsFighting := append(fightRoundResult.AttackingAgents, fightRoundResult.ShieldingAgents...)
+		if strength, ok := abilities[state.StaminaDrain]; ok {
+			effects[string(state.StaminaDrain)] = g.drainStamina(agentsFighting, uint(strength))
+		}
+		if g.state.MonsterHealth > 0 {
+			monsterAttack := g.state.MonsterAttack
+			areaDamage := uint(0)
+			if strength, ok := abilities[state.AreaDamage]; ok {
+				areaDamage = uint(float64(monsterAttack) * strength)
+				monsterAttack -= areaDamage
+			}
+			if fightRoundResult.ShieldSum < monsterAttack {
+				damageTaken := monsterAttack - fightRoundResult.ShieldSum
+				// targets are picked before the damage is dealt
+				targeted := make(map[state.Ability]uint)
+				targets := make(map[state.Ability]commons.ID)
+				for _, a := range []state.Ability{state.TargetLeader, state.TargetWeakest} {
+					if target, ok := g.target(a, agentsFighting); ok {
+						targets[a], targeted[a] = target, uint(float64(damageTaken)*abilities[a])
+						damageTaken -= targeted[a]
+					}
+				}
+				deal(policy, damageTaken, agentsFighting)
+				for _, a := range []state.Ability{state.TargetLeader, state.TargetWeakest} {
+					if target, ok := targets[a]; ok {
+						if _, alive := g.agentMap[target]; alive {
+							deal(fight.EvenSplit{}, targeted[a], []commons.ID{target})
+						}
+						effects[string(a)] = targeted[a]
+					}
+				}
+			}
+			if areaDamage > 0 && len(g.agentMap) > 0 {
+				ids := maps.Keys(g.agentMap)
+				sort.Strings(ids)
+				deal(fight.EvenSplit{}, areaDamage, ids)
+				effects[string(state.AreaDamage)] = areaDamage
+			}
+		}
+	} else {
+		damageTaken := g.state.MonsterAttack
+		deal(policy, damageTaken, fightRoundResult.CoweringAgents)
+	}
+	if strength, ok := abilities[state.Regeneration]; ok && g.state.MonsterHealth > 0 {
+		g.state.MonsterHealth += uint(strength)
+		effects[string(state.Regeneration)] = uint(strength)
+	}
+	g.updateView()
+	return damage, effects
+}
+
+// monsterAbilities are the monster's abilities as they are logged.
+func (g *Game) monsterAbilities() map[string]float64 {
+	if len(g.state.MonsterAbilities) == 0 {
+		return nil
+	}
+	abilities := make(map[string]float64, len(g.state.MonsterAbilities))
+	for a, strength := range g.state.MonsterAbilities {
+		abilities[string(a)] = strength
+	}
+	return abilities
+}
+
+// target picks the agent that ability a turns the monster's damage on from
+// those fighting, if the monster has it.
+func (g *Game) target(a state.Ability, agentsFighting []commons.ID) (commons.ID, bool) {
+	if !g.state.MonsterAbilities.Has(a) {
+		return "", false
+	}
+	switch a {
+	case state.TargetLeader:
+		for _, id := range agentsFighting {
+			if id == g.state.CurrentLeader {
+				return id, true
+			}
+		}
+	case state.TargetWeakest:
+		weakest, found := "", false
+		for _, id := range agentsFighting {
+			hp := g.state.AgentState[id].Hp
+			if !found || hp < g.state.AgentState[weakest].Hp || (hp == g.state.AgentState[weakest].Hp && id < weakest) {
+				weakest, found = id, true
+			}
+		}
+		return weakest, found
+	}
+	return "", false
+}
+
+// drainStamina takes drain stamina from each agent, returning the total taken.
+func (g *Game) drainStamina(ids []commons.ID, drain uint) uint {
+	total := uint(0)
+	for _, id := range ids {
+		agentState, ok := g.state.AgentState[id]
+		if !ok {
+			continue
+		}
+		taken := drain
+		if agentState.Stamina < taken {
+			taken = agentState.Stamina
+		}
+		agentState.Stamina -= taken
+		g.state.AgentState[id] = agentState
+		total += taken
+	}
+	return total
+}
+
+// tooFewAgents reports, and logs, whether the game is lost for lack of agents.
+func (g *Game) tooFewAgents() bool {
+	if float64(len(g.agentMap)) < math.Ceil(float64(g.gameConfig.ThresholdPercentage)*float64(g.gameConfig.InitialNumAgents)) {
+		logging.Log(logging.Info, nil, fmt.Sprintf("Lost on level %d  with %d remaining", g.state.CurrentLevel, len(g.agentMap)))
+		return true
+	}
+	return false
+}
+
+// stalemate applies the consequence of failing or retreating from a level,
+// returning the HP or HP pool it cost.
+func (g *Game) stalemate() uint {
+	lost := uint(0)
+	switch g.gameConfig.Stalemate {
+	case config.FailLevel:
+		ids := maps.Keys(g.agentMap)
+		sort.Strings(ids)
+		for _, id := range ids {
+			penalty := uint(math.Ceil(float64(g.state.AgentState[id].Hp) * float64(g.gameConfig.StalematePenalty)))
+			fight.DealDamage(fight.EvenSplit{}, penalty, []commons.ID{id}, nil, g.agentMap, g.state)
+			lost += penalty
+		}
+	case config.Retreat:
+		lost = g.state.HpPool
+		g.state.HpPool = 0
+	}
+	g.updateView()
+	return lost
+}
+
+/*
+	Hp Pool Helpers
+*/
+
+func (g *Game) checkHpPool() bool {
+	if g.state.HpPool >= g.state.MonsterHealth && !g.spendHpPool() {
+		logging.Log(logging.Info, logging.LogField{"treasurer": g.holder(decision.Treasurer)}, "Treasurer kept the HP Pool")
+		return false
+	}
+	if g.state.HpPool >= g.state.MonsterHealth {
+		logging.Log(logging.Info, logging.LogField{
+			"Original HP Pool":  g.state.HpPool,
+			"Monster Health":    g.state.MonsterHealth,
+			"HP Pool Remaining": g.state.HpPool - g.state.MonsterHealth,
+		}, fmt.Sprintf("Skipping level %d through HP Pool", g.state.CurrentLevel))
+
+		g.state.HpPool -= g.state.MonsterHealth
+		g.state.MonsterHealth = 0
+		return true
+	}
+	return false
+}
+
+// spendHpPool reports whether the treasurer, if there is a cabinet, agrees to
+// spend the HP pool on the monster.
+func (g *Game) spendHpPool() bool {
+	if g.gameConfig.Cabinet == "" || g.gameConfig.Cabinet == config.NoCabinet {
+		return true
+	}
+	treasurer, alive := g.agentMap[g.holder(decision.Treasurer)]
+	return !alive || treasurer.HandleSpendHpPool(g.state.AgentState[treasurer.ID()])
+}
+
+/*
+	Loot Helpers
+*/
+
+func statDelta(r *rand.Rand) float64 {
+	min := 0.8
+	max := 1.2
+	return min + r.Float64()*(max-min)
+}
+
+// shareLoot shares pool out among the agents the leader has not sanctioned, in
+// the order and within the limit its manifesto commits to.
+func (g *Game) shareLoot(pool *state.LootPool) {
+	policies := g.policies()
+	prunedAgentMap := stages.AgentPruneMapping(g.agentMap, g.holder(decision.Quartermaster))
+
+	var looters []agent.Agent
+	switch policies.LootAllocation {
+	case decision.RoundRobin, decision.NeedsFirst, decision.Lottery:
+		r := g.source.Stream("loot-allocation", g.state.CurrentLevel)
+		looters = loot.OrderLooters(policies.LootAllocation, prunedAgentMap, g.state.AgentState, r)
+	default:
+		looters = stages.AgentMapToSortedArray(prunedAgentMap, g.holder(decision.Quartermaster))
+	}
+
+	var limits map[commons.ID]uint
+	if share := policies.MaxLeaderLootShare; share > 0 && share < 100 {
+		items := pool.Weapons().Len() + pool.Shields().Len() + pool.HpPotions().Len() + pool.StaminaPotions().Len()
+		limits = map[commons.ID]uint{g.state.CurrentLeader: uint(items) * share / 100}
+	}
+	g.state = loot.HandleLootAllocationExhaustive(*g.state, pool, looters, limits)
+}
+
+// policies are what the living leader's manifesto commits it to.
+func (g *Game) policies() decision.Policies {
+	if _, alive := g.agentMap[g.state.CurrentLeader]; !alive {
+		return decision.Policies{}
+	}
+	return g.state.LeaderManifesto.Policies()
+}
+
+// rules are the game's settings as they stand.
+func (g *Game) rules() config.File {
+	return config.File{Game: g.gameConfig, Sanctions: g.cfg.Sanctions}
+}
+
+// supermajority is the fraction of the votes cast that must be for an
+// amendment for it to pass.
+func (g *Game) supermajority() float32 {
+	if g.gameConfig.Supermajority == 0 {
+		return 2.0 / 3
+	}
+	return g.gameConfig.Supermajority
+}
+
+// amend plays the rest of the game by the amended settings.
+func (g *Game) amend(amended config.File) {
+	g.gameConfig, g.cfg.Sanctions = amended.Game, amended.Sanctions
+	g.state.Defection = g.gameConfig.Defection
+	g.state.SanctionConfig = g.sanctionConfig()
+}
+
+// sanctionConfig is the game's sanction settings, with the sanction regime
+// the leader's manifesto commits to.
+func (g *Game) sanctionConfig() cmdline.CmdLine {
+	sanctions := g.cfg.Sanctions
+	switch g.state.LeaderManifesto.Policies().Sanctions {
+	case decision.FixedSanctions:
+		sanctions.DynamicSanctions, sanctions.GraduatedSanctions = false, false
+	case decision.GraduatedSanctions:
+		// Graduated sanctions need a maximum duration to escalate towards.
+		if sanctions.MaxGraduatedSanctionDuration > 0 {
+			sanctions.DynamicSanctions, sanctions.GraduatedSanctions = false, true
+		}
+	case decision.DynamicSanctions:
+		sanctions.DynamicSanctions, sanctions.GraduatedSanctions = true, false
+	}
+	return sanctions
+}
+
+// corpseLoot clears the corpses away, returning their inventory and the
+// stamina potions they drop as loot.
+func (g *Game) corpseLoot() []state.Item {
+	r := g.source.Stream("corpse", g.state.CurrentLevel)
+	var items []state.Item
+	for _, corpse := range g.state.Corpses {
+		items = append(items, corpse.Weapons...)
+		items = append(items, corpse.Shields...)
+		if stamina := uint(float64(corpse.Stamina) * float64(g.gameConfig.DeathDropStamina)); stamina > 0 {
+			items = append(items, *state.NewItem(rng.NewID(r), stamina, state.STAMINA_POTION))
+		}
+	}
+	g.state.Corpses = nil
+	return items
+}
+
+func (g *Game) generateLootPool(numAgents uint) *state.LootPool {
+	r := g.source.Stream("loot", g.state.CurrentLevel)
+	nWeapons, nShields := gamemath.GetEquipmentDistribution(r, g.gameConfig.EquipmentScarcity, numAgents)
+	nHealthPotions, nStaminaPotions := gamemath.GetPotionDistribution(r, g.gameConfig.PotionScarcity, numAgents)
+
+	makeItems := func(nItems uint, stats uint, itemType state.ItemName) *commons.ImmutableList[state.Item] {
+		items := make([]state.Item, nItems)
+		for i := uint(0); i < nItems; i++ {
+			delta := statDelta(r)
+			items[i] = *state.NewItem(rng.NewID(r), uint(float64(stats)*delta), itemType)
+		}
+		sort.SliceStable(items, func(i, j int) bool {
+			return items[i].Value() > items[j].Value()
+		})
+		return commons.NewImmutableList(items)
+	}
+
+	recalculatedMonsterHealth := gamemath.CalculateMonsterHealth(r, g.gameConfig.InitialNumAgents, g.gameConfig.Stamina, g.gameConfig.NumLevels, g.state.CurrentLevel)
+
+	return state.NewLootPool(
+		// Weapons
+		makeItems(nWeapons, gamemath.GetWeaponDamage(r, recalculatedMonsterHealth, numAgents), state.SWORD),
+		// Shields
+		makeItems(nShields, gamemath.GetShieldProtection(r, g.state.MonsterAttack, numAgents), state.SHIELD),
+		// Health Potions
+		makeItems(nHealthPotions, gamemath.GetHealthPotionValue(r, g.state.MonsterAttack, numAgents), state.HP_POTION),
+		// Stamina Potions
+		makeItems(nStaminaPotions, gamemath.GetStaminaPotionValue(r, recalculatedMonsterHealth, numAgents), state.STAMINA_POTION),
+	)
+}
+
+/*
+	CSV Helpers
+*/
+
+func uintStr(in uint) string {
+	return strconv.Itoa(int(in))
+}
+
+func (g *Game) initCsvLogging() {
+	g.csvBuf = &bytes.Buffer{}
+	g.csv = csv.NewWriter(g.csvBuf)
+	// title row
+	firstRow := []string{"level", "total agents alive", "average health", "average stamina", "average attack", "average defense", "average personality", "average sanctioned", "count selfless", "count selfish", "count collective"}
+	_ = g.csv.Write(firstRow)
+}
+
+func (g *Game) logLevel(levelLog logging.LevelStages) {
+	// quantize personalities to count them
+	countSelfless := 0
+	countSelfish := 0
+	countCollective := 0
+	avPersonality := 0
+	avSanctioned := 0
+	for _, a := range g.agentMap {
+		personality, sanctioned := a.GetStats()
+		avPersonality += personality
+		avSanctioned += sanctioned
+		if personality <= 25 {
+			countSelfish += 1
+		} else if personality >= 75 {
+			countSelfless += 1
+		} else {
+			countCollective += 1
+		}
+	}
+	countAgentint := len(g.agentMap)
+	if countAgentint > 0 {
+		avPersonality /= countAgentint
+		avSanctioned /= countAgentint
+	}
+
+	lvStats := levelLog.LevelStats
+	row := []string{uintStr(lvStats.CurrentLevel),
+		uintStr(lvStats.NumberOfAgents),
+		uintStr(lvStats.AverageAgentHealth),
+		uintStr(lvStats.AverageAgentStamina),
+		uintStr(lvStats.AverageAgentAttack),
+		uintStr(lvStats.AverageAgentShield),
+		strconv.Itoa(avPersonality),
+		strconv.Itoa(avSanctioned),
+		strconv.Itoa(countSelfless),
+		strconv.Itoa(countSelfish),
+		strconv.Itoa(countCollective),
+	}
+	// writes to a bytes.Buffer cannot fail, errors are surfaced by csv.Error on flush
+	_ = g.csv.Write(row)
+}
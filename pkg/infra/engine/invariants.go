@@ -0,0 +1,221 @@
+package engine
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"infra/game/commons"
+	"infra/game/state"
+
+	"github.com/benbjohnson/immutable"
+	"github.com/google/uuid"
+	"golang.org/x/exp/maps"
+)
+
+// defectingStages are the stages that may change an agent's defector flags.
+var defectingStages = map[string]bool{"fight": true, "loot": true}
+
+// InvariantError is returned by Step in strict mode (see Config.Strict) when a
+// stage leaves the game state inconsistent.
+type InvariantError struct {
+	Level uint
+	Stage string
+	// Violations are the invariants broken after the stage.
+	Violations []string
+	// Changes are what the stage changed, for each agent it touched.
+	Changes []string
+}
+
+func (e *InvariantError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "engine: level %d, stage %s broke invariants: %s", e.Level, e.Stage, strings.Join(e.Violations, "; "))
+	if len(e.Changes) > 0 {
+		b.WriteString("\nchanges:\n\t")
+		b.WriteString(strings.Join(e.Changes, "\n\t"))
+	}
+	return b.String()
+}
+
+// snapshot is the part of the game state its invariants cover.
+type snapshot struct {
+	agents     map[commons.ID]bool
+	agentState map[commons.ID]state.AgentState
+	weapons    map[commons.ItemID]uint
+	shields    map[commons.ItemID]uint
+}
+
+func (g *Game) snapshot() snapshot {
+	s := snapshot{
+		agents:     make(map[commons.ID]bool, len(g.agentMap)),
+		agentState: maps.Clone(g.state.AgentState),
+		weapons:    maps.Clone(g.state.InventoryMap.Weapons),
+		shields:    maps.Clone(g.state.InventoryMap.Shields),
+	}
+	for id := range g.agentMap {
+		s.agents[id] = true
+	}
+	return s
+}
+
+// checkInvariants checks the state stage left behind, given the state before
+// it.
+func (g *Game) checkInvariants(stage string, before snapshot) error {
+	after := g.snapshot()
+	var violations []string
+	violate := func(format string, args ...any) {
+		violations = append(violations, fmt.Sprintf(format, args...))
+	}
+
+	weapons := make(map[commons.ItemID]uint)
+	shields := make(map[commons.ItemID]uint)
+	for _, id := range agentIDs(after) {
+		agentState, hasState := after.agentState[id]
+		switch {
+		case !after.agents[id]:
+			violate("agent %s has a state but no agent", id)
+		case !hasState:
+			violate("agent %s has no state", id)
+			continue
+		}
+
+		if agentState.Hp > math.MaxUint/2 {
+			violate("agent %s HP underflowed to %d", id, agentState.Hp)
+		}
+		if agentState.Stamina > math.MaxUint/2 {
+			violate("agent %s stamina underflowed to %d", id, agentState.Stamina)
+		}
+		if !owns(agentState.Weapons, agentState.WeaponInUse) {
+			violate("agent %s uses weapon %s it does not own", id, agentState.WeaponInUse)
+		}
+		if !owns(agentState.Shields, agentState.ShieldInUse) {
+			violate("agent %s uses shield %s it does not own", id, agentState.ShieldInUse)
+		}
+		if old, ok := before.agentState[id]; ok && old.Defector != agentState.Defector && !defectingStages[stage] {
+			violate("agent %s defector flags changed", id)
+		}
+		for _, item := range commons.ImmutableListToSlice(agentState.Weapons) {
+			weapons[item.Id()] = item.Value()
+		}
+		for _, item := range commons.ImmutableListToSlice(agentState.Shields) {
+			shields[item.Id()] = item.Value()
+		}
+	}
+	for _, diff := range diffItems(weapons, after.weapons) {
+		violate("inventory map weapon %s", diff)
+	}
+	for _, diff := range diffItems(shields, after.shields) {
+		violate("inventory map shield %s", diff)
+	}
+
+	if len(violations) == 0 {
+		return nil
+	}
+	return &InvariantError{
+		Level:      g.state.CurrentLevel,
+		Stage:      stage,
+		Violations: violations,
+		Changes:    diffSnapshots(before, after),
+	}
+}
+
+// owns reports whether items holds the item in use, or none is in use.
+func owns(items immutable.List[state.Item], inUse commons.ItemID) bool {
+	if inUse == uuid.Nil.String() || inUse == "" {
+		return true
+	}
+	for i := 0; i < items.Len(); i++ {
+		if items.Get(i).Id() == inUse {
+			return true
+		}
+	}
+	return false
+}
+
+// diffItems describes how the inventory map's items differ from those held.
+func diffItems(held map[commons.ItemID]uint, inventory map[commons.ItemID]uint) []string {
+	var diffs []string
+	for _, id := range sortedKeys(held, inventory) {
+		value, isHeld := held[id]
+		mapped, isMapped := inventory[id]
+		switch {
+		case !isMapped:
+			diffs = append(diffs, fmt.Sprintf("%s is held but not in the map", id))
+		case !isHeld:
+			diffs = append(diffs, fmt.Sprintf("%s is held by no agent", id))
+		case value != mapped:
+			diffs = append(diffs, fmt.Sprintf("%s is worth %d, held as %d", id, mapped, value))
+		}
+	}
+	return diffs
+}
+
+// diffSnapshots lists what changed between two snapshots, agent by agent.
+func diffSnapshots(before, after snapshot) []string {
+	var changes []string
+	for _, id := range agentIDs(before, after) {
+		old, wasState := before.agentState[id]
+		now, isState := after.agentState[id]
+		if before.agents[id] != after.agents[id] {
+			changes = append(changes, fmt.Sprintf("agent %s: in agent map %v -> %v", id, before.agents[id], after.agents[id]))
+		}
+		switch {
+		case wasState && !isState:
+			changes = append(changes, fmt.Sprintf("agent %s: state removed", id))
+		case !wasState && isState:
+			changes = append(changes, fmt.Sprintf("agent %s: state added", id))
+		case wasState && isState:
+			if diff := diffAgentState(old, now); diff != "" {
+				changes = append(changes, fmt.Sprintf("agent %s: %s", id, diff))
+			}
+		}
+	}
+	return changes
+}
+
+func diffAgentState(old, now state.AgentState) string {
+	var fields []string
+	field := func(name string, old, now any) {
+		if old != now {
+			fields = append(fields, fmt.Sprintf("%s %v -> %v", name, old, now))
+		}
+	}
+	field("Hp", old.Hp, now.Hp)
+	field("Stamina", old.Stamina, now.Stamina)
+	field("Attack", old.Attack, now.Attack)
+	field("Defense", old.Defense, now.Defense)
+	field("WeaponInUse", old.WeaponInUse, now.WeaponInUse)
+	field("ShieldInUse", old.ShieldInUse, now.ShieldInUse)
+	field("Weapons", old.Weapons.Len(), now.Weapons.Len())
+	field("Shields", old.Shields.Len(), now.Shields.Len())
+	field("Defector", old.Defector.IsDefector(), now.Defector.IsDefector())
+	return strings.Join(fields, ", ")
+}
+
+// agentIDs lists, in order, every agent in the snapshots.
+func agentIDs(snapshots ...snapshot) []commons.ID {
+	set := make(map[commons.ID]bool)
+	for _, s := range snapshots {
+		for id := range s.agents {
+			set[id] = true
+		}
+		for id := range s.agentState {
+			set[id] = true
+		}
+	}
+	ids := maps.Keys(set)
+	sort.Strings(ids)
+	return ids
+}
+
+func sortedKeys(a, b map[commons.ItemID]uint) []commons.ItemID {
+	keys := maps.Keys(a)
+	for id := range b {
+		if _, ok := a[id]; !ok {
+			keys = append(keys, id)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
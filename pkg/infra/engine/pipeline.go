@@ -0,0 +1,124 @@
+package engine
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"infra/game/agent"
+	"infra/game/commons"
+	"infra/game/decision"
+	"infra/game/state"
+	"infra/logging"
+)
+
+// Stage is a single step of a level, eg. an election or a fight.
+type Stage interface {
+	// Name identifies the stage in config.GameConfig.Stages and in errors.
+	Name() string
+	// Run plays the stage. The returned Entry, if any, is merged into the
+	// level's log.
+	Run(l *Level) (Entry, error)
+}
+
+// Entry is a stage's contribution to the level log, eg. logging.FightStage.
+type Entry interface {
+	Merge(l *logging.LevelStages)
+}
+
+// Entries merges several entries in order.
+type Entries []Entry
+
+func (e Entries) Merge(l *logging.LevelStages) {
+	for _, entry := range e {
+		entry.Merge(l)
+	}
+}
+
+// Pipeline is the sequence of stages played in every level.
+type Pipeline []Stage
+
+// DefaultStages is the order stages are played in when none is configured.
+var DefaultStages = []string{
+	"election", "confidence", "hp-pool-check", "items", "fight", "corpse-loot", "loot", "trade", "trust", "hp-pool-donation", "update",
+}
+
+var builtinStages = map[string]Stage{
+	"election":         Election{},
+	"confidence":       ConfidenceVote{},
+	"hp-pool-check":    HPPoolCheck{},
+	"items":            ItemUpdate{},
+	"fight":            Fight{},
+	"corpse-loot":      CorpseLoot{},
+	"loot":             Loot{},
+	"trade":            Trade{Rounds: 5, RoundLimit: 3},
+	"trust":            Trust{},
+	"hp-pool-donation": HPPoolDonation{},
+	"update":           InternalUpdate{},
+	"referendum":       Referendum{},
+}
+
+// ParsePipeline builds a pipeline from stage names. Stages may be repeated or
+// left out. No names gives the DefaultStages.
+func ParsePipeline(names []string) (Pipeline, error) {
+	if len(names) == 0 {
+		names = DefaultStages
+	}
+	pipeline := make(Pipeline, 0, len(names))
+	for _, name := range names {
+		stage, ok := builtinStages[name]
+		if !ok {
+			known := make([]string, 0, len(builtinStages))
+			for n := range builtinStages {
+				known = append(known, n)
+			}
+			sort.Strings(known)
+			return nil, fmt.Errorf("engine: unknown stage %q, want one of %s", name, strings.Join(known, ", "))
+		}
+		pipeline = append(pipeline, stage)
+	}
+	return pipeline, nil
+}
+
+// Level is what the stages of a level share: the game itself, and what earlier
+// stages found out that later ones need.
+type Level struct {
+	game         *Game
+	leaderBefore commons.ID
+	elected      bool
+	election     *decision.ElectionResult
+	votes        map[decision.Intent]uint
+	// confidenceVotes are how each agent voted in the level's no-confidence
+	// motion
+	confidenceVotes map[commons.ID]decision.Intent
+	fightResults    []decision.ImmutableFightResult
+	outcome         *logging.Outcome
+	// stalemate is set when the fight ended without killing the monster
+	stalemate bool
+}
+
+// State is the game state, which stages update in place.
+func (l *Level) State() *state.State {
+	return l.game.state
+}
+
+// Agents are the living agents, keyed by ID.
+func (l *Level) Agents() map[commons.ID]agent.Agent {
+	return l.game.agentMap
+}
+
+// ConfidenceVotes are how each agent voted in the level's no-confidence
+// motion, public or secret, or nil if none was held.
+func (l *Level) ConfidenceVotes() map[commons.ID]decision.Intent {
+	return l.confidenceVotes
+}
+
+// UpdateView shows agents the changes made to State.
+func (l *Level) UpdateView() {
+	l.game.updateView()
+}
+
+// End ends the game with outcome once the current stage returns.
+func (l *Level) End(outcome logging.Outcome) {
+	l.outcome = &outcome
+}
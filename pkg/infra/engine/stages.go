@@ -0,0 +1,378 @@
+package engine
+
+import (
+	"fmt"
+	"math"
+
+	"infra/config"
+	"infra/game/commons"
+	"infra/game/decision"
+	"infra/game/stage/discussion"
+	"infra/game/stage/fight"
+	"infra/game/stage/hppool"
+	"infra/game/stage/loot"
+	"infra/game/stage/referendum"
+	"infra/game/stage/trade"
+	"infra/game/stages"
+	"infra/game/state"
+	"infra/logging"
+)
+
+// Election elects a new leader when the term is over or the leader has died.
+type Election struct{}
+
+func (Election) Name() string { return "election" }
+
+func (Election) Run(l *Level) (Entry, error) {
+	g := l.game
+	if _, alive := g.agentMap[g.state.CurrentLeader]; alive && g.termLeft > 0 {
+		return nil, nil
+	}
+	termLeft, result := g.runElection()
+	g.termLeft = termLeft
+	l.elected = true
+	l.election = result
+	logging.Log(logging.Info, g.electionFields(), "Re-Election Vote")
+	return g.electionLog(result), nil
+}
+
+// ConfidenceVote lets the agents overthrow a leader that was not elected this
+// level.
+type ConfidenceVote struct{}
+
+func (ConfidenceVote) Name() string { return "confidence" }
+
+func (ConfidenceVote) Run(l *Level) (Entry, error) {
+	g := l.game
+	if _, alive := g.agentMap[g.state.CurrentLeader]; !alive || l.elected {
+		return nil, nil
+	}
+	if g.confidenceCooldown > 0 {
+		g.confidenceCooldown--
+		return nil, nil
+	}
+
+	result := g.runConfidenceVote()
+	l.votes = result.Tally
+	l.confidenceVotes = result.Votes
+	entry := logging.VONCStage{
+		Occurred:  true,
+		Threshold: g.state.LeaderManifesto.OverthrowThreshold(),
+		For:       result.Tally[decision.Positive],
+		Against:   result.Tally[decision.Negative],
+		Abstain:   result.Tally[decision.Abstain],
+		Quorate:   result.Quorate,
+		Passed:    result.Passed,
+	}
+	if result.Weights != nil {
+		entry.Weights, entry.WeightFor, entry.WeightAgainst = result.Weights, result.WeightFor, result.WeightAgainst
+	}
+	if g.gameConfig.PublicConfidenceVotes {
+		entry.Votes = make(map[commons.ID]string, len(result.Votes))
+		for id, intent := range result.Votes {
+			entry.Votes[id] = intentNames[intent]
+		}
+	}
+
+	if result.Passed {
+		logging.Log(logging.Info, nil, fmt.Sprintf("%s got ousted", g.state.CurrentLeader))
+		g.termLeft, l.election = g.runElection()
+		l.elected = true
+		logging.Log(logging.Info, g.electionFields(), "Re-Election Vote")
+		return Entries{entry, g.electionLog(l.election)}, nil
+	}
+	g.confidenceCooldown = g.gameConfig.ConfidenceCooldown
+	entry.Cabinet = g.runCabinetVotes()
+	return entry, nil
+}
+
+// intentNames are how confidence votes are logged.
+var intentNames = map[decision.Intent]string{
+	decision.Positive: "for",
+	decision.Negative: "against",
+	decision.Abstain:  "abstain",
+}
+
+// HPPoolCheck kills the monster outright if the HP pool can cover its health.
+type HPPoolCheck struct{}
+
+func (HPPoolCheck) Name() string { return "hp-pool-check" }
+
+func (HPPoolCheck) Run(l *Level) (Entry, error) {
+	return logging.SkippedThroughHpPool(l.game.checkHpPool()), nil
+}
+
+// ItemUpdate lets agents change the weapon and shield in use.
+type ItemUpdate struct{}
+
+func (ItemUpdate) Name() string { return "items" }
+
+func (ItemUpdate) Run(l *Level) (Entry, error) {
+	g := l.game
+	g.state = loot.UpdateItems(*g.state, g.agentMap)
+	g.updateView()
+	return nil, nil
+}
+
+// Fight plays fight rounds until the monster is dead, ending the game if too
+// few agents survive. After config.GameConfig.MaxFightRounds rounds, the
+// level's stalemate consequence applies.
+type Fight struct{}
+
+func (Fight) Name() string { return "fight" }
+
+func (Fight) Run(l *Level) (Entry, error) {
+	g := l.game
+	var stats logging.LevelStats
+	fightLog := logging.FightStage{}
+	entry := func() Entry {
+		if !fightLog.Occurred {
+			return fightLog
+		}
+		return Entries{stats, fightLog}
+	}
+
+	var decisionMap map[commons.ID]decision.FightAction
+	policy := fight.NewDamagePolicy(g.gameConfig, g.source.Stream("damage", g.state.CurrentLevel))
+	roundNum := uint(0)
+	for g.state.MonsterHealth != 0 {
+		if maxRounds := g.gameConfig.MaxFightRounds; maxRounds > 0 && roundNum >= maxRounds {
+			if fightLog.Stalemate == "" {
+				fightLog.Stalemate = string(g.gameConfig.Stalemate)
+				logging.Log(logging.Info, logging.LogField{"currLevel": g.state.CurrentLevel, "rounds": roundNum, "stalemate": g.gameConfig.Stalemate}, "Fight rounds ran out")
+			}
+			if g.gameConfig.Stalemate != config.Enrage {
+				l.stalemate = true
+				fightLog.StalematePenalty = g.stalemate()
+				if g.tooFewAgents() {
+					l.End(logging.Loss)
+				}
+				return entry(), nil
+			}
+			g.state.MonsterAttack = uint(math.Ceil(float64(g.state.MonsterAttack) * (1 + float64(g.gameConfig.EnrageRate))))
+			g.updateView()
+		}
+		fightLog.Occurred = true
+		// find out the maximum attack from alive agents
+		maxAttack := uint(0)
+		for _, agentState := range g.state.AgentState {
+			if agentState.Hp > 0 {
+				maxAttack += agentState.TotalAttack()
+			}
+		}
+		// calculate average stats
+		avgHP, avgAT, avgSH, avgST := uint(0), uint(0), uint(0), uint(0)
+		for _, a := range g.agentMap {
+			state := a.AgentState()
+			avgHP += a.AgentState().Hp
+			avgAT += state.TotalAttack()
+			avgSH += state.TotalDefense()
+			avgST += a.AgentState().Stamina
+		}
+		agents := uint(len(g.agentMap))
+		avgHP, avgAT, avgSH, avgST = avgHP/agents, avgAT/agents, avgSH/agents, avgST/agents
+
+		stats = logging.LevelStats{
+			NumberOfAgents:       uint(len(g.agentMap)),
+			CurrentLevel:         g.state.CurrentLevel,
+			LeaderBeforeElection: l.leaderBefore,
+			LeaderAfterElection:  g.state.CurrentLeader,
+			HPPool:               g.state.HpPool,
+			MonsterHealth:        g.state.MonsterHealth,
+			MonsterAttack:        g.state.MonsterAttack,
+			MonsterAbilities:     g.monsterAbilities(),
+			AverageAgentHealth:   avgHP,
+			AverageAgentAttack:   avgAT,
+			AverageAgentShield:   avgSH,
+			AverageAgentStamina:  avgST,
+		}
+		// end calc average stats
+
+		fightTally := stages.AgentFightDecisions(g.cfg.Mode, *g.state, g.agentMap, commons.MapToImmutable(decisionMap), g.rounds)
+		fightActions := discussion.ResolveFightDiscussion(*g.state, g.agentMap, g.agentMap[g.holder(decision.FightMarshal)], g.state.LeaderManifesto, fightTally)
+		g.state = fight.HandleFightRound(*g.state, g.gameConfig.Stamina, g.gameConfig.StartingHealthPoints, &fightActions)
+		g.updateView()
+
+		logging.Log(logging.Info, logging.LogField{
+			"currLevel":     g.state.CurrentLevel,
+			"monsterHealth": g.state.MonsterHealth,
+			"monsterDamage": g.state.MonsterAttack,
+			"abilities":     g.state.MonsterAbilities.Sorted(),
+			"numCoward":     len(fightActions.CoweringAgents),
+			"attackSum":     fightActions.AttackSum,
+			"shieldSum":     fightActions.ShieldSum,
+			"numAgents":     len(g.agentMap),
+			"maxAttack":     maxAttack,
+			"AvHP":          avgHP,
+			"AvST":          avgST,
+			"AvAttack":      avgAT,
+			"AvDefence":     avgSH,
+		}, "Battle Summary")
+		// NOTE: update the following function when you change AgentState
+		damage, abilities := g.damageCalculation(fightActions, policy)
+		fightLog.Rounds = append(fightLog.Rounds, logging.FightLog{
+			AttackingAgents: fightActions.AttackingAgents,
+			CoweringAgents:  fightActions.CoweringAgents,
+			ShieldingAgents: fightActions.ShieldingAgents,
+			AttackSum:       fightActions.AttackSum,
+			ShieldSum:       fightActions.ShieldSum,
+			AgentsRemaining: uint(len(g.agentMap)),
+			DamageTaken:     damage,
+			Abilities:       abilities,
+		})
+
+		g.addComms()
+
+		if g.tooFewAgents() {
+			l.End(logging.Loss)
+			return entry(), nil
+		}
+		l.fightResults = append(l.fightResults, *decision.NewImmutableFightResult(fightActions, roundNum))
+		roundNum++
+	}
+	return entry(), nil
+}
+
+// Loot generates the level's loot and shares it out among the agents the
+// leader has not sanctioned. A level failed or retreated from has no loot.
+type Loot struct{}
+
+func (Loot) Name() string { return "loot" }
+
+func (Loot) Run(l *Level) (Entry, error) {
+	g := l.game
+	if l.stalemate {
+		return logging.LootStage{}, nil
+	}
+	lootPool := g.generateLootPool(uint(g.initialAgents))
+	if g.gameConfig.DeathDrop == config.NextLoot {
+		lootPool = lootPool.With(g.corpseLoot())
+	}
+	g.shareLoot(lootPool)
+	return logging.LootStage{Occurred: true}, nil
+}
+
+// CorpseLoot shares out what the agents killed in the fight dropped, when
+// config.GameConfig.DeathDrop is config.CorpseLoot.
+type CorpseLoot struct{}
+
+func (CorpseLoot) Name() string { return "corpse-loot" }
+
+func (CorpseLoot) Run(l *Level) (Entry, error) {
+	g := l.game
+	if g.gameConfig.DeathDrop != config.CorpseLoot || len(g.state.Corpses) == 0 {
+		return logging.CorpseLootStage{}, nil
+	}
+	corpses := uint(len(g.state.Corpses))
+	items := g.corpseLoot()
+	g.shareLoot(state.LootPool{}.With(items))
+	return logging.CorpseLootStage{Occurred: true, Corpses: corpses, Items: uint(len(items))}, nil
+}
+
+// Trade lets agents swap items over a number of negotiation rounds.
+type Trade struct {
+	Rounds uint
+	// RoundLimit is how many rounds a negotiation stays open.
+	RoundLimit uint
+}
+
+func (Trade) Name() string { return "trade" }
+
+func (t Trade) Run(l *Level) (Entry, error) {
+	g := l.game
+	trade.HandleTrade(*g.state, g.agentMap, t.Rounds, t.RoundLimit, g.rounds.Deadline, g.source.Stream("trade", g.state.CurrentLevel))
+	return nil, nil
+}
+
+// Trust has the agents gossip about each other's reputations.
+type Trust struct{}
+
+func (Trust) Name() string { return "trust" }
+
+func (Trust) Run(l *Level) (Entry, error) {
+	g := l.game
+	g.addComms()
+	g.state.Reputation = stages.HandleTrustStage(g.agentMap, g.rounds)
+	return nil, nil
+}
+
+// HPPoolDonation has the agents donate to the HP pool.
+type HPPoolDonation struct{}
+
+func (HPPoolDonation) Name() string { return "hp-pool-donation" }
+
+func (HPPoolDonation) Run(l *Level) (Entry, error) {
+	g := l.game
+	entry := logging.HPPoolStage{Occurred: true, OldHPPool: g.state.HpPool}
+	var donations map[commons.ID]uint
+	donations, entry.Taxed = hppool.UpdateHpPool(g.agentMap, g.state, g.policies().HPPoolTax)
+	if g.state.Contributions == nil {
+		g.state.Contributions = make(map[commons.ID]uint, len(donations))
+	}
+	for id, donation := range donations {
+		g.state.Contributions[id] += donation
+	}
+	entry.NewHPPool = g.state.HpPool
+	entry.DonatedThisRound = entry.NewHPPool - entry.OldHPPool
+	return entry, nil
+}
+
+// Referendum puts the amendments the agents propose to the game's
+// institutional rules to a vote. Those passed take effect from the next
+// level.
+type Referendum struct{}
+
+func (Referendum) Name() string { return "referendum" }
+
+func (Referendum) Run(l *Level) (Entry, error) {
+	g := l.game
+	rules := g.rules()
+	if g.amended != nil {
+		rules = *g.amended
+	}
+	for _, p := range referendum.Proposals(g.state, g.agentMap) {
+		amendment := logging.Amendment{
+			Level:    g.state.CurrentLevel,
+			Proposer: p.Proposer,
+			Setting:  p.Amendment.Setting,
+			Value:    p.Amendment.Value,
+		}
+		amended, err := rules.Amend(p.Amendment.Setting, p.Amendment.Value)
+		if err != nil {
+			amendment.Rejected = err.Error()
+			g.log.Amendments = append(g.log.Amendments, amendment)
+			continue
+		}
+		result := referendum.HandleReferendum(g.state, g.agentMap, p, g.supermajority())
+		amendment.For, amendment.Against, amendment.Abstain = result.Tally[decision.Positive], result.Tally[decision.Negative], result.Tally[decision.Abstain]
+		amendment.Passed = result.Passed
+		g.log.Amendments = append(g.log.Amendments, amendment)
+		logging.Log(logging.Info, logging.LogField{
+			"proposer": p.Proposer,
+			"setting":  p.Amendment.Setting,
+			"value":    p.Amendment.Value,
+			"for":      amendment.For,
+			"against":  amendment.Against,
+			"passed":   amendment.Passed,
+		}, "Referendum")
+		if result.Passed {
+			rules = amended
+			g.amended = &rules
+		}
+	}
+	return nil, nil
+}
+
+// InternalUpdate tells the agents how the level's fights, votes and election
+// went.
+type InternalUpdate struct{}
+
+func (InternalUpdate) Name() string { return "update" }
+
+func (InternalUpdate) Run(l *Level) (Entry, error) {
+	g := l.game
+	g.updateView()
+	immutableFightRounds := commons.NewImmutableList(l.fightResults)
+	votesResult := commons.MapToImmutable(l.votes)
+	return logging.AgentLogs(stages.UpdateInternalStates(g.cfg.Mode, g.agentMap, g.state, immutableFightRounds, &votesResult, l.election)), nil
+}
@@ -7,6 +7,7 @@ import (
 	"infra/game/message"
 	"infra/game/state"
 	"infra/logging"
+	"math/rand"
 
 	"github.com/benbjohnson/immutable"
 )
@@ -22,10 +23,10 @@ func (a *Agent) HandleDonateToHpPool(agentState state.AgentState) uint {
 	return a.Strategy.DonateToHpPool(*a.BaseAgent)
 }
 
-func (a *Agent) HandleUpdateInternalState(agentState state.AgentState, fightResults *commons.ImmutableList[decision.ImmutableFightResult], voteResults *immutable.Map[decision.Intent, uint], logChan chan<- logging.AgentLog) {
+func (a *Agent) HandleUpdateInternalState(agentState state.AgentState, fightResults *commons.ImmutableList[decision.ImmutableFightResult], voteResults *immutable.Map[decision.Intent, uint], electionResult *decision.ElectionResult, logChan chan<- logging.AgentLog) {
 	a.BaseAgent.latestState = agentState
 
-	a.Strategy.UpdateInternalState(*a.BaseAgent, fightResults, voteResults, logChan)
+	a.Strategy.UpdateInternalState(*a.BaseAgent, fightResults, voteResults, electionResult, logChan)
 }
 
 func (a *Agent) HandleUpdateWeapon(agentState state.AgentState) decision.ItemIdx {
@@ -58,20 +59,118 @@ func (a *Agent) HandleElection(agentState state.AgentState, params *decision.Ele
 	return a.Strategy.HandleElectionBallot(*a.BaseAgent, params)
 }
 
+// HandleRunoff asks the agent for its ballot in the runoff of a two-round
+// election.
+func (a *Agent) HandleRunoff(agentState state.AgentState, params *decision.ElectionParams, firstBallot decision.Ballot) decision.Ballot {
+	a.BaseAgent.latestState = agentState
+
+	return a.Strategy.HandleRunoffBallot(*a.BaseAgent, params, firstBallot)
+}
+
+// ReviseManifesto gives the agent, having made the runoff of a two-round
+// election, the chance to revise its manifesto. A revision with no term is
+// ignored.
+func (a *Agent) ReviseManifesto(agentState state.AgentState, manifesto decision.Manifesto, params *decision.ElectionParams) decision.Manifesto {
+	a.BaseAgent.latestState = agentState
+
+	if revised := RevisedManifesto(a.Strategy, *a.BaseAgent, manifesto, params); revised != nil && revised.TermLength() > 0 {
+		return *revised
+	}
+	return manifesto
+}
+
+// HandleVoteCredits asks the agent how many of its remaining vote credits to
+// spend on the next vote, which is never more than it has.
+func (a *Agent) HandleVoteCredits(agentState state.AgentState, remaining uint) uint {
+	a.BaseAgent.latestState = agentState
+
+	if credits := VoteCredits(a.Strategy, *a.BaseAgent, remaining); credits < remaining {
+		return credits
+	}
+	return remaining
+}
+
+// HandleAppointCabinet asks the agent, as leader, who to appoint to each of
+// roles.
+func (a *Agent) HandleAppointCabinet(agentState state.AgentState, roles []decision.Role) map[decision.Role]commons.ID {
+	a.BaseAgent.latestState = agentState
+
+	return AppointCabinet(a.Strategy, *a.BaseAgent, roles)
+}
+
+// HandleCabinetConfidenceVote asks the agent for its vote in a no-confidence
+// motion on role's holder.
+func (a *Agent) HandleCabinetConfidenceVote(agentState state.AgentState, role decision.Role, holder commons.ID) decision.Intent {
+	a.BaseAgent.latestState = agentState
+
+	return CabinetConfidencePoll(a.Strategy, *a.BaseAgent, role, holder)
+}
+
+// HandleSpendHpPool asks the agent, as treasurer, whether to spend the HP
+// pool on the monster.
+func (a *Agent) HandleSpendHpPool(agentState state.AgentState) bool {
+	a.BaseAgent.latestState = agentState
+
+	return SpendHpPool(a.Strategy, *a.BaseAgent)
+}
+
+// HandleProposeAmendment asks the agent for the amendment to the game's
+// institutional rules it proposes, if any.
+func (a *Agent) HandleProposeAmendment(agentState state.AgentState) *decision.Amendment {
+	a.BaseAgent.latestState = agentState
+
+	return ProposeAmendment(a.Strategy, *a.BaseAgent)
+}
+
+// HandleReferendum asks the agent for its vote on proposer's amendment.
+func (a *Agent) HandleReferendum(agentState state.AgentState, proposer commons.ID, amendment decision.Amendment) decision.Intent {
+	a.BaseAgent.latestState = agentState
+
+	return ReferendumBallot(a.Strategy, *a.BaseAgent, proposer, amendment)
+}
+
+// HandleScoredElection asks the agent for its ballot in an approval or score
+// voting election.
+func (a *Agent) HandleScoredElection(agentState state.AgentState, params *decision.ElectionParams) decision.Scores {
+	a.BaseAgent.latestState = agentState
+
+	return ScoredBallot(a.Strategy, *a.BaseAgent, params)
+}
+
+// HandleCampaign handles the messages delivered to the agent in one round of
+// the campaign before an election between the candidates in params.
+func (a *Agent) HandleCampaign(agentState state.AgentState, params *decision.ElectionParams, inbox []message.TaggedMessage) {
+	a.BaseAgent.latestState = agentState
+	for _, m := range inbox {
+		switch r := m.Message().(type) {
+		case message.CampaignRequest:
+			req := *message.NewTaggedRequestMessage(m.Sender(), r, m.MID())
+			resp := a.Strategy.HandleCampaignRequest(req, *a.BaseAgent, params)
+			if resp == nil {
+				continue
+			}
+			if err := a.BaseAgent.SendBlockingMessage(m.Sender(), resp); err != nil {
+				logging.Log(logging.Error, nil, err.Error())
+			}
+		case message.CampaignInform:
+			inf := *message.NewTaggedInformMessage(m.Sender(), r, m.MID())
+			a.Strategy.HandleCampaignInformation(inf, *a.BaseAgent, params)
+		default:
+			logging.Log(logging.Warn, nil, fmt.Sprintf("Unknown type, %T", r))
+		}
+	}
+}
+
+// HandleFight handles the messages delivered to the agent in one round of the
+// fight discussion, recording what it submits and votes for in ballot.
 func (a *Agent) HandleFight(agentState state.AgentState,
 	log immutable.Map[commons.ID, decision.FightAction],
-	votes chan commons.ProposalID,
-	submission chan message.Proposal[decision.FightAction],
-	closure <-chan struct{},
+	inbox []message.TaggedMessage,
+	ballot *Ballot[decision.FightAction],
 ) {
 	a.BaseAgent.latestState = agentState
-	for {
-		select {
-		case taggedMessage := <-a.BaseAgent.communication.receipt:
-			a.handleFightRoundMessage(&log, taggedMessage, votes, submission)
-		case <-closure:
-			return
-		}
+	for _, taggedMessage := range inbox {
+		a.handleFightRoundMessage(&log, taggedMessage, ballot)
 	}
 }
 
@@ -83,17 +182,22 @@ func (a *Agent) SetCommunication(communication *Communication) {
 	a.BaseAgent.setCommunication(communication)
 }
 
+// SetRand replaces the agent's random stream.
+func (a *Agent) SetRand(r *rand.Rand) {
+	a.BaseAgent.rand = r
+}
+
 func (a *Agent) handleFightRoundMessage(log *immutable.Map[commons.ID, decision.FightAction],
 	m message.TaggedMessage,
-	votes chan commons.ProposalID,
-	submission chan message.Proposal[decision.FightAction],
+	ballot *Ballot[decision.FightAction],
 ) {
 	switch r := m.Message().(type) {
 	case message.FightRequest:
 		req := *message.NewTaggedRequestMessage(m.Sender(), r, m.MID())
 		resp := a.Strategy.HandleFightRequest(req, log)
-		err := a.BaseAgent.SendBlockingMessage(m.Sender(), resp)
-		logging.Log(logging.Error, nil, err.Error())
+		if err := a.BaseAgent.SendBlockingMessage(m.Sender(), resp); err != nil {
+			logging.Log(logging.Error, nil, err.Error())
+		}
 	case message.FightInform:
 		inf := *message.NewTaggedInformMessage(m.Sender(), r, m.MID())
 		a.Strategy.HandleFightInformation(inf, *a.BaseAgent, log)
@@ -101,17 +205,13 @@ func (a *Agent) handleFightRoundMessage(log *immutable.Map[commons.ID, decision.
 	case message.Proposal[decision.FightAction]:
 		if a.isLeader() {
 			if a.Strategy.HandleFightProposalRequest(r, *a.BaseAgent, log) {
-				submission <- r
-				iterator := a.BaseAgent.communication.peer.Iterator()
-				for !iterator.Done() {
-					_, value, _ := iterator.Next()
-					value <- m
-				}
+				ballot.Submissions = append(ballot.Submissions, r)
+				a.BaseAgent.communication.broadcast(m)
 			}
 		}
 		switch a.Strategy.HandleFightProposal(r, *a.BaseAgent) {
 		case decision.Positive:
-			votes <- r.ProposalID()
+			ballot.Votes = append(ballot.Votes, r.ProposalID())
 		default:
 		}
 	default:
@@ -119,62 +219,49 @@ func (a *Agent) handleFightRoundMessage(log *immutable.Map[commons.ID, decision.
 	}
 }
 
-func (a *Agent) HandleLoot(agentState state.AgentState, votes chan commons.ProposalID, submission chan message.Proposal[decision.LootAction], closure chan struct{}, start <-chan message.StartLoot) {
+// HandleLoot handles the messages delivered to the agent in one round of the
+// loot discussion, recording what it submits and votes for in ballot.
+func (a *Agent) HandleLoot(agentState state.AgentState, inbox []message.TaggedMessage, ballot *Ballot[decision.LootAction]) {
 	a.BaseAgent.latestState = agentState
-	for {
-		select {
-		case loot := <-start:
-			a.addLoot(loot.LootPool)
-			a.Strategy.RequestLootProposal(*a.BaseAgent) // this key function initiates the round. Previously, nothing was threaded to the channel
-		case taggedMessage := <-a.BaseAgent.communication.receipt:
-			a.handleLootRoundMessage(taggedMessage, votes, submission)
-		case <-closure:
-			return
-		}
+	for _, taggedMessage := range inbox {
+		a.handleLootRoundMessage(taggedMessage, ballot)
 	}
 }
 
-func (a *Agent) HandleTrust(
-	closures chan struct{},
-) {
-	for {
-		select {
-		case taggedMessage := <-a.BaseAgent.communication.receipt:
-			a.Strategy.HandleTrustMessage(taggedMessage)
-		case <-closures:
-			return
-		}
+// HandleTrust handles the trust messages delivered to the agent in one round.
+func (a *Agent) HandleTrust(inbox []message.TaggedMessage) {
+	for _, taggedMessage := range inbox {
+		a.Strategy.HandleTrustMessage(taggedMessage)
 	}
 }
 
 func (a *Agent) handleLootRoundMessage(
 	m message.TaggedMessage,
-	votes chan commons.ProposalID,
-	submission chan message.Proposal[decision.LootAction],
+	ballot *Ballot[decision.LootAction],
 ) {
 	switch r := m.Message().(type) {
+	case message.StartLoot:
+		a.addLoot(r.LootPool)
+		a.Strategy.RequestLootProposal(*a.BaseAgent) // this key function initiates the round
 	case message.LootRequest:
 		req := *message.NewTaggedRequestMessage(m.Sender(), r, m.MID())
 		resp := a.Strategy.HandleLootRequest(req)
-		err := a.BaseAgent.SendBlockingMessage(m.Sender(), resp)
-		logging.Log(logging.Error, nil, err.Error())
+		if err := a.BaseAgent.SendBlockingMessage(m.Sender(), resp); err != nil {
+			logging.Log(logging.Error, nil, err.Error())
+		}
 	case message.LootInform:
 		inf := *message.NewTaggedInformMessage(m.Sender(), r, m.MID())
 		a.Strategy.HandleLootInformation(inf, *a.BaseAgent)
 	case message.Proposal[decision.LootAction]:
 		if a.isLeader() {
 			if a.Strategy.HandleLootProposalRequest(r, *a.BaseAgent) {
-				submission <- r
-				iterator := a.BaseAgent.communication.peer.Iterator()
-				for !iterator.Done() {
-					_, value, _ := iterator.Next()
-					value <- m
-				}
+				ballot.Submissions = append(ballot.Submissions, r)
+				a.BaseAgent.communication.broadcast(m)
 			}
 		}
 		switch a.Strategy.HandleLootProposal(r, *a.BaseAgent) {
 		case decision.Positive:
-			votes <- r.ProposalID()
+			ballot.Votes = append(ballot.Votes, r.ProposalID())
 		default:
 		}
 	default:
@@ -5,6 +5,9 @@ import (
 	"fmt"
 	"infra/game/decision"
 	"infra/game/message/proposal"
+	"infra/game/rng"
+	"math/rand"
+	"sort"
 
 	// "reflect"
 
@@ -29,6 +32,7 @@ type BaseAgent struct {
 	latestState   state.AgentState
 	view          *state.View
 	loot          state.LootPool
+	rand          *rand.Rand
 }
 
 func (ba *BaseAgent) Loot() state.LootPool {
@@ -51,20 +55,47 @@ func (ba *BaseAgent) Name() string {
 	return ba.name
 }
 
-func NewBaseAgent(communication *Communication, id commons.ID, agentName string, ptr *state.View) *BaseAgent {
-	return &BaseAgent{communication: communication, id: id, name: agentName, view: ptr}
+// Rand returns the agent's own random stream. Strategies should draw from it
+// instead of the global math/rand functions so that games can be replayed from a seed.
+func (ba *BaseAgent) Rand() *rand.Rand {
+	return ba.rand
 }
 
-func (ba *BaseAgent) BroadcastBlockingMessage(m message.Message) {
-	iterator := ba.communication.peer.Iterator()
-	tm := message.NewTaggedMessage(ba.id, m, uuid.New())
+func NewBaseAgent(communication *Communication, id commons.ID, agentName string, ptr *state.View, r *rand.Rand) *BaseAgent {
+	return &BaseAgent{communication: communication, id: id, name: agentName, view: ptr, rand: r}
+}
 
-	for !iterator.Done() {
-		_, c, ok := iterator.Next()
-		if ok {
-			c <- *tm
-		}
+// Sent is a message an agent has sent that is yet to be delivered.
+type Sent struct {
+	To      commons.ID
+	Message message.TaggedMessage
+}
+
+// Outbox returns the messages the agent has sent that are yet to be
+// delivered, oldest first, so that what the agent does can be recorded.
+func (ba *BaseAgent) Outbox() []Sent {
+	if ba.communication == nil {
+		return nil
+	}
+	sent := make([]Sent, 0, len(ba.communication.outbox))
+	for _, e := range ba.communication.outbox {
+		sent = append(sent, Sent{To: e.to, Message: e.m})
 	}
+	return sent
+}
+
+// Post queues a message as if the agent had just sent it, so that recorded
+// games can be replayed.
+func (ba *BaseAgent) Post(s Sent) {
+	ba.communication.send(s.To, s.Message)
+}
+
+func (ba *BaseAgent) newMessageID() uuid.UUID {
+	return uuid.MustParse(rng.NewID(ba.rand))
+}
+
+func (ba *BaseAgent) BroadcastBlockingMessage(m message.Message) {
+	ba.communication.broadcast(*message.NewTaggedMessage(ba.id, m, ba.newMessageID()))
 }
 
 func (ba *BaseAgent) SendBlockingMessage(id commons.ID, m message.Message) (e error) {
@@ -74,29 +105,27 @@ func (ba *BaseAgent) SendBlockingMessage(id commons.ID, m message.Message) (e er
 	case message.Proposal[decision.LootAction]:
 		return communicationError("Illegal attempt to send proposal - use SendLootProposalToLeader() instead")
 	default:
-		channel, ok := ba.communication.peer.Get(id)
-		if ok {
-			channel <- *message.NewTaggedMessage(ba.id, m, uuid.New())
-		} else {
+		if !ba.communication.isPeer(id) {
 			return communicationError(fmt.Sprintf("agent %s not available for messaging", id))
 		}
+		ba.communication.send(id, *message.NewTaggedMessage(ba.id, m, ba.newMessageID()))
 	}
 	return nil
 }
 
 func (ba *BaseAgent) SendFightProposalToLeader(rules commons.ImmutableList[proposal.Rule[decision.FightAction]]) error {
-	channel, ok := ba.communication.peer.Get(ba.view.CurrentLeader())
-	if ok {
-		channel <- *message.NewTaggedMessage(ba.id, *message.NewProposal(rules, ba.ID()), uuid.New())
+	leader := ba.view.CurrentLeader()
+	if ba.communication.isPeer(leader) {
+		ba.communication.send(leader, *message.NewTaggedMessage(ba.id, *message.NewProposal(rng.NewID(ba.rand), rules, ba.ID()), ba.newMessageID()))
 		return nil
 	}
 	return communicationError("Leader not available for messaging, dead or bad!")
 }
 
 func (ba *BaseAgent) SendLootProposalToLeader(rules commons.ImmutableList[proposal.Rule[decision.LootAction]]) error {
-	channel, ok := ba.communication.peer.Get(ba.view.CurrentLeader())
-	if ok {
-		channel <- *message.NewTaggedMessage(ba.id, *message.NewProposal(rules, ba.ID()), uuid.New())
+	leader := ba.view.CurrentLeader()
+	if ba.communication.isPeer(leader) {
+		ba.communication.send(leader, *message.NewTaggedMessage(ba.id, *message.NewProposal(rng.NewID(ba.rand), rules, ba.ID()), ba.newMessageID()))
 		return nil
 	}
 	return communicationError("Leader not available for messaging, dead or bad!")
@@ -128,6 +157,7 @@ func (a *Agent) CompileTrustMessage(agentMap map[commons.ID]Agent) message.Trust
 		keys[i] = k
 		i++
 	}
+	sort.Strings(keys)
 
 	// declare new trust message
 	trustMsg := new(message.Trust)
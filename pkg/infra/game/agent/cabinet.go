@@ -0,0 +1,55 @@
+package agent
+
+import (
+	"infra/game/commons"
+	"infra/game/decision"
+)
+
+// CabinetAppointer is implemented by strategies that, as leader, appoint
+// their cabinet. Leaders whose strategies do not keep every role themselves.
+type CabinetAppointer interface {
+	// AppointCabinet returns who the leader appoints to each of roles. A role
+	// left out, or given to an agent that is not alive, stays with the
+	// leader.
+	AppointCabinet(baseAgent BaseAgent, roles []decision.Role) map[decision.Role]commons.ID
+}
+
+// AppointCabinet asks strategy to appoint a holder to each of roles, keeping
+// every role with the leader if it does not appoint.
+func AppointCabinet(strategy Strategy, baseAgent BaseAgent, roles []decision.Role) map[decision.Role]commons.ID {
+	if c, ok := strategy.(CabinetAppointer); ok {
+		return c.AppointCabinet(baseAgent, roles)
+	}
+	return nil
+}
+
+// CabinetVoter is implemented by strategies that vote in no-confidence
+// motions on the holders of the cabinet's roles. Other strategies abstain.
+type CabinetVoter interface {
+	HandleCabinetConfidencePoll(baseAgent BaseAgent, role decision.Role, holder commons.ID) decision.Intent
+}
+
+// CabinetConfidencePoll asks strategy for its vote on role's holder,
+// abstaining if it does not vote on the cabinet.
+func CabinetConfidencePoll(strategy Strategy, baseAgent BaseAgent, role decision.Role, holder commons.ID) decision.Intent {
+	if c, ok := strategy.(CabinetVoter); ok {
+		return c.HandleCabinetConfidencePoll(baseAgent, role, holder)
+	}
+	return decision.Abstain
+}
+
+// Treasurer is implemented by strategies that, as treasurer, decide whether
+// to spend the HP pool on a monster whose health it covers. Other treasurers
+// always spend it.
+type Treasurer interface {
+	SpendHpPool(baseAgent BaseAgent) bool
+}
+
+// SpendHpPool asks strategy whether to spend the HP pool on the monster,
+// spending it if it does not decide.
+func SpendHpPool(strategy Strategy, baseAgent BaseAgent) bool {
+	if t, ok := strategy.(Treasurer); ok {
+		return t.SpendHpPool(baseAgent)
+	}
+	return true
+}
@@ -1,17 +1,57 @@
 package agent
 
 import (
+	"sort"
+
 	"infra/game/commons"
 	"infra/game/message"
-
-	"github.com/benbjohnson/immutable"
 )
 
+// Communication holds who an agent can message and the messages it has sent
+// in the current round. Messages are only delivered at the start of the next
+// round (see Rounds), so the order agents run in cannot change what they see.
 type Communication struct {
-	receipt <-chan message.TaggedMessage
-	peer    immutable.Map[commons.ID, chan<- message.TaggedMessage]
+	peers  map[commons.ID]struct{}
+	order  []commons.ID
+	outbox []envelope
+}
+
+type envelope struct {
+	to commons.ID
+	m  message.TaggedMessage
+}
+
+func NewCommunication(peers []commons.ID) *Communication {
+	c := &Communication{
+		peers: make(map[commons.ID]struct{}, len(peers)),
+		order: make([]commons.ID, len(peers)),
+	}
+	copy(c.order, peers)
+	sort.Strings(c.order)
+	for _, id := range peers {
+		c.peers[id] = struct{}{}
+	}
+	return c
+}
+
+func (c *Communication) isPeer(id commons.ID) bool {
+	_, ok := c.peers[id]
+	return ok
+}
+
+func (c *Communication) send(to commons.ID, m message.TaggedMessage) {
+	c.outbox = append(c.outbox, envelope{to: to, m: m})
+}
+
+func (c *Communication) broadcast(m message.TaggedMessage) {
+	for _, id := range c.order {
+		c.send(id, m)
+	}
 }
 
-func NewCommunication(receipt <-chan message.TaggedMessage, peer immutable.Map[commons.ID, chan<- message.TaggedMessage]) *Communication {
-	return &Communication{receipt: receipt, peer: peer}
+// drain returns the messages sent since the last call and empties the outbox.
+func (c *Communication) drain() []envelope {
+	sent := c.outbox
+	c.outbox = nil
+	return sent
 }
@@ -2,10 +2,85 @@ package agent
 
 import (
 	"infra/game/decision"
+	"infra/game/message"
 )
 
 type Election interface {
 	CreateManifesto(baseAgent BaseAgent) *decision.Manifesto
 	HandleConfidencePoll(baseAgent BaseAgent) decision.Intent
 	HandleElectionBallot(baseAgent BaseAgent, params *decision.ElectionParams) decision.Ballot
+	// HandleRunoffBallot returns the agent's ballot in the second round of a
+	// two-round election, between the two candidates in params, given its
+	// ballot in the first. DefaultRunoffBallot reuses the first ballot.
+	HandleRunoffBallot(baseAgent BaseAgent, params *decision.ElectionParams, firstBallot decision.Ballot) decision.Ballot
+	// HandleCampaignInformation handles the start of the campaign before an
+	// election between the candidates in params, and the promises and
+	// endorsements sent during it.
+	HandleCampaignInformation(m message.TaggedInformMessage[message.CampaignInform], baseAgent BaseAgent, params *decision.ElectionParams)
+	// HandleCampaignRequest answers a voter's question during the campaign,
+	// or returns nil not to.
+	HandleCampaignRequest(m message.TaggedRequestMessage[message.CampaignRequest], baseAgent BaseAgent, params *decision.ElectionParams) message.CampaignInform
+}
+
+// DefaultRunoffBallot ranks the candidates still in a runoff in the order
+// firstBallot did, leaving out the rest.
+func DefaultRunoffBallot(params *decision.ElectionParams, firstBallot decision.Ballot) decision.Ballot {
+	var ballot decision.Ballot
+	for _, candidate := range firstBallot {
+		if _, ok := params.CandidateList().Get(candidate); ok {
+			ballot = append(ballot, candidate)
+		}
+	}
+	return ballot
+}
+
+// ManifestoReviser is implemented by strategies that revise their manifesto
+// when they make the runoff of a two-round election.
+type ManifestoReviser interface {
+	// ReviseManifesto returns the manifesto to stand on in the runoff, given
+	// the one stood on in the first round and both runoff candidates' in
+	// params, or nil to keep it.
+	ReviseManifesto(baseAgent BaseAgent, manifesto decision.Manifesto, params *decision.ElectionParams) *decision.Manifesto
+}
+
+// RevisedManifesto asks strategy to revise its manifesto, returning nil if it
+// keeps it or does not revise manifestos.
+func RevisedManifesto(strategy Strategy, baseAgent BaseAgent, manifesto decision.Manifesto, params *decision.ElectionParams) *decision.Manifesto {
+	if r, ok := strategy.(ManifestoReviser); ok {
+		return r.ReviseManifesto(baseAgent, manifesto, params)
+	}
+	return nil
+}
+
+// ScoredElection is implemented by strategies that score candidates
+// themselves in approval and score voting elections. Other strategies have
+// their ranked ballot converted (see decision.Ballot.Scores).
+type ScoredElection interface {
+	HandleScoredBallot(baseAgent BaseAgent, params *decision.ElectionParams) decision.Scores
+}
+
+// ScoredBallot asks strategy for its scored ballot, converting its ranked
+// ballot if it does not score candidates itself.
+func ScoredBallot(strategy Strategy, baseAgent BaseAgent, params *decision.ElectionParams) decision.Scores {
+	if s, ok := strategy.(ScoredElection); ok {
+		return s.HandleScoredBallot(baseAgent, params)
+	}
+	return strategy.HandleElectionBallot(baseAgent, params).Scores(params.Strategy(), params.MaxScore())
+}
+
+// QuadraticVoter is implemented by strategies that choose how many vote
+// credits to spend in quadratic voting. Other strategies spend one a vote.
+type QuadraticVoter interface {
+	// VoteCredits returns how many of the agent's remaining credits for the
+	// term it spends on the next vote, which weighs their square root.
+	VoteCredits(baseAgent BaseAgent, remaining uint) uint
+}
+
+// VoteCredits asks strategy how many of its remaining credits to spend on
+// the next vote, spending one if it does not choose.
+func VoteCredits(strategy Strategy, baseAgent BaseAgent, remaining uint) uint {
+	if q, ok := strategy.(QuadraticVoter); ok {
+		return q.VoteCredits(baseAgent, remaining)
+	}
+	return 1
 }
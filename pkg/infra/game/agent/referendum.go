@@ -0,0 +1,34 @@
+package agent
+
+import (
+	"infra/game/commons"
+	"infra/game/decision"
+)
+
+// Amender is implemented by strategies that propose amendments to the game's
+// institutional rules and vote on them in referendums. Other strategies
+// propose none and abstain.
+type Amender interface {
+	// ProposeAmendment returns the amendment the agent proposes, or nil to
+	// propose none.
+	ProposeAmendment(baseAgent BaseAgent) *decision.Amendment
+	HandleReferendum(baseAgent BaseAgent, proposer commons.ID, amendment decision.Amendment) decision.Intent
+}
+
+// ProposeAmendment asks strategy for the amendment it proposes, returning nil
+// if it proposes none or does not amend.
+func ProposeAmendment(strategy Strategy, baseAgent BaseAgent) *decision.Amendment {
+	if a, ok := strategy.(Amender); ok {
+		return a.ProposeAmendment(baseAgent)
+	}
+	return nil
+}
+
+// ReferendumBallot asks strategy for its vote on proposer's amendment,
+// abstaining if it does not amend.
+func ReferendumBallot(strategy Strategy, baseAgent BaseAgent, proposer commons.ID, amendment decision.Amendment) decision.Intent {
+	if a, ok := strategy.(Amender); ok {
+		return a.HandleReferendum(baseAgent, proposer, amendment)
+	}
+	return decision.Abstain
+}
@@ -0,0 +1,158 @@
+package agent
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"infra/game/commons"
+	"infra/game/decision"
+	"infra/game/message"
+	"infra/logging"
+
+	"golang.org/x/exp/maps"
+)
+
+// DefaultRounds is the number of rounds a stage runs when Rounds.Max is unset.
+const DefaultRounds = 10
+
+// Rounds runs a stage's communication as a sequence of synchronous rounds.
+// In each round, every agent with mail handles it and signals completion; only
+// then are the messages sent during the round delivered, in sender ID order, to
+// be handled in the next round. The stage ends once a round sends nothing, or
+// after Max rounds, so outcomes do not depend on how fast the agents run.
+type Rounds struct {
+	// Max is the most rounds a stage runs. Zero means DefaultRounds.
+	Max uint
+	// Deadline is how long agents have to handle each round. An agent that
+	// misses it takes no further part in the stage, and whatever it sent or
+	// voted for that round is dropped. The stage still waits for it to finish
+	// before ending, so it never runs alongside later stages. Zero means no
+	// deadline.
+	Deadline time.Duration
+}
+
+// Ballot is what an agent put to the tally while handling a round: the
+// proposals it accepted as leader and the proposals it voted for.
+type Ballot[A decision.ProposalAction] struct {
+	Submissions []message.Proposal[A]
+	Votes       []commons.ProposalID
+}
+
+// Run delivers start to every agent, along with anything already sent, and
+// runs rounds until the agents are quiescent.
+//
+// handle is called from its own goroutine for every agent with mail in a
+// round, and must only touch that agent. done is called after every round
+// from the calling goroutine with the agents that finished it in time, in ID
+// order, so per-agent results can be gathered deterministically.
+func (r Rounds) Run(agents map[commons.ID]Agent, start []message.TaggedMessage, handle func(a *Agent, inbox []message.TaggedMessage), done func(ids []commons.ID)) {
+	maxRounds := r.Max
+	if maxRounds == 0 {
+		maxRounds = DefaultRounds
+	}
+
+	ids := maps.Keys(agents)
+	sort.Strings(ids)
+
+	inboxes := make(map[commons.ID][]message.TaggedMessage, len(ids))
+	for _, id := range ids {
+		inboxes[id] = append([]message.TaggedMessage(nil), start...)
+	}
+	late := make(map[commons.ID]bool)
+	deliver(agents, ids, late, inboxes)
+
+	// wait for agents that missed the deadline, then drop what they and the
+	// last round sent, so it does not leak into the next stage
+	var running sync.WaitGroup
+	defer func() {
+		running.Wait()
+		for _, id := range ids {
+			agents[id].BaseAgent.communication.drain()
+		}
+	}()
+
+	for round := uint(0); ; round++ {
+		active := make([]commons.ID, 0, len(ids))
+		for _, id := range ids {
+			if len(inboxes[id]) > 0 && !late[id] {
+				active = append(active, id)
+			}
+		}
+		if len(active) == 0 {
+			return
+		}
+		if round == maxRounds {
+			logging.Log(logging.Debug, logging.LogField{"rounds": maxRounds}, "Stage ended with undelivered messages")
+			break
+		}
+
+		finished := r.runRound(agents, active, inboxes, handle, &running)
+		inTime := make([]commons.ID, 0, len(active))
+		for _, id := range active {
+			if finished[id] {
+				inTime = append(inTime, id)
+			} else {
+				late[id] = true
+				logging.Log(logging.Warn, logging.LogField{"agentID": id, "round": round}, "Agent missed the round deadline")
+			}
+		}
+		done(inTime)
+
+		inboxes = make(map[commons.ID][]message.TaggedMessage, len(ids))
+		deliver(agents, ids, late, inboxes)
+	}
+}
+
+// runRound has every active agent handle its inbox, and reports which of them
+// finished before the deadline. running is done once every handler has
+// returned, including those of agents that missed the deadline.
+func (r Rounds) runRound(agents map[commons.ID]Agent, active []commons.ID, inboxes map[commons.ID][]message.TaggedMessage, handle func(a *Agent, inbox []message.TaggedMessage), running *sync.WaitGroup) map[commons.ID]bool {
+	finished := make(chan commons.ID, len(active))
+	running.Add(len(active))
+	for _, id := range active {
+		a, inbox := agents[id], inboxes[id]
+		go func(id commons.ID) {
+			defer running.Done()
+			handle(&a, inbox)
+			finished <- id
+		}(id)
+	}
+
+	var timeout <-chan time.Time
+	if r.Deadline > 0 {
+		timer := time.NewTimer(r.Deadline)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	inTime := make(map[commons.ID]bool, len(active))
+	for len(inTime) < len(active) {
+		select {
+		case id := <-finished:
+			inTime[id] = true
+		case <-timeout:
+			return inTime
+		}
+	}
+	return inTime
+}
+
+// deliver moves every message sent by agents that are still taking part into
+// their recipients' inboxes, in sender ID order. Messages to agents that have
+// died or dropped out are discarded.
+func deliver(agents map[commons.ID]Agent, ids []commons.ID, late map[commons.ID]bool, inboxes map[commons.ID][]message.TaggedMessage) {
+	for _, id := range ids {
+		if late[id] {
+			continue
+		}
+		for _, e := range agents[id].BaseAgent.communication.drain() {
+			if _, ok := agents[e.to]; !ok || late[e.to] {
+				logging.Log(logging.Trace, nil, fmt.Sprintf("Dropping message from %s to unavailable agent %s", id, e.to))
+				continue
+			}
+			inboxes[e.to] = append(inboxes[e.to], e.m)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package agent_test
+
+import (
+	"math/rand"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"infra/game/agent"
+	"infra/game/commons"
+	"infra/game/message"
+	"infra/game/state"
+
+	"github.com/google/uuid"
+)
+
+func TestRoundsWaitsForLateAgents(t *testing.T) {
+	t.Parallel()
+
+	const deadline = 10 * time.Millisecond
+	ids := []commons.ID{"fast", "slow"}
+	view := state.View{}
+	agents := make(map[commons.ID]agent.Agent, len(ids))
+	for i, id := range ids {
+		a := agent.Agent{BaseAgent: agent.NewBaseAgent(nil, id, id, &view, rand.New(rand.NewSource(int64(i))))}
+		a.SetCommunication(agent.NewCommunication(ids))
+		agents[id] = a
+	}
+
+	var slowDone atomic.Bool
+	received := make(map[commons.ID]int)
+	start := *message.NewTaggedMessage("server", &message.StartFight{}, uuid.Nil)
+	agent.Rounds{Deadline: deadline}.Run(agents, []message.TaggedMessage{start},
+		func(a *agent.Agent, inbox []message.TaggedMessage) {
+			if a.BaseAgent.ID() == "slow" {
+				time.Sleep(5 * deadline)
+				a.BaseAgent.BroadcastBlockingMessage(&message.StartFight{})
+				slowDone.Store(true)
+			}
+		},
+		func(ids []commons.ID) {
+			for _, id := range ids {
+				received[id]++
+			}
+		},
+	)
+
+	if !slowDone.Load() {
+		t.Error("stage ended while the late agent was still running")
+	}
+	if received["slow"] != 0 {
+		t.Errorf("late agent counted in time %d times", received["slow"])
+	}
+	for _, id := range ids {
+		if sent := agents[id].BaseAgent.Outbox(); len(sent) != 0 {
+			t.Errorf("%s has %d messages left to leak into the next stage", id, len(sent))
+		}
+	}
+}
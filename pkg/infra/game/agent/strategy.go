@@ -20,5 +20,18 @@ type Strategy interface {
 	// HandleUpdateShield return the index of the shield you want to use in AgentState.Shields
 	HandleUpdateShield(baseAgent BaseAgent) decision.ItemIdx
 
-	UpdateInternalState(baseAgent BaseAgent, fightResult *commons.ImmutableList[decision.ImmutableFightResult], voteResult *immutable.Map[decision.Intent, uint], logChan chan<- logging.AgentLog)
+	// UpdateInternalState is called at the end of each level with its fight
+	// rounds, confidence vote and election, which is nil if none was held.
+	UpdateInternalState(baseAgent BaseAgent, fightResult *commons.ImmutableList[decision.ImmutableFightResult], voteResult *immutable.Map[decision.Intent, uint], electionResult *decision.ElectionResult, logChan chan<- logging.AgentLog)
+}
+
+// Snapshotter may be implemented by a Strategy whose internal state should
+// survive a checkpoint. Strategies that do not implement it are resumed as
+// freshly constructed.
+type Snapshotter interface {
+	// Snapshot encodes the strategy's internal state.
+	Snapshot(baseAgent BaseAgent) ([]byte, error)
+	// Restore replaces the strategy's internal state with a snapshot. It is
+	// called once the agent can see the resumed game's state.
+	Restore(baseAgent BaseAgent, snapshot []byte) error
 }
@@ -5,6 +5,8 @@ import (
 
 	"github.com/benbjohnson/immutable"
 	"golang.org/x/exp/constraints"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
 )
 
 func SaturatingSub(x uint, y uint) uint {
@@ -39,11 +41,15 @@ func ImmutableMapKeys[K constraints.Ordered, V any](p immutable.Map[K, V]) []K {
 	return keys
 }
 
+// MapToImmutable copies m into an immutable map. Keys are inserted in order,
+// as small immutable maps iterate in insertion order.
 func MapToImmutable[K constraints.Ordered, V any](m map[K]V) immutable.Map[K, V] {
 	builder := immutable.NewMapBuilder[K, V](nil)
 
-	for k, v := range m {
-		builder.Set(k, v)
+	keys := maps.Keys(m)
+	slices.Sort(keys)
+	for _, k := range keys {
+		builder.Set(k, m[k])
 	}
 
 	return *builder.Map()
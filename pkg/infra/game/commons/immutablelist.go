@@ -1,5 +1,7 @@
 package commons
 
+import "encoding/json"
+
 type ImmutableList[A any] struct {
 	internalList []A
 }
@@ -39,3 +41,11 @@ func (i *Iterator[A]) Next() (A, bool) {
 	}
 	return i.internal.internalList[i.index], true
 }
+
+func (l ImmutableList[A]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(l.internalList)
+}
+
+func (l *ImmutableList[A]) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, &l.internalList)
+}
@@ -0,0 +1,9 @@
+package decision
+
+// Amendment proposes setting one of the game's institutional rules, named by
+// its environment variable (see config.Amendable), to Value, eg. setting
+// VOTING_STRATEGY to 2.
+type Amendment struct {
+	Setting string
+	Value   string
+}
@@ -0,0 +1,17 @@
+package decision
+
+// Role is a power the leader may delegate to a member of its cabinet.
+type Role string
+
+const (
+	// FightMarshal resolves the fight actions, when the manifesto gives the
+	// leader fight decision power.
+	FightMarshal Role = "fight-marshal"
+	// Quartermaster sanctions agents out of the loot and orders the rest.
+	Quartermaster Role = "quartermaster"
+	// Treasurer decides whether the HP pool is spent on the monster.
+	Treasurer Role = "treasurer"
+)
+
+// Roles are the cabinet's roles, in the order they are filled.
+var Roles = []Role{FightMarshal, Quartermaster, Treasurer}
@@ -1,7 +1,10 @@
 package decision
 
 import (
+	"encoding/json"
+
 	"infra/game/commons"
+	"infra/logging"
 
 	"github.com/benbjohnson/immutable"
 )
@@ -17,8 +20,74 @@ type Manifesto struct {
 	lootDecisionPower  bool
 	termLength         uint
 	overthrowThreshold uint
+	policies           Policies
+}
+
+// Policies are what a leader commits to in its manifesto, which the engine
+// enforces for the leader's term. The zero value commits to nothing, and
+// values the engine does not know are ignored.
+type Policies struct {
+	// HPPoolTax is the percentage of its HP every agent must donate to the
+	// HP pool each level, though never its last HP.
+	HPPoolTax uint `json:",omitempty"`
+	// LootAllocation is the order in which the loot is shared out. Empty is
+	// LeaderOrder.
+	LootAllocation LootAllocation `json:",omitempty"`
+	// Sanctions is how long the leader's sanctions last. Empty leaves it to
+	// the game's sanction settings.
+	Sanctions SanctionRegime `json:",omitempty"`
+	// MaxLeaderLootShare is the largest percentage of a loot pool's items
+	// the leader may take. Zero sets no limit.
+	MaxLeaderLootShare uint `json:",omitempty"`
+}
+
+// Keeps reports whether p commits to every policy promised sets.
+func (p Policies) Keeps(promised Policies) bool {
+	return (promised.HPPoolTax == 0 || promised.HPPoolTax == p.HPPoolTax) &&
+		(promised.LootAllocation == "" || promised.LootAllocation == p.LootAllocation) &&
+		(promised.Sanctions == "" || promised.Sanctions == p.Sanctions) &&
+		(promised.MaxLeaderLootShare == 0 || promised.MaxLeaderLootShare == p.MaxLeaderLootShare)
 }
 
+// Log is how the policies are logged.
+func (p Policies) Log() logging.PoliciesLog {
+	return logging.PoliciesLog{
+		HPPoolTax:          p.HPPoolTax,
+		LootAllocation:     string(p.LootAllocation),
+		Sanctions:          string(p.Sanctions),
+		MaxLeaderLootShare: p.MaxLeaderLootShare,
+	}
+}
+
+// LootAllocation is the order in which agents take turns choosing loot.
+type LootAllocation string
+
+const (
+	// LeaderOrder lets the leader order the agents.
+	LeaderOrder LootAllocation = "leader"
+	// RoundRobin orders the agents by ID.
+	RoundRobin LootAllocation = "round-robin"
+	// NeedsFirst orders the agents from the lowest HP up.
+	NeedsFirst LootAllocation = "needs"
+	// Lottery orders the agents at random.
+	Lottery LootAllocation = "lottery"
+)
+
+// SanctionRegime is how the durations of sanctions are decided, matching the
+// flags of cmdline.CmdLine.
+type SanctionRegime string
+
+const (
+	// FixedSanctions last FixedSanctionDuration levels.
+	FixedSanctions SanctionRegime = "fixed"
+	// GraduatedSanctions last longer for repeat offenders, up to
+	// MaxGraduatedSanctionDuration levels.
+	GraduatedSanctions SanctionRegime = "graduated"
+	// DynamicSanctions last as long as the leader decides from the agents'
+	// stats.
+	DynamicSanctions SanctionRegime = "dynamic"
+)
+
 func (m Manifesto) FightDecisionPower() bool {
 	return m.fightDecisionPower
 }
@@ -35,6 +104,17 @@ func (m Manifesto) OverthrowThreshold() uint {
 	return m.overthrowThreshold
 }
 
+// Policies are what the leader commits to for its term.
+func (m Manifesto) Policies() Policies {
+	return m.policies
+}
+
+// WithPolicies returns a copy of the manifesto committing to policies.
+func (m Manifesto) WithPolicies(policies Policies) *Manifesto {
+	m.policies = policies
+	return &m
+}
+
 func NewManifesto(fightDecisionPower bool, lootDecisionPower bool, termLength uint, overthrowThreshold uint) *Manifesto {
 	return &Manifesto{
 		fightDecisionPower: fightDecisionPower,
@@ -48,18 +128,16 @@ type ElectionParams struct {
 	candidateList       *immutable.Map[commons.ID, Manifesto]
 	strategy            VotingStrategy
 	numberOfPreferences uint
+	maxScore            uint
 }
 
 func (e ElectionParams) CandidateList() *immutable.Map[commons.ID, Manifesto] {
 	return e.candidateList
 }
 
-func NewElectionParams(candidateList map[commons.ID]Manifesto, strategy VotingStrategy, numberOfPreferences uint) *ElectionParams {
-	builder := immutable.NewMapBuilder[commons.ID, Manifesto](nil)
-	for id, manifesto := range candidateList {
-		builder.Set(id, manifesto)
-	}
-	return &ElectionParams{candidateList: builder.Map(), strategy: strategy, numberOfPreferences: numberOfPreferences}
+func NewElectionParams(candidateList map[commons.ID]Manifesto, strategy VotingStrategy, numberOfPreferences uint, maxScore uint) *ElectionParams {
+	candidates := commons.MapToImmutable(candidateList)
+	return &ElectionParams{candidateList: &candidates, strategy: strategy, numberOfPreferences: numberOfPreferences, maxScore: maxScore}
 }
 
 func (e ElectionParams) Strategy() VotingStrategy {
@@ -70,6 +148,11 @@ func (e ElectionParams) NumberOfPreferences() uint {
 	return e.numberOfPreferences
 }
 
+// MaxScore is the highest score a candidate can be given in score voting.
+func (e ElectionParams) MaxScore() uint {
+	return e.maxScore
+}
+
 // Intent is used for polling.
 // Positive can mean true/agree/have confidence
 // Negative can mean false/disagree/don't have confidence
@@ -87,11 +170,53 @@ const (
 // e.g. 1 candidate in choose-one voting and >1 candidates in ranked voting.
 type Ballot []commons.ID
 
+// Scores is a ballot that scores candidates rather than ranking them: 1 for
+// each approved candidate in approval voting, or 0 to MaxScore in score
+// voting. Candidates left out score 0.
+type Scores map[commons.ID]uint
+
+// Scores converts a ranked ballot for strategies that only rank candidates:
+// in approval voting every ranked candidate is approved, and in score voting
+// they are scored from maxScore for the first down in even steps.
+func (b Ballot) Scores(strategy VotingStrategy, maxScore uint) Scores {
+	scores := make(Scores, len(b))
+	for i, candidate := range b {
+		if strategy == ApprovalVoting {
+			scores[candidate] = 1
+		} else {
+			scores[candidate] = maxScore * uint(len(b)-i) / uint(len(b))
+		}
+	}
+	return scores
+}
+
 type VotingStrategy uint
 
+// Scored reports whether ballots score candidates (see Scores) rather than
+// rank them.
+func (s VotingStrategy) Scored() bool {
+	return s == ApprovalVoting || s == ScoreVoting
+}
+
 const (
 	SingleChoicePlurality = iota
 	BordaCount
+	// InstantRunoff eliminates the last placed candidate round by round,
+	// counting each ballot for its highest ranked candidate still standing.
+	InstantRunoff
+	// ApprovalVoting elects the candidate approved on the most ballots.
+	ApprovalVoting
+	// ScoreVoting elects the candidate with the highest total score.
+	ScoreVoting
+	// Copeland elects the candidate winning the most head-to-head contests
+	// between the ranked candidates, which is the Condorcet winner if there
+	// is one.
+	Copeland
+	// TwoRound elects the candidate with a majority of first preferences or,
+	// failing that, the winner of a second ballot between the top two.
+	TwoRound
+	// NumVotingStrategies is the number of voting strategies above.
+	NumVotingStrategies
 )
 
 type HpPoolDonation struct {
@@ -100,3 +225,36 @@ type HpPoolDonation struct {
 }
 
 type ItemIdx uint
+
+type manifestoJSON struct {
+	FightDecisionPower bool
+	LootDecisionPower  bool
+	TermLength         uint
+	OverthrowThreshold uint
+	Policies           *Policies `json:",omitempty"`
+}
+
+func (m Manifesto) MarshalJSON() ([]byte, error) {
+	j := manifestoJSON{
+		FightDecisionPower: m.fightDecisionPower,
+		LootDecisionPower:  m.lootDecisionPower,
+		TermLength:         m.termLength,
+		OverthrowThreshold: m.overthrowThreshold,
+	}
+	if m.policies != (Policies{}) {
+		j.Policies = &m.policies
+	}
+	return json.Marshal(j)
+}
+
+func (m *Manifesto) UnmarshalJSON(data []byte) error {
+	var j manifestoJSON
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	*m = *NewManifesto(j.FightDecisionPower, j.LootDecisionPower, j.TermLength, j.OverthrowThreshold)
+	if j.Policies != nil {
+		m.policies = *j.Policies
+	}
+	return nil
+}
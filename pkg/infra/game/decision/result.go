@@ -2,6 +2,7 @@ package decision
 
 import (
 	"infra/game/commons"
+	"infra/logging"
 
 	"github.com/benbjohnson/immutable"
 )
@@ -64,3 +65,48 @@ func NewImmutableFightResult(fightResult FightResult, round uint) *ImmutableFigh
 		round:           round,
 	}
 }
+
+// ElectionResult is the full count of a leader election. Agents are given the
+// same result, which they must not modify.
+type ElectionResult struct {
+	Strategy  VotingStrategy
+	Winner    commons.ID
+	Manifesto Manifesto
+	// Candidates are the manifestos every candidate stood on, as revised for
+	// the runoff of a two-round election.
+	Candidates map[commons.ID]Manifesto
+	// Tally is what each candidate counted for the result: first preferences
+	// in plurality, points in Borda count, votes in the last round of an
+	// instant runoff or two-round election, head-to-head wins in Copeland,
+	// approvals or total scores.
+	Tally map[commons.ID]float64
+	// Margin is how far the winner's tally is ahead of the runner-up's.
+	Margin float64
+	// Electorate is the number of agents asked to vote, of which Ballots
+	// named at least one candidate and Abstentions none.
+	Electorate  uint
+	Ballots     uint
+	Abstentions uint
+	// Weights is how much each voter's ballot counted, nil if every ballot
+	// counted once.
+	Weights map[commons.ID]float64
+	// Rounds are the counts of an instant runoff or two-round election,
+	// round by round.
+	Rounds []logging.RunoffRound
+	// Revised are the runoff candidates of a two-round election who revised
+	// their manifestos.
+	Revised []commons.ID
+	// Condorcet is the head-to-head count of a Copeland election.
+	Condorcet *logging.CondorcetCount
+	// Campaign is what was promised and endorsed before the vote, nil if
+	// there was no campaign.
+	Campaign *logging.CampaignLog
+}
+
+// Turnout is the fraction of the electorate that named a candidate.
+func (r *ElectionResult) Turnout() float64 {
+	if r.Electorate == 0 {
+		return 0
+	}
+	return float64(r.Ballots) / float64(r.Electorate)
+}
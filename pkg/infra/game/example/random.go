@@ -8,13 +8,17 @@ import (
 	"infra/game/message/proposal"
 	"infra/game/state"
 	"infra/logging"
-	"math/rand"
+	"sort"
 
 	"github.com/benbjohnson/immutable"
+	"golang.org/x/exp/maps"
 )
 
 type RandomAgent struct {
 	bravery int
+	// started is whether the starting bravery has been drawn. It is drawn on
+	// first use, as the agent's random stream does not exist before then.
+	started bool
 }
 
 func (r *RandomAgent) FightResolution(
@@ -26,7 +30,7 @@ func (r *RandomAgent) FightResolution(
 	builder := immutable.NewMapBuilder[commons.ID, decision.FightAction](nil)
 	for _, id := range commons.ImmutableMapKeys(view.AgentState()) {
 		var fightAction decision.FightAction
-		switch rand.Intn(3) {
+		switch agent.Rand().Intn(3) {
 		case 0:
 			fightAction = decision.Attack
 		case 1:
@@ -50,28 +54,28 @@ func (r *RandomAgent) LootActionNoProposal(baseAgent agent.BaseAgent) immutable.
 
 	for !weapons.Done() {
 		weapon, _ := weapons.Next()
-		if rand.Int()%2 == 0 {
+		if baseAgent.Rand().Int()%2 == 0 {
 			builder.Set(weapon.Id(), struct{}{})
 		}
 	}
 
 	for !shields.Done() {
 		shield, _ := shields.Next()
-		if rand.Int()%2 == 0 {
+		if baseAgent.Rand().Int()%2 == 0 {
 			builder.Set(shield.Id(), struct{}{})
 		}
 	}
 
 	for !hpPotions.Done() {
 		pot, _ := hpPotions.Next()
-		if rand.Int()%2 == 0 {
+		if baseAgent.Rand().Int()%2 == 0 {
 			builder.Set(pot.Id(), struct{}{})
 		}
 	}
 
 	for !staminaPotions.Done() {
 		pot, _ := staminaPotions.Next()
-		if rand.Int()%2 == 0 {
+		if baseAgent.Rand().Int()%2 == 0 {
 			builder.Set(pot.Id(), struct{}{})
 		}
 	}
@@ -87,8 +91,8 @@ func (r *RandomAgent) LootAction(
 	return proposedLoot
 }
 
-func (r *RandomAgent) FightActionNoProposal(_ agent.BaseAgent) decision.FightAction {
-	fight := rand.Intn(3)
+func (r *RandomAgent) FightActionNoProposal(baseAgent agent.BaseAgent) decision.FightAction {
+	fight := baseAgent.Rand().Intn(3)
 	switch fight {
 	case 0:
 		return decision.Cower
@@ -114,8 +118,8 @@ func (r *RandomAgent) HandleLootRequest(m message.TaggedRequestMessage[message.L
 	return nil
 }
 
-func (r *RandomAgent) HandleLootProposal(_ message.Proposal[decision.LootAction], _ agent.BaseAgent) decision.Intent {
-	switch rand.Intn(3) {
+func (r *RandomAgent) HandleLootProposal(_ message.Proposal[decision.LootAction], baseAgent agent.BaseAgent) decision.Intent {
+	switch baseAgent.Rand().Intn(3) {
 	case 0:
 		return decision.Positive
 	case 1:
@@ -125,8 +129,8 @@ func (r *RandomAgent) HandleLootProposal(_ message.Proposal[decision.LootAction]
 	}
 }
 
-func (r *RandomAgent) HandleLootProposalRequest(_ message.Proposal[decision.LootAction], _ agent.BaseAgent) bool {
-	switch rand.Intn(2) {
+func (r *RandomAgent) HandleLootProposalRequest(_ message.Proposal[decision.LootAction], baseAgent agent.BaseAgent) bool {
+	switch baseAgent.Rand().Intn(2) {
 	case 0:
 		return true
 	default:
@@ -143,13 +147,13 @@ func (r *RandomAgent) LootAllocation(
 	view := baseAgent.View()
 	ids := commons.ImmutableMapKeys(view.AgentState())
 	iterator := baseAgent.Loot().Weapons().Iterator()
-	allocateRandomly(iterator, ids, lootAllocation)
+	allocateRandomly(baseAgent, iterator, ids, lootAllocation)
 	iterator = baseAgent.Loot().Shields().Iterator()
-	allocateRandomly(iterator, ids, lootAllocation)
+	allocateRandomly(baseAgent, iterator, ids, lootAllocation)
 	iterator = baseAgent.Loot().HpPotions().Iterator()
-	allocateRandomly(iterator, ids, lootAllocation)
+	allocateRandomly(baseAgent, iterator, ids, lootAllocation)
 	iterator = baseAgent.Loot().StaminaPotions().Iterator()
-	allocateRandomly(iterator, ids, lootAllocation)
+	allocateRandomly(baseAgent, iterator, ids, lootAllocation)
 	mMapped := make(map[commons.ID]immutable.SortedMap[commons.ItemID, struct{}])
 	for id, itemIDS := range lootAllocation {
 		mMapped[id] = commons.ListToImmutableSortedSet(itemIDS)
@@ -157,10 +161,10 @@ func (r *RandomAgent) LootAllocation(
 	return commons.MapToImmutable(mMapped)
 }
 
-func allocateRandomly(iterator commons.Iterator[state.Item], ids []commons.ID, lootAllocation map[commons.ID][]commons.ItemID) {
+func allocateRandomly(baseAgent agent.BaseAgent, iterator commons.Iterator[state.Item], ids []commons.ID, lootAllocation map[commons.ID][]commons.ItemID) {
 	for !iterator.Done() {
 		next, _ := iterator.Next()
-		toBeAllocated := ids[rand.Intn(len(ids))]
+		toBeAllocated := ids[baseAgent.Rand().Intn(len(ids))]
 		if l, ok := lootAllocation[toBeAllocated]; ok {
 			l = append(l, next.Id())
 			lootAllocation[toBeAllocated] = l
@@ -173,11 +177,14 @@ func allocateRandomly(iterator commons.Iterator[state.Item], ids []commons.ID, l
 }
 
 func (r *RandomAgent) DonateToHpPool(baseAgent agent.BaseAgent) uint {
-	return uint(rand.Intn(int(baseAgent.AgentState().Hp)))
+	return uint(baseAgent.Rand().Intn(int(baseAgent.AgentState().Hp)))
 }
 
-func (r *RandomAgent) UpdateInternalState(a agent.BaseAgent, _ *commons.ImmutableList[decision.ImmutableFightResult], _ *immutable.Map[decision.Intent, uint], log chan<- logging.AgentLog) {
-	r.bravery += rand.Intn(10)
+func (r *RandomAgent) UpdateInternalState(a agent.BaseAgent, _ *commons.ImmutableList[decision.ImmutableFightResult], _ *immutable.Map[decision.Intent, uint], _ *decision.ElectionResult, log chan<- logging.AgentLog) {
+	if !r.started {
+		r.bravery, r.started = a.Rand().Intn(5), true
+	}
+	r.bravery += a.Rand().Intn(10)
 	log <- logging.AgentLog{
 		Name: a.Name(),
 		ID:   a.ID(),
@@ -192,8 +199,8 @@ func (r *RandomAgent) CreateManifesto(_ agent.BaseAgent) *decision.Manifesto {
 	return manifesto
 }
 
-func (r *RandomAgent) HandleConfidencePoll(_ agent.BaseAgent) decision.Intent {
-	switch rand.Intn(3) {
+func (r *RandomAgent) HandleConfidencePoll(baseAgent agent.BaseAgent) decision.Intent {
+	switch baseAgent.Rand().Intn(3) {
 	case 0:
 		return decision.Abstain
 	case 1:
@@ -205,7 +212,7 @@ func (r *RandomAgent) HandleConfidencePoll(_ agent.BaseAgent) decision.Intent {
 
 func (r *RandomAgent) HandleFightInformation(_ message.TaggedInformMessage[message.FightInform], baseAgent agent.BaseAgent, _ *immutable.Map[commons.ID, decision.FightAction]) {
 	// baseAgent.Log(logging.Trace, logging.LogField{"bravery": r.bravery, "hp": baseAgent.AgentState().Hp}, "Cowering")
-	makesProposal := rand.Intn(100)
+	makesProposal := baseAgent.Rand().Intn(100)
 
 	if makesProposal > 80 {
 		rules := make([]proposal.Rule[decision.FightAction], 0)
@@ -254,9 +261,9 @@ func (r *RandomAgent) HandleElectionBallot(b agent.BaseAgent, _ *decision.Electi
 	// Randomly fill the ballot
 	var ballot decision.Ballot
 	numAliveAgents := len(aliveAgentIDs)
-	numCandidate := rand.Intn(numAliveAgents)
+	numCandidate := b.Rand().Intn(numAliveAgents)
 	for i := 0; i < numCandidate; i++ {
-		randomIdx := rand.Intn(numAliveAgents)
+		randomIdx := b.Rand().Intn(numAliveAgents)
 		randomCandidate := aliveAgentIDs[uint(randomIdx)]
 		ballot = append(ballot, randomCandidate)
 	}
@@ -264,8 +271,41 @@ func (r *RandomAgent) HandleElectionBallot(b agent.BaseAgent, _ *decision.Electi
 	return ballot
 }
 
-func (r *RandomAgent) HandleFightProposal(_ message.Proposal[decision.FightAction], _ agent.BaseAgent) decision.Intent {
-	intent := rand.Intn(2)
+func (r *RandomAgent) HandleRunoffBallot(_ agent.BaseAgent, params *decision.ElectionParams, firstBallot decision.Ballot) decision.Ballot {
+	return agent.DefaultRunoffBallot(params, firstBallot)
+}
+
+func (r *RandomAgent) HandleCampaignInformation(m message.TaggedInformMessage[message.CampaignInform], baseAgent agent.BaseAgent, params *decision.ElectionParams) {
+	if _, ok := m.Message().(message.StartCampaign); !ok {
+		return
+	}
+	if manifesto, ok := params.CandidateList().Get(baseAgent.ID()); ok {
+		baseAgent.BroadcastBlockingMessage(message.Promise{Policies: manifesto.Policies()})
+		return
+	}
+
+	candidates := commons.ImmutableMapKeys(*params.CandidateList())
+	if len(candidates) == 0 {
+		return
+	}
+	sort.Strings(candidates)
+	candidate := candidates[baseAgent.Rand().Intn(len(candidates))]
+	if baseAgent.Rand().Intn(2) == 0 {
+		baseAgent.BroadcastBlockingMessage(message.Endorsement{Candidate: candidate})
+	} else {
+		_ = baseAgent.SendBlockingMessage(candidate, message.Question{Topic: "loot"})
+	}
+}
+
+func (r *RandomAgent) HandleCampaignRequest(_ message.TaggedRequestMessage[message.CampaignRequest], baseAgent agent.BaseAgent, params *decision.ElectionParams) message.CampaignInform {
+	if manifesto, ok := params.CandidateList().Get(baseAgent.ID()); ok {
+		return message.Promise{Policies: manifesto.Policies()}
+	}
+	return nil
+}
+
+func (r *RandomAgent) HandleFightProposal(_ message.Proposal[decision.FightAction], baseAgent agent.BaseAgent) decision.Intent {
+	intent := baseAgent.Rand().Intn(2)
 	if intent == 0 {
 		return decision.Positive
 	} else {
@@ -275,10 +315,10 @@ func (r *RandomAgent) HandleFightProposal(_ message.Proposal[decision.FightActio
 
 func (r *RandomAgent) HandleFightProposalRequest(
 	_ message.Proposal[decision.FightAction],
-	_ agent.BaseAgent,
+	baseAgent agent.BaseAgent,
 	_ *immutable.Map[commons.ID, decision.FightAction],
 ) bool {
-	switch rand.Intn(2) {
+	switch baseAgent.Rand().Intn(2) {
 	case 0:
 		return true
 	default:
@@ -300,26 +340,49 @@ func (r *RandomAgent) HandleTradeNegotiation(_ agent.BaseAgent, _ message.TradeI
 	return message.TradeRequest{}
 }
 
-func (r *RandomAgent) PruneAgentList(agentMap map[commons.ID]agent.Agent) map[commons.ID]agent.Agent {
+func (r *RandomAgent) PruneAgentList(_ agent.BaseAgent, agentMap map[commons.ID]agent.Agent) map[commons.ID]agent.Agent {
 	return agentMap
 }
 
-func (r *RandomAgent) CompileTrustMessage(agentMap map[commons.ID]agent.Agent) message.Trust
+func (r *RandomAgent) CompileTrustMessage(_ map[commons.ID]agent.Agent) message.Trust {
+	// random agents do not gossip
+	return message.Trust{}
+}
 
-func (r *RandomAgent) HandleTrustMessage(message message.TaggedMessage)
+func (r *RandomAgent) HandleTrustMessage(_ message.TaggedMessage) {
+}
 
-func (r *RandomAgent) GetStats() (int, int)
+func (r *RandomAgent) GetStats() (int, int) {
+	// random agents have no personality, report them as collective
+	return 50, 0
+}
 
-func (r *RandomAgent) ChooseItem(BaseAgent agent.BaseAgent,
-	weaponSet []state.Item,
-	shieldSet []state.Item,
-	hpPotionSet []state.Item,
-	staminaPotionSet []state.Item) []state.ItemName
+func (r *RandomAgent) ChooseItem(baseAgent agent.BaseAgent,
+	_ []state.Item,
+	_ []state.Item,
+	_ []state.Item,
+	_ []state.Item,
+) []state.ItemName {
+	preferences := []state.ItemName{state.SWORD, state.SHIELD, state.HP_POTION, state.STAMINA_POTION}
+	baseAgent.Rand().Shuffle(len(preferences), func(i, j int) {
+		preferences[i], preferences[j] = preferences[j], preferences[i]
+	})
+	return preferences
+}
 
-func (r *RandomAgent) RequestLootProposal(ba agent.BaseAgent)
+func (r *RandomAgent) RequestLootProposal(_ agent.BaseAgent) {
+}
 
-func (r *RandomAgent) SortAgentsArray(agentMap map[commons.ID]agent.Agent) []agent.Agent
+func (r *RandomAgent) SortAgentsArray(agentMap map[commons.ID]agent.Agent) []agent.Agent {
+	ids := maps.Keys(agentMap)
+	sort.Strings(ids)
+	agents := make([]agent.Agent, len(ids))
+	for i, id := range ids {
+		agents[i] = agentMap[id]
+	}
+	return agents
+}
 
 func NewRandomAgent() agent.Strategy {
-	return &RandomAgent{bravery: rand.Intn(5)}
+	return &RandomAgent{}
 }
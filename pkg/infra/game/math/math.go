@@ -3,25 +3,21 @@ package math
 import (
 	"math"
 	"math/rand"
-	"time"
 
 	"infra/config"
+	"infra/game/state"
 )
 
-func init() {
-	rand.Seed(time.Now().UnixNano())
-}
-
 // Enemy Resilience Modifier
-func CalculateDelta() float64 {
+func CalculateDelta(r *rand.Rand) float64 {
 	min := 0.8
 	max := 1.2
-	return min + rand.Float64()*(max-min)
+	return min + r.Float64()*(max-min)
 }
 
 // X, the monster’s resilience
-func CalculateMonsterHealth(nAgent uint, stamina uint, nLevel uint, currentLevel uint) uint {
-	delta := CalculateDelta()
+func CalculateMonsterHealth(r *rand.Rand, nAgent uint, stamina uint, nLevel uint, currentLevel uint) uint {
+	delta := CalculateDelta(r)
 	NFp := float64(nAgent)
 	LFp := float64(nLevel)
 
@@ -38,8 +34,8 @@ func CalculateMonsterHealth(nAgent uint, stamina uint, nLevel uint, currentLevel
 }
 
 // Y, monster’s damage rating
-func CalculateMonsterDamage(nAgent uint, HP uint, stamina uint, thresholdPercentage float32, nLevel uint, currentLevel uint) uint {
-	delta := CalculateDelta()
+func CalculateMonsterDamage(r *rand.Rand, nAgent uint, HP uint, stamina uint, thresholdPercentage float32, nLevel uint, currentLevel uint) uint {
+	delta := CalculateDelta(r)
 	NFp := float64(nAgent)
 	LFp := float64(nLevel)
 	damageBoost := 2.5
@@ -55,56 +51,78 @@ func CalculateMonsterDamage(nAgent uint, HP uint, stamina uint, thresholdPercent
 	// return uint(delta * (NFp / LFp) * (float64(HP) + float64(stamina)) * (float64(currentLevel)/LFp + 0.5))
 }
 
-func GetNextLevelMonsterValues(gameConfig config.GameConfig, currentLevel uint) (uint, uint) {
-	return CalculateMonsterHealth(gameConfig.InitialNumAgents, gameConfig.Stamina, gameConfig.NumLevels, currentLevel+1), CalculateMonsterDamage(gameConfig.InitialNumAgents, gameConfig.StartingHealthPoints, gameConfig.Stamina, gameConfig.ThresholdPercentage, gameConfig.NumLevels, currentLevel+1)
+func GetNextLevelMonsterValues(r *rand.Rand, gameConfig config.GameConfig, currentLevel uint) (uint, uint) {
+	return CalculateMonsterHealth(r, gameConfig.InitialNumAgents, gameConfig.Stamina, gameConfig.NumLevels, currentLevel+1), CalculateMonsterDamage(r, gameConfig.InitialNumAgents, gameConfig.StartingHealthPoints, gameConfig.Stamina, gameConfig.ThresholdPercentage, gameConfig.NumLevels, currentLevel+1)
+}
+
+// MonsterAbilities draws each of the monster's abilities with the configured
+// chance, scaling their strength by the monster's health and the agents'
+// stamina where they are absolute.
+func MonsterAbilities(r *rand.Rand, gameConfig config.GameConfig, health uint) state.MonsterAbilities {
+	abilities := make(state.MonsterAbilities)
+	for _, a := range state.Abilities {
+		if r.Float64() >= float64(gameConfig.MonsterAbilityChance) {
+			continue
+		}
+		delta := CalculateDelta(r)
+		switch a {
+		case state.StaminaDrain:
+			abilities[a] = math.Ceil(delta * 0.02 * float64(gameConfig.Stamina))
+		case state.Regeneration:
+			abilities[a] = math.Ceil(delta * 0.02 * float64(health))
+		case state.TargetWeakest, state.TargetLeader:
+			abilities[a] = delta * 0.25
+		case state.Armour, state.AreaDamage:
+			abilities[a] = delta * 0.2
+		}
+	}
+	return abilities
 }
 
-func NumberPotionDropped(P float64, nAgent uint) uint {
-	delta := CalculateDelta()
+func NumberPotionDropped(r *rand.Rand, P float64, nAgent uint) uint {
+	delta := CalculateDelta(r)
 	return uint(delta * P * float64(nAgent))
 }
 
-func NumberEquipmentDropped(E float64, nAgent uint) uint {
-	delta := CalculateDelta()
+func NumberEquipmentDropped(r *rand.Rand, E float64, nAgent uint) uint {
+	delta := CalculateDelta(r)
 	return uint(delta * E * float64(nAgent))
 }
 
 // function encapsulated to use same random val
-func GetPotionDistribution(nAgent uint) (uint, uint) {
-	tau := rand.Float64()
-	// hardcoded by design
-	P := float64(config.EnvToFloat("POTION_SCARCITY_PCT", 0.2))
-	NumberHealthPotionDropped := uint((tau) * float64(NumberPotionDropped(P, nAgent)))
-	NumberStaminaPotionDropped := uint((1 - tau) * float64(NumberPotionDropped(P, nAgent)))
+func GetPotionDistribution(r *rand.Rand, scarcity float32, nAgent uint) (uint, uint) {
+	tau := r.Float64()
+	P := float64(scarcity)
+	NumberHealthPotionDropped := uint((tau) * float64(NumberPotionDropped(r, P, nAgent)))
+	NumberStaminaPotionDropped := uint((1 - tau) * float64(NumberPotionDropped(r, P, nAgent)))
 	return NumberHealthPotionDropped, NumberStaminaPotionDropped
 }
 
 // tau recalculated for equipment  and potions
-func GetEquipmentDistribution(nAgent uint) (uint, uint) {
-	tau := rand.Float64()
-	// hardcoded by design
-	E := float64(config.EnvToFloat("EQUIPMENT_SCARCITY_PCT", 0.15))
-	NumberWeaponDropped := uint((tau) * float64(NumberEquipmentDropped(E, nAgent)))
-	NumberShieldDropped := uint((1 - tau) * float64(NumberEquipmentDropped(E, nAgent)))
+func GetEquipmentDistribution(r *rand.Rand, scarcity float32, nAgent uint) (uint, uint) {
+	tau := r.Float64()
+	E := float64(scarcity)
+	NumberWeaponDropped := uint((tau) * float64(NumberEquipmentDropped(r, E, nAgent)))
+	NumberShieldDropped := uint((1 - tau) * float64(NumberEquipmentDropped(r, E, nAgent)))
 	return NumberWeaponDropped, NumberShieldDropped
 }
 
-func GetWeaponDamage(X uint, nAgent uint) uint {
-	delta := CalculateDelta()
+func GetWeaponDamage(r *rand.Rand, X uint, nAgent uint) uint {
+	delta := CalculateDelta(r)
 	return uint(math.Ceil((delta * float64(X) * 2) / float64(nAgent)))
 }
 
-func GetShieldProtection(Y uint, nAgent uint) uint {
-	delta := CalculateDelta()
+func GetShieldProtection(r *rand.Rand, Y uint, nAgent uint) uint {
+	delta := CalculateDelta(r)
 	return uint(math.Ceil((delta * float64(Y)) / (float64(nAgent))))
 }
 
-func GetHealthPotionValue(Y uint, nAgent uint) uint {
-	delta := CalculateDelta()
+func GetHealthPotionValue(r *rand.Rand, Y uint, nAgent uint) uint {
+	delta := CalculateDelta(r)
 	return uint(math.Ceil((delta * float64(Y) * 5.0) / (float64(nAgent))))
 }
 
-func GetStaminaPotionValue(X uint, nAgent uint) uint {
-	delta := CalculateDelta()
+func GetStaminaPotionValue(r *rand.Rand, X uint, nAgent uint) uint {
+	delta := CalculateDelta(r)
 	return uint(math.Ceil((delta * float64(X) * 5.0) / (float64(nAgent))))
 }
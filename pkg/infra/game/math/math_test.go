@@ -1,9 +1,12 @@
 package math_test
 
 import (
+	"math/rand"
 	"testing"
 
+	"infra/config"
 	"infra/game/math"
+	"infra/game/state"
 )
 
 func TestCalculateMonsterHealth(t *testing.T) {
@@ -24,16 +27,17 @@ func TestCalculateMonsterHealth(t *testing.T) {
 		{
 			name: "Case 1",
 			args: args{N: 100, ST: 2000, L: 60, CL: 1},
-			// min for delta = 0.8 and max for delta = 1.2
-			wantmin: 1423,
-			wantmax: 2134,
+			// min for delta = 0.8 and max for delta = 1.2, including the 1.3 health boost
+			wantmin: 1791,
+			wantmax: 2687,
 		},
 	}
 	for _, tt := range tests {
+		tt := tt
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
 
-			if got := math.CalculateMonsterHealth(tt.args.N, tt.args.ST, tt.args.L, tt.args.CL); !(tt.wantmin <= got && got <= tt.wantmax) {
+			if got := math.CalculateMonsterHealth(rand.New(rand.NewSource(1)), tt.args.N, tt.args.ST, tt.args.L, tt.args.CL); !(tt.wantmin <= got && got <= tt.wantmax) {
 				t.Errorf("CalculateMonsterHealth() = %v, wanted between %v and %v", got, tt.wantmin, tt.wantmax)
 			}
 		})
@@ -60,18 +64,49 @@ func TestCalculateMonsterDamage(t *testing.T) {
 		{
 			name: "Case 1",
 			args: args{N: 100, HP: 1000, ST: 2000, TH: 0.1, L: 60, CL: 1},
-			// min for delta = 0.8 and max for delta = 1.2
-			wantmin: 1922,
-			wantmax: 2848,
+			// min for delta = 0.8 and max for delta = 1.2, including the 2.5 damage boost
+			wantmin: 5166,
+			wantmax: 7750,
 		},
 	}
 	for _, tt := range tests {
+		tt := tt
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
 
-			if got := math.CalculateMonsterDamage(tt.args.N, tt.args.HP, tt.args.ST, tt.args.TH, tt.args.L, tt.args.CL); !(tt.wantmin <= got && got <= tt.wantmax) {
+			if got := math.CalculateMonsterDamage(rand.New(rand.NewSource(1)), tt.args.N, tt.args.HP, tt.args.ST, tt.args.TH, tt.args.L, tt.args.CL); !(tt.wantmin <= got && got <= tt.wantmax) {
 				t.Errorf("CalculateMonsterDamage() = %v,  wanted between %v and %v", got, tt.wantmin, tt.wantmax)
 			}
 		})
 	}
 }
+
+func TestMonsterAbilities(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		chance float32
+		want   int
+	}{
+		{name: "never", chance: 0, want: 0},
+		{name: "always", chance: 1, want: len(state.Abilities)},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := config.GameConfig{Stamina: 2000, MonsterAbilityChance: tt.chance}
+			got := math.MonsterAbilities(rand.New(rand.NewSource(1)), cfg, 5000)
+			if len(got) != tt.want {
+				t.Fatalf("MonsterAbilities() = %v, want %d abilities", got, tt.want)
+			}
+			for a, strength := range got {
+				if strength <= 0 {
+					t.Errorf("ability %s has strength %v, want above 0", a, strength)
+				}
+			}
+		})
+	}
+}
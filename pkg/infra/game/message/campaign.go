@@ -0,0 +1,56 @@
+package message
+
+import (
+	"infra/game/commons"
+	"infra/game/decision"
+)
+
+// CampaignInform is a message agents send while campaigning before an
+// election.
+type CampaignInform interface {
+	Inform
+	sealedCampaignInform()
+}
+
+// CampaignRequest is a message a voter sends a candidate, which answers with
+// a CampaignInform.
+type CampaignRequest interface {
+	Request
+	sealedCampaignRequest()
+}
+
+// StartCampaign tells every agent the campaign has started.
+type StartCampaign struct{}
+
+// Promise is a candidate's commitment to policies if elected. The campaign
+// log records whether the candidate's manifesto commits to them.
+type Promise struct {
+	Policies  decision.Policies
+	Statement string
+}
+
+// Endorsement is a voter's public support for a candidate.
+type Endorsement struct {
+	Candidate commons.ID
+}
+
+// Question asks a candidate what it stands for.
+type Question struct {
+	Topic string
+}
+
+func (s StartCampaign) sealedMessage()        {}
+func (s StartCampaign) sealedInform()         {}
+func (s StartCampaign) sealedCampaignInform() {}
+
+func (p Promise) sealedMessage()        {}
+func (p Promise) sealedInform()         {}
+func (p Promise) sealedCampaignInform() {}
+
+func (e Endorsement) sealedMessage()        {}
+func (e Endorsement) sealedInform()         {}
+func (e Endorsement) sealedCampaignInform() {}
+
+func (q Question) sealedMessage()         {}
+func (q Question) sealedRequest()         {}
+func (q Question) sealedCampaignRequest() {}
@@ -0,0 +1,157 @@
+package message
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"infra/game/commons"
+	"infra/game/decision"
+	"infra/game/message/proposal"
+)
+
+// JSON encodings, so that the messages agents send can be recorded and
+// replayed. Messages are encoded along with their type, as they are passed
+// around as interfaces.
+
+type proposalJSON[A decision.ProposalAction] struct {
+	ProposalID commons.ProposalID
+	ProposerID commons.ID
+	Rules      commons.ImmutableList[proposal.Rule[A]]
+}
+
+func (p Proposal[A]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(proposalJSON[A]{ProposalID: p.proposalID, ProposerID: p.proposerID, Rules: p.rules})
+}
+
+func (p *Proposal[A]) UnmarshalJSON(data []byte) error {
+	var j proposalJSON[A]
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	*p = Proposal[A]{proposalID: j.ProposalID, proposerID: j.ProposerID, rules: j.Rules}
+	return nil
+}
+
+type arrayInfoJSON struct {
+	Num       int
+	StringArr []string
+}
+
+func (s ArrayInfo) MarshalJSON() ([]byte, error) {
+	return json.Marshal(arrayInfoJSON{Num: s.num, StringArr: s.GetStringArr()})
+}
+
+func (s *ArrayInfo) UnmarshalJSON(data []byte) error {
+	var j arrayInfoJSON
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	*s = NewArrayInfo(j.Num, j.StringArr)
+	return nil
+}
+
+// Typed is a message, or trade message, encoded along with its type.
+type Typed struct {
+	Type string
+	Body json.RawMessage `json:",omitempty"`
+}
+
+// Encode encodes any message an agent can send, or respond with in a trade.
+func Encode(m any) (Typed, error) {
+	var name string
+	switch m.(type) {
+	case nil:
+		return Typed{}, nil
+	case Proposal[decision.FightAction]:
+		name = "FightProposal"
+	case Proposal[decision.LootAction]:
+		name = "LootProposal"
+	case ArrayInfo:
+		name = "ArrayInfo"
+	case Trust:
+		name = "Trust"
+	case StartFight:
+		name = "StartFight"
+	case StartLoot:
+		name = "StartLoot"
+	case StartCampaign:
+		name = "StartCampaign"
+	case Promise:
+		name = "Promise"
+	case Endorsement:
+		name = "Endorsement"
+	case Question:
+		name = "Question"
+	case *TradeNegotiation:
+		name = "TradeNegotiation"
+	case TradeAbstain:
+		name = "TradeAbstain"
+		// the embedded interface is always nil
+		return Typed{Type: name}, nil
+	case TradeRequest:
+		name = "TradeRequest"
+	case TradeBargain:
+		name = "TradeBargain"
+	case TradeAccept:
+		name = "TradeAccept"
+	case TradeReject:
+		name = "TradeReject"
+	default:
+		return Typed{}, fmt.Errorf("message: cannot encode %T", m)
+	}
+	body, err := json.Marshal(m)
+	if err != nil {
+		return Typed{}, fmt.Errorf("message: cannot encode %s: %w", name, err)
+	}
+	return Typed{Type: name, Body: body}, nil
+}
+
+// Decode decodes a message encoded by Encode.
+func Decode(t Typed) (any, error) {
+	switch t.Type {
+	case "":
+		return nil, nil
+	case "FightProposal":
+		return decodeAs[Proposal[decision.FightAction]](t)
+	case "LootProposal":
+		return decodeAs[Proposal[decision.LootAction]](t)
+	case "ArrayInfo":
+		return decodeAs[ArrayInfo](t)
+	case "Trust":
+		return decodeAs[Trust](t)
+	case "StartFight":
+		return StartFight{}, nil
+	case "StartLoot":
+		return decodeAs[StartLoot](t)
+	case "StartCampaign":
+		return StartCampaign{}, nil
+	case "Promise":
+		return decodeAs[Promise](t)
+	case "Endorsement":
+		return decodeAs[Endorsement](t)
+	case "Question":
+		return decodeAs[Question](t)
+	case "TradeNegotiation":
+		return decodeAs[*TradeNegotiation](t)
+	case "TradeAbstain":
+		return TradeAbstain{}, nil
+	case "TradeRequest":
+		return decodeAs[TradeRequest](t)
+	case "TradeBargain":
+		return decodeAs[TradeBargain](t)
+	case "TradeAccept":
+		return decodeAs[TradeAccept](t)
+	case "TradeReject":
+		return decodeAs[TradeReject](t)
+	default:
+		return nil, fmt.Errorf("message: unknown message type %q", t.Type)
+	}
+}
+
+func decodeAs[M any](t Typed) (any, error) {
+	var m M
+	if err := json.Unmarshal(t.Body, &m); err != nil {
+		return nil, fmt.Errorf("message: cannot decode %s: %w", t.Type, err)
+	}
+	return m, nil
+}
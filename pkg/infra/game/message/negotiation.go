@@ -4,8 +4,6 @@ import (
 	"fmt"
 	"infra/game/commons"
 	"infra/game/state"
-
-	"github.com/google/uuid"
 )
 
 type TradeNegotiation struct {
@@ -17,13 +15,13 @@ type TradeNegotiation struct {
 	Condition2 TradeCondition
 }
 
-func NewTradeNegotiation(agentID commons.ID, counterPartyID commons.ID, offer TradeOffer, demand TradeDemand) TradeNegotiation {
+func NewTradeNegotiation(id commons.TradeID, agentID commons.ID, counterPartyID commons.ID, offer TradeOffer, demand TradeDemand) TradeNegotiation {
 	condition := TradeCondition{
 		Offer:  offer,
 		Demand: demand,
 	}
 	return TradeNegotiation{
-		Id:         uuid.New().String(),
+		Id:         id,
 		Agent1:     agentID,
 		Agent2:     counterPartyID,
 		RoundNum:   0,
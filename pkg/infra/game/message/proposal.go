@@ -29,8 +29,8 @@ func (p Proposal[A]) Rules() commons.ImmutableList[proposal.Rule[A]] {
 func (p Proposal[A]) sealedMessage() {
 }
 
-func NewProposal[A decision.ProposalAction](rules commons.ImmutableList[proposal.Rule[A]], proposerID commons.ID) *Proposal[A] {
-	return &Proposal[A]{proposalID: uuid.NewString(), rules: rules, proposerID: proposerID}
+func NewProposal[A decision.ProposalAction](proposalID commons.ProposalID, rules commons.ImmutableList[proposal.Rule[A]], proposerID commons.ID) *Proposal[A] {
+	return &Proposal[A]{proposalID: proposalID, rules: rules, proposerID: proposerID}
 }
 
 func NewProposalInternal[A decision.ProposalAction](proposalID commons.ProposalID, rules commons.ImmutableList[proposal.Rule[A]]) *Proposal[A] {
@@ -0,0 +1,118 @@
+package proposal
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"infra/game/decision"
+)
+
+// JSON encodings, so that proposals can be recorded and replayed.
+
+type conditionKind string
+
+const (
+	andKind         conditionKind = "And"
+	orKind          conditionKind = "Or"
+	comparativeKind conditionKind = "Comparative"
+	defectorKind    conditionKind = "Defector"
+)
+
+type conditionJSON struct {
+	Kind       conditionKind
+	A          *conditionJSON `json:",omitempty"`
+	B          *conditionJSON `json:",omitempty"`
+	Attribute  Attribute      `json:",omitempty"`
+	Comparator Comparator     `json:",omitempty"`
+	Value      Value          `json:",omitempty"`
+}
+
+func encodeCondition(cond Condition) (*conditionJSON, error) {
+	pair := func(kind conditionKind, condA Condition, condB Condition) (*conditionJSON, error) {
+		a, err := encodeCondition(condA)
+		if err != nil {
+			return nil, err
+		}
+		b, err := encodeCondition(condB)
+		if err != nil {
+			return nil, err
+		}
+		return &conditionJSON{Kind: kind, A: a, B: b}, nil
+	}
+
+	switch condT := cond.(type) {
+	case *AndCondition:
+		return pair(andKind, condT.condA, condT.condB)
+	case AndCondition:
+		return pair(andKind, condT.condA, condT.condB)
+	case *OrCondition:
+		return pair(orKind, condT.condA, condT.condB)
+	case OrCondition:
+		return pair(orKind, condT.condA, condT.condB)
+	case *ComparativeCondition:
+		return &conditionJSON{Kind: comparativeKind, Attribute: condT.Attribute, Comparator: condT.Comparator, Value: condT.Value}, nil
+	case ComparativeCondition:
+		return &conditionJSON{Kind: comparativeKind, Attribute: condT.Attribute, Comparator: condT.Comparator, Value: condT.Value}, nil
+	case *DefectorCondition, DefectorCondition:
+		return &conditionJSON{Kind: defectorKind}, nil
+	case nil:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("proposal: cannot encode condition %T", cond)
+	}
+}
+
+func decodeCondition(j *conditionJSON) (Condition, error) {
+	if j == nil {
+		return nil, nil
+	}
+	pair := func() (Condition, Condition, error) {
+		a, err := decodeCondition(j.A)
+		if err != nil {
+			return nil, nil, err
+		}
+		b, err := decodeCondition(j.B)
+		return a, b, err
+	}
+
+	switch j.Kind {
+	case andKind:
+		a, b, err := pair()
+		return NewAndCondition(a, b), err
+	case orKind:
+		a, b, err := pair()
+		return NewOrCondition(a, b), err
+	case comparativeKind:
+		return NewComparativeCondition(j.Attribute, j.Comparator, j.Value), nil
+	case defectorKind:
+		return *NewDefectorCondition(), nil
+	default:
+		return nil, fmt.Errorf("proposal: unknown condition %q", j.Kind)
+	}
+}
+
+type ruleJSON[A decision.ProposalAction] struct {
+	Action    A
+	Condition *conditionJSON
+}
+
+func (r Rule[A]) MarshalJSON() ([]byte, error) {
+	cond, err := encodeCondition(r.condition)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(ruleJSON[A]{Action: r.action, Condition: cond})
+}
+
+func (r *Rule[A]) UnmarshalJSON(data []byte) error {
+	var j ruleJSON[A]
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	cond, err := decodeCondition(j.Condition)
+	if err != nil {
+		return err
+	}
+	*r = Rule[A]{action: j.Action, condition: cond}
+	return nil
+}
@@ -0,0 +1,56 @@
+package rng_test
+
+import (
+	"testing"
+
+	"infra/game/rng"
+)
+
+func TestStreamIsReproducible(t *testing.T) {
+	t.Parallel()
+
+	a := rng.NewSource(42).Stream("loot", 3)
+	b := rng.NewSource(42).Stream("loot", 3)
+	for i := 0; i < 10; i++ {
+		if x, y := a.Int63(), b.Int63(); x != y {
+			t.Fatalf("draw %d differs between identical streams: %d != %d", i, x, y)
+		}
+	}
+}
+
+func TestStreamsAreIndependent(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		seedA int64
+		seedB int64
+		a     []any
+		b     []any
+	}{
+		{name: "different labels", seedA: 1, seedB: 1, a: []any{"fight", 1}, b: []any{"loot", 1}},
+		{name: "different level", seedA: 1, seedB: 1, a: []any{"fight", 1}, b: []any{"fight", 2}},
+		{name: "different seed", seedA: 1, seedB: 2, a: []any{"fight", 1}, b: []any{"fight", 1}},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			a := rng.NewSource(tt.seedA).Stream(tt.a...)
+			b := rng.NewSource(tt.seedB).Stream(tt.b...)
+			if a.Int63() == b.Int63() && a.Int63() == b.Int63() {
+				t.Errorf("streams %v and %v produced the same values", tt.a, tt.b)
+			}
+		})
+	}
+}
+
+func TestNewIDIsReproducible(t *testing.T) {
+	t.Parallel()
+
+	source := rng.NewSource(7)
+	if a, b := rng.NewID(source.Stream("ids")), rng.NewID(source.Stream("ids")); a != b {
+		t.Errorf("NewID() = %s and %s for the same stream", a, b)
+	}
+}
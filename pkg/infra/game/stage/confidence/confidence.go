@@ -1 +1,159 @@
 package confidence
+
+import (
+	"math"
+	"sort"
+	"sync"
+
+	"infra/config"
+	"infra/game/agent"
+	"infra/game/commons"
+	"infra/game/decision"
+	"infra/game/state"
+
+	"golang.org/x/exp/maps"
+)
+
+// Rules are how a no-confidence motion is decided.
+type Rules struct {
+	// Threshold is the percentage of the votes counted that must go against
+	// the leader, strictly exceeded, to oust it.
+	Threshold uint
+	// Quorum is the fraction of the electorate whose votes must be counted
+	// for the motion to stand.
+	Quorum float32
+	// Abstentions is how abstentions are counted.
+	Abstentions config.Abstentions
+	// Weights, unless nil, is how much each agent's vote counts towards the
+	// threshold. Votes of agents missing from it count for nothing, unless
+	// no counted vote counts for anything, when every vote counts once. The
+	// quorum is of votes, however much they count.
+	Weights map[commons.ID]float64
+}
+
+// Result is the outcome of a no-confidence motion.
+type Result struct {
+	// Votes is how each agent voted.
+	Votes map[commons.ID]decision.Intent
+	// Tally is the number of votes cast with each intent.
+	Tally map[decision.Intent]uint
+	// For and Against are the votes counted for and against the leader,
+	// abstentions included as the rules say.
+	For, Against uint
+	// WeightFor and WeightAgainst are how much the votes for and against
+	// the leader count by the rules' weights, the same as For and Against
+	// without them.
+	WeightFor, WeightAgainst float64
+	// Weights is how much each agent's vote counted, nil if every vote
+	// counted once.
+	Weights map[commons.ID]float64
+	// Quorate reports whether enough votes were counted for the motion to
+	// stand.
+	Quorate bool
+	// Passed reports whether the leader, or the role's holder, is ousted.
+	Passed bool
+}
+
+// HandleConfidenceVote polls every agent on the leader, concurrently, and
+// decides the motion by rules.
+func HandleConfidenceVote(gameState *state.State, agents map[commons.ID]agent.Agent, rules Rules) Result {
+	return poll(gameState, agents, rules, func(a agent.Agent, agentState state.AgentState) decision.Intent {
+		return a.HandleNoConfidenceVote(agentState)
+	})
+}
+
+// HandleCabinetVote polls every agent on holder, who holds role in the
+// leader's cabinet, concurrently, and decides the motion by rules.
+func HandleCabinetVote(gameState *state.State, agents map[commons.ID]agent.Agent, role decision.Role, holder commons.ID, rules Rules) Result {
+	return poll(gameState, agents, rules, func(a agent.Agent, agentState state.AgentState) decision.Intent {
+		return a.HandleCabinetConfidenceVote(agentState, role, holder)
+	})
+}
+
+// poll asks every agent for its vote, concurrently, and decides the motion by
+// rules.
+func poll(gameState *state.State, agents map[commons.ID]agent.Agent, rules Rules, ask func(agent.Agent, state.AgentState) decision.Intent) Result {
+	type vote struct {
+		id     commons.ID
+		intent decision.Intent
+	}
+	voteChan := make(chan vote)
+
+	var wg sync.WaitGroup
+	for id, a := range agents {
+		wg.Add(1)
+		go func(id commons.ID, a agent.Agent, agentState state.AgentState) {
+			voteChan <- vote{id: id, intent: ask(a, agentState)}
+			wg.Done()
+		}(id, a, gameState.AgentState[id])
+	}
+
+	go func() {
+		wg.Wait()
+		close(voteChan)
+	}()
+
+	votes := make(map[commons.ID]decision.Intent, len(agents))
+	for v := range voteChan {
+		votes[v.id] = v.intent
+	}
+	return Count(votes, uint(len(agents)), rules)
+}
+
+// Count decides a motion from the votes of an electorate of the given size.
+func Count(votes map[commons.ID]decision.Intent, electorate uint, rules Rules) Result {
+	result := Result{Votes: votes, Tally: make(map[decision.Intent]uint)}
+	for _, intent := range votes {
+		result.Tally[intent]++
+	}
+
+	result.For, result.Against = result.Tally[decision.Positive], result.Tally[decision.Negative]
+	switch rules.Abstentions {
+	case config.AbstainFor:
+		result.For += result.Tally[decision.Abstain]
+	case config.AbstainAgainst:
+		result.Against += result.Tally[decision.Abstain]
+	}
+
+	result.WeightFor, result.WeightAgainst = float64(result.For), float64(result.Against)
+	if rules.Weights != nil {
+		weighFor, weighAgainst := weigh(votes, rules)
+		if weighFor+weighAgainst > 0 {
+			result.WeightFor, result.WeightAgainst = weighFor, weighAgainst
+			result.Weights = rules.Weights
+		}
+	}
+
+	counted := result.For + result.Against
+	// allow for Quorum's float32 rounding, so that a quorum of 0.2 of 10 is 2
+	result.Quorate = float64(counted) >= float64(rules.Quorum)*float64(electorate)-1e-6
+	weight := result.WeightFor + result.WeightAgainst
+	// floored, as the percentage was when votes all counted once
+	result.Passed = result.Quorate && weight > 0 && math.Floor(100*result.WeightAgainst/weight) > float64(rules.Threshold)
+	return result
+}
+
+// weigh sums the weights of the votes counted for and against the leader, in
+// ID order so the sums are the same on every run.
+func weigh(votes map[commons.ID]decision.Intent, rules Rules) (weighFor float64, weighAgainst float64) {
+	ids := maps.Keys(votes)
+	sort.Strings(ids)
+	for _, id := range ids {
+		intent := votes[id]
+		if intent == decision.Abstain {
+			switch rules.Abstentions {
+			case config.AbstainFor:
+				intent = decision.Positive
+			case config.AbstainAgainst:
+				intent = decision.Negative
+			}
+		}
+		switch intent {
+		case decision.Positive:
+			weighFor += rules.Weights[id]
+		case decision.Negative:
+			weighAgainst += rules.Weights[id]
+		}
+	}
+	return weighFor, weighAgainst
+}
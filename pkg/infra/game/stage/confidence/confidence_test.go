@@ -0,0 +1,127 @@
+package confidence_test
+
+import (
+	"testing"
+
+	"infra/config"
+	"infra/game/commons"
+	"infra/game/decision"
+	"infra/game/stage/confidence"
+)
+
+func TestCount(t *testing.T) {
+	t.Parallel()
+
+	votes := map[commons.ID]decision.Intent{
+		"a": decision.Positive,
+		"b": decision.Negative,
+		"c": decision.Negative,
+		"d": decision.Abstain,
+		"e": decision.Abstain,
+		"f": decision.Abstain,
+	}
+	tests := []struct {
+		name        string
+		votes       map[commons.ID]decision.Intent
+		electorate  uint
+		rules       confidence.Rules
+		wantFor     uint
+		wantAgainst uint
+		wantQuorate bool
+		wantPassed  bool
+	}{
+		{
+			name:        "abstentions ignored",
+			votes:       votes,
+			electorate:  6,
+			rules:       confidence.Rules{Threshold: 50},
+			wantFor:     1,
+			wantAgainst: 2,
+			wantQuorate: true,
+			wantPassed:  true,
+		},
+		{
+			name:        "abstentions for the leader",
+			votes:       votes,
+			electorate:  6,
+			rules:       confidence.Rules{Threshold: 50, Abstentions: config.AbstainFor},
+			wantFor:     4,
+			wantAgainst: 2,
+			wantQuorate: true,
+		},
+		{
+			name:        "abstentions against the leader",
+			votes:       votes,
+			electorate:  6,
+			rules:       confidence.Rules{Threshold: 80, Abstentions: config.AbstainAgainst},
+			wantFor:     1,
+			wantAgainst: 5,
+			wantQuorate: true,
+			wantPassed:  true,
+		},
+		{
+			name:        "inquorate",
+			votes:       votes,
+			electorate:  6,
+			rules:       confidence.Rules{Threshold: 50, Quorum: 0.6},
+			wantFor:     1,
+			wantAgainst: 2,
+		},
+		{
+			name:        "quorum met exactly",
+			votes:       votes,
+			electorate:  10,
+			rules:       confidence.Rules{Threshold: 50, Quorum: 0.3},
+			wantFor:     1,
+			wantAgainst: 2,
+			wantQuorate: true,
+			wantPassed:  true,
+		},
+		{
+			name:        "threshold not exceeded",
+			votes:       votes,
+			electorate:  6,
+			rules:       confidence.Rules{Threshold: 66},
+			wantFor:     1,
+			wantAgainst: 2,
+			wantQuorate: true,
+		},
+		{
+			name:        "weighted for the leader",
+			votes:       votes,
+			electorate:  6,
+			rules:       confidence.Rules{Threshold: 50, Weights: map[commons.ID]float64{"a": 3, "b": 1, "c": 1, "d": 10}},
+			wantFor:     1,
+			wantAgainst: 2,
+			wantQuorate: true,
+		},
+		{
+			name:        "weights of no counted vote",
+			votes:       votes,
+			electorate:  6,
+			rules:       confidence.Rules{Threshold: 50, Weights: map[commons.ID]float64{"d": 10}},
+			wantFor:     1,
+			wantAgainst: 2,
+			wantQuorate: true,
+			wantPassed:  true,
+		},
+		{
+			name:        "no votes",
+			votes:       map[commons.ID]decision.Intent{"a": decision.Abstain},
+			electorate:  1,
+			wantQuorate: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := confidence.Count(tt.votes, tt.electorate, tt.rules)
+			if got.For != tt.wantFor || got.Against != tt.wantAgainst || got.Quorate != tt.wantQuorate || got.Passed != tt.wantPassed {
+				t.Errorf("Count() = %d for, %d against, quorate %v, passed %v, want %d, %d, %v, %v",
+					got.For, got.Against, got.Quorate, got.Passed, tt.wantFor, tt.wantAgainst, tt.wantQuorate, tt.wantPassed)
+			}
+		})
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"infra/game/state"
 	"infra/game/tally"
 	"math/rand"
+	"sort"
 
 	"golang.org/x/exp/maps"
 
@@ -80,9 +81,10 @@ func ResolveLootDiscussion(
 	leader agent.Agent,
 	manifesto decision.Manifesto,
 	tally *tally.Tally[decision.LootAction],
+	r *rand.Rand,
 ) map[commons.ID]map[commons.ItemID]struct{} {
 	prop := tally.GetMax()
-	allocation := getAllocation(gs, agentMap, pool, prop)
+	allocation := getAllocation(gs, agentMap, pool, prop, r)
 	// if manifesto.LootDecisionPower() && leader.Strategy != nil {
 	// 	leaderAllocation := leader.Strategy.LootAllocation(*leader.BaseAgent, prop, allocation)
 	// 	iterator := leaderAllocation.Iterator()
@@ -114,11 +116,11 @@ func ResolveLootDiscussion(
 	// }
 }
 
-func getAllocation(gs state.State, agentMap map[commons.ID]agent.Agent, pool *state.LootPool, prop message.Proposal[decision.LootAction]) map[commons.ID]map[commons.ItemID]struct{} {
+func getAllocation(gs state.State, agentMap map[commons.ID]agent.Agent, pool *state.LootPool, prop message.Proposal[decision.LootAction], r *rand.Rand) map[commons.ID]map[commons.ItemID]struct{} {
 	predicate := proposal.ToMultiPredicate(prop.Rules())
 	if predicate == nil {
 		// either leader died or no proposal was made
-		return handleNilLootAllocation(agentMap)
+		return handleNilLootAllocation(agentMap, r)
 	}
 	getsWeapon, getsShield, getsHealthPotion, getsStaminaPotion := demandList(gs, agentMap, predicate)
 	agentToElibilityList := make(map[commons.ID]map[commons.ItemID]struct{})
@@ -195,13 +197,13 @@ func demandList(
 	return getsWeapon, getsShield, getsHealthPotion, getsStaminaPotion
 }
 
-func handleNilLootAllocation(agentMap map[commons.ID]agent.Agent) map[commons.ID]map[commons.ItemID]struct{} {
+func handleNilLootAllocation(agentMap map[commons.ID]agent.Agent, r *rand.Rand) map[commons.ID]map[commons.ItemID]struct{} {
 	wantedItems := make(map[commons.ItemID]map[commons.ID]struct{})
 	for id, a := range agentMap {
 		wantedLoot := a.Strategy.LootActionNoProposal(*agentMap[id].BaseAgent)
 		addWantedLootToItemAllocMap(wantedLoot, wantedItems, id)
 	}
-	allocations := formAllocationFromConflicts(wantedItems)
+	allocations := formAllocationFromConflicts(wantedItems, r)
 
 	return (allocations)
 }
@@ -214,14 +216,17 @@ func handleNilLootAllocation(agentMap map[commons.ID]agent.Agent) map[commons.ID
 // 	return commons.MapToImmutable(mMapped)
 // }
 
-func formAllocationFromConflicts(wantedItems map[commons.ItemID]map[commons.ID]struct{}) map[commons.ID]map[commons.ItemID]struct{} {
+func formAllocationFromConflicts(wantedItems map[commons.ItemID]map[commons.ID]struct{}, r *rand.Rand) map[commons.ID]map[commons.ItemID]struct{} {
 	allocations := make(map[commons.ID]map[commons.ItemID]struct{})
-	for item, agentSet := range wantedItems {
-		agents := maps.Keys(agentSet)
+	items := maps.Keys(wantedItems)
+	sort.Strings(items)
+	for _, item := range items {
+		agents := maps.Keys(wantedItems[item])
+		sort.Strings(agents)
 		if len(agents) > 0 {
 			// for a number of iterations
 			for i := 0; i < len(agents); i++ {
-				randID := rand.Intn(len(agents))
+				randID := r.Intn(len(agents))
 				// choose a random agent, if they are not in the allocation, then assign them the item
 				if _, ok := allocations[agents[randID]]; !ok {
 					m := make(map[commons.ItemID]struct{})
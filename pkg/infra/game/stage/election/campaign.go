@@ -0,0 +1,110 @@
+package election
+
+import (
+	"sort"
+
+	"infra/game/agent"
+	"infra/game/commons"
+	"infra/game/decision"
+	"infra/game/message"
+	"infra/game/state"
+	"infra/logging"
+
+	"github.com/google/uuid"
+)
+
+// campaignRecord is what was said in a campaign, with the policies of each
+// promise so they can be checked against the manifestos stood on at the vote.
+type campaignRecord struct {
+	log      *logging.CampaignLog
+	promised []decision.Policies
+	endorsed map[commons.ID]map[commons.ID]bool
+	seen     map[uuid.UUID]bool
+}
+
+// campaign runs the campaign before an election between the candidates in
+// params: every agent is told it has started, then candidates make promises
+// and voters ask questions and endorse candidates until they fall quiet.
+func campaign(gameState *state.State, agents map[commons.ID]agent.Agent, params *decision.ElectionParams, rounds agent.Rounds) *campaignRecord {
+	sent := make(map[commons.ID]*[]agent.Sent, len(agents))
+	for id := range agents {
+		sent[id] = new([]agent.Sent)
+	}
+
+	c := &campaignRecord{
+		log:      &logging.CampaignLog{Endorsements: make(map[commons.ID][]commons.ID)},
+		endorsed: make(map[commons.ID]map[commons.ID]bool),
+		seen:     make(map[uuid.UUID]bool),
+	}
+	round := uint(0)
+
+	start := *message.NewTaggedMessage("server", message.StartCampaign{}, uuid.Nil)
+	rounds.Run(agents, []message.TaggedMessage{start},
+		func(a *agent.Agent, inbox []message.TaggedMessage) {
+			a.HandleCampaign(gameState.AgentState[a.BaseAgent.ID()], params, inbox)
+			*sent[a.BaseAgent.ID()] = a.BaseAgent.Outbox()
+		},
+		func(ids []commons.ID) {
+			for _, id := range ids {
+				for _, s := range *sent[id] {
+					c.record(params, id, s, round)
+				}
+				*sent[id] = nil
+			}
+			round++
+		},
+	)
+
+	for _, endorsers := range c.log.Endorsements {
+		sort.Strings(endorsers)
+	}
+	return c
+}
+
+// record logs what sender said in round, if it was a promise from a
+// candidate, an endorsement of one or a question to one.
+func (c *campaignRecord) record(params *decision.ElectionParams, sender commons.ID, s agent.Sent, round uint) {
+	// a broadcast is sent to every agent under the same message ID
+	if c.seen[s.Message.MID()] {
+		return
+	}
+	c.seen[s.Message.MID()] = true
+
+	isCandidate := func(id commons.ID) bool {
+		_, ok := params.CandidateList().Get(id)
+		return ok
+	}
+	switch m := s.Message.Message().(type) {
+	case message.Promise:
+		if isCandidate(sender) {
+			c.log.Promises = append(c.log.Promises, logging.PromiseLog{
+				Candidate:   sender,
+				Round:       round,
+				Statement:   m.Statement,
+				PoliciesLog: m.Policies.Log(),
+			})
+			c.promised = append(c.promised, m.Policies)
+		}
+	case message.Endorsement:
+		if isCandidate(m.Candidate) && !c.endorsed[m.Candidate][sender] {
+			if c.endorsed[m.Candidate] == nil {
+				c.endorsed[m.Candidate] = make(map[commons.ID]bool)
+			}
+			c.endorsed[m.Candidate][sender] = true
+			c.log.Endorsements[m.Candidate] = append(c.log.Endorsements[m.Candidate], sender)
+		}
+	case message.Question:
+		if isCandidate(s.To) {
+			c.log.Questions++
+		}
+	}
+}
+
+// keep marks the promises kept by the manifestos the candidates stood on at
+// the vote.
+func (c *campaignRecord) keep(candidates map[commons.ID]decision.Manifesto) {
+	for i, promised := range c.promised {
+		p := &c.log.Promises[i]
+		p.Kept = candidates[p.Candidate].Policies().Keeps(promised)
+	}
+}
@@ -0,0 +1,159 @@
+package election
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+
+	"infra/game/commons"
+	"infra/game/decision"
+	"infra/logging"
+
+	"golang.org/x/exp/maps"
+)
+
+// Completion rules for a Copeland election without a Condorcet winner.
+const (
+	CopelandCompletion       = "copeland"
+	CopelandRandomCompletion = "copeland, random"
+)
+
+// Copeland counts ballots, up to numberOfPreferences each, head to head: a
+// ballot prefers each ranked candidate to those ranked below it and to those
+// it leaves out. The Condorcet winner, beating every other candidate, is
+// elected if there is one. Otherwise the candidates' wins form a cycle, which
+// is logged, and the candidate with the most wins, counting ties as half, is
+// elected, ties broken at random.
+func Copeland(ballots []decision.Ballot, allAgents []commons.ID, numberOfPreferences uint, r *rand.Rand) (commons.ID, *logging.CondorcetCount) {
+	set := make(map[commons.ID]bool)
+	for _, ballot := range ballots {
+		for i, candidate := range ballot {
+			if uint(i) < numberOfPreferences {
+				set[candidate] = true
+			}
+		}
+	}
+	if len(set) == 0 {
+		logging.Log(logging.Info, nil, "No votes cast. Random agent selected as leader")
+		return allAgents[0], nil
+	}
+	candidates := maps.Keys(set)
+	sort.Strings(candidates)
+
+	count := &logging.CondorcetCount{
+		Pairwise: make(map[commons.ID]map[commons.ID]uint, len(candidates)),
+		Copeland: make(map[commons.ID]float64, len(candidates)),
+	}
+	for _, candidate := range candidates {
+		count.Pairwise[candidate] = make(map[commons.ID]uint, len(candidates)-1)
+		for _, other := range candidates {
+			if other != candidate {
+				count.Pairwise[candidate][other] = 0
+			}
+		}
+	}
+	for _, ballot := range ballots {
+		if uint(len(ballot)) > numberOfPreferences {
+			ballot = ballot[:numberOfPreferences]
+		}
+		ranked := make(map[commons.ID]bool, len(ballot))
+		for _, candidate := range ballot {
+			ranked[candidate] = true
+			for other := range count.Pairwise[candidate] {
+				if !ranked[other] {
+					count.Pairwise[candidate][other]++
+				}
+			}
+		}
+	}
+
+	for _, candidate := range candidates {
+		for _, other := range candidates {
+			switch {
+			case other == candidate:
+			case beats(count.Pairwise, candidate, other):
+				count.Copeland[candidate]++
+			case !beats(count.Pairwise, other, candidate):
+				count.Copeland[candidate] += 0.5
+			}
+		}
+	}
+
+	for _, candidate := range candidates {
+		if count.Copeland[candidate] == float64(len(candidates)-1) {
+			logging.Log(logging.Info, nil, fmt.Sprintf("New leader has been elected %s as the Condorcet winner", candidate))
+			return candidate, count
+		}
+	}
+
+	count.Cycle = findCycle(candidates, count.Pairwise)
+	var best float64
+	var winners []commons.ID
+	for _, candidate := range candidates {
+		switch score := count.Copeland[candidate]; {
+		case score > best || len(winners) == 0:
+			best = score
+			winners = []commons.ID{candidate}
+		case score == best:
+			winners = append(winners, candidate)
+		}
+	}
+	winner := winners[0]
+	count.Completion = CopelandCompletion
+	if len(winners) > 1 {
+		winner = winners[r.Intn(len(winners))]
+		count.Completion = CopelandRandomCompletion
+	}
+	logging.Log(
+		logging.Info,
+		logging.LogField{"cycle": count.Cycle, "completion": count.Completion},
+		fmt.Sprintf("No Condorcet winner, new leader has been elected %s with a Copeland score of %v", winner, best),
+	)
+
+	return winner, count
+}
+
+// beats reports whether more ballots rank a above b than b above a.
+func beats(pairwise map[commons.ID]map[commons.ID]uint, a, b commons.ID) bool {
+	return pairwise[a][b] > pairwise[b][a]
+}
+
+// findCycle returns the first cycle of head-to-head wins found searching from
+// each candidate in order, or nil if there is none.
+func findCycle(candidates []commons.ID, pairwise map[commons.ID]map[commons.ID]uint) []commons.ID {
+	done := make(map[commons.ID]bool)
+	var path []commons.ID
+	onPath := make(map[commons.ID]int)
+
+	var visit func(commons.ID) []commons.ID
+	visit = func(candidate commons.ID) []commons.ID {
+		onPath[candidate] = len(path)
+		path = append(path, candidate)
+		for _, other := range candidates {
+			if !beats(pairwise, candidate, other) {
+				continue
+			}
+			if i, ok := onPath[other]; ok {
+				return append([]commons.ID(nil), path[i:]...)
+			}
+			if !done[other] {
+				if cycle := visit(other); cycle != nil {
+					return cycle
+				}
+			}
+		}
+		delete(onPath, candidate)
+		path = path[:len(path)-1]
+		done[candidate] = true
+		return nil
+	}
+
+	for _, candidate := range candidates {
+		if !done[candidate] {
+			if cycle := visit(candidate); cycle != nil {
+				return cycle
+			}
+		}
+	}
+	return nil
+}
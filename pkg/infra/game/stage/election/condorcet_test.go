@@ -0,0 +1,97 @@
+package election_test
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"infra/game/commons"
+	"infra/game/decision"
+	"infra/game/stage/election"
+)
+
+func TestCopeland(t *testing.T) {
+	t.Parallel()
+
+	agents := []commons.ID{"a", "b", "c", "d"}
+	tests := []struct {
+		name        string
+		ballots     []decision.Ballot
+		preferences uint
+		want        []commons.ID
+		cycle       []commons.ID
+		completion  string
+	}{
+		{
+			name:        "condorcet winner",
+			ballots:     []decision.Ballot{{"a", "b", "c"}, {"b", "a", "c"}, {"a", "c", "b"}},
+			preferences: 3,
+			want:        []commons.ID{"a"},
+		},
+		{
+			name:        "unranked candidates lose to ranked ones",
+			ballots:     []decision.Ballot{{"c", "b"}, {"b", "c"}, {"a", "b"}},
+			preferences: 3,
+			want:        []commons.ID{"b"},
+		},
+		{
+			name:        "preferences beyond the limit are ignored",
+			ballots:     []decision.Ballot{{"c", "b"}, {"a", "b"}, {"b", "a"}},
+			preferences: 1,
+			want:        []commons.ID{"a", "b", "c"},
+			completion:  election.CopelandRandomCompletion,
+		},
+		{
+			name:        "cycle",
+			ballots:     []decision.Ballot{{"a", "b", "c"}, {"b", "c", "a"}, {"c", "a", "b"}},
+			preferences: 3,
+			want:        []commons.ID{"a", "b", "c"},
+			cycle:       []commons.ID{"a", "b", "c"},
+			completion:  election.CopelandRandomCompletion,
+		},
+		{
+			name:        "ties without a cycle",
+			ballots:     []decision.Ballot{{"d", "b", "a", "c"}, {"c", "a", "b", "d"}, {"b", "a", "c", "d"}, {"a", "c", "d", "b"}},
+			preferences: 4,
+			want:        []commons.ID{"a"},
+			completion:  election.CopelandCompletion,
+		},
+		{
+			name:        "no ballots",
+			ballots:     []decision.Ballot{{}, {}},
+			preferences: 2,
+			want:        []commons.ID{"a"},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, count := election.Copeland(tt.ballots, agents, tt.preferences, rand.New(rand.NewSource(1)))
+			found := false
+			for _, want := range tt.want {
+				found = found || got == want
+			}
+			if !found {
+				t.Errorf("Copeland() = %s, want one of %q", got, tt.want)
+			}
+			if count == nil {
+				if len(tt.ballots[0]) > 0 {
+					t.Fatal("Copeland() count is nil")
+				}
+				return
+			}
+			if !reflect.DeepEqual(count.Cycle, tt.cycle) || count.Completion != tt.completion {
+				t.Errorf("Copeland() cycle %q completed by %q, want %q completed by %q", count.Cycle, count.Completion, tt.cycle, tt.completion)
+			}
+			for a, row := range count.Pairwise {
+				for b, n := range row {
+					if total := n + count.Pairwise[b][a]; total > uint(len(tt.ballots)) {
+						t.Errorf("Copeland() counts %d ballots between %s and %s, want at most %d", total, a, b, len(tt.ballots))
+					}
+				}
+			}
+		})
+	}
+}
@@ -1,23 +1,50 @@
 package election
 
 import (
+	"math/rand"
+	"sort"
 	"sync"
 
 	"infra/game/agent"
 	"infra/game/commons"
 	"infra/game/decision"
 	"infra/game/state"
+	"infra/logging"
 )
 
-func HandleElection(state *state.State, agents map[commons.ID]agent.Agent, strategy decision.VotingStrategy, numberOfPreferences uint) (
-	commons.ID, decision.Manifesto,
-) {
+type agentBallot struct {
+	commons.ID
+	decision.Ballot
+	// Scores is the ballot cast in approval and score voting.
+	Scores decision.Scores
+}
+
+// Rules are how a leader election is held.
+type Rules struct {
+	Strategy decision.VotingStrategy
+	// Preferences is the most candidates a ranked ballot counts.
+	Preferences uint
+	// MaxScore is the most a score voting ballot gives a candidate.
+	MaxScore uint
+	// Campaign, unless nil, are the rounds the agents campaign in before
+	// they vote.
+	Campaign *agent.Rounds
+	// Weights, unless nil, is how much each agent's ballot counts in
+	// plurality and Borda count elections. Ballots of agents missing from it
+	// count for nothing, unless no ballot counts for anything, when every
+	// ballot counts once.
+	Weights map[commons.ID]float64
+}
+
+// HandleElection elects a leader by rules, returning the full count.
+func HandleElection(gameState *state.State, agents map[commons.ID]agent.Agent, rules Rules, r *rand.Rand) *decision.ElectionResult {
+	strategy, numberOfPreferences, maxScore := rules.Strategy, rules.Preferences, rules.MaxScore
 	// Get manifestos from agents
 	agentManifestos := make(map[commons.ID]decision.Manifesto)
 
 	for id, a := range agents {
-		// agentManifestos[id] = *a.SubmitManifesto(state.AgentState[id])
-		manifesto := *a.SubmitManifesto(state.AgentState[id])
+		// agentManifestos[id] = *a.SubmitManifesto(gameState.AgentState[id])
+		manifesto := *a.SubmitManifesto(gameState.AgentState[id])
 		// if term is 0, then remove the agent, as no manifesto has been submitted.
 		if manifesto.TermLength() > 0 {
 			agentManifestos[id] = manifesto
@@ -32,53 +59,182 @@ func HandleElection(state *state.State, agents map[commons.ID]agent.Agent, strat
 		agentIDs[i] = k
 		i++
 	}
+	sort.Strings(agentIDs)
 
-	ballots := make([]decision.Ballot, 0)
+	params := decision.NewElectionParams(agentManifestos, strategy, numberOfPreferences, maxScore)
 
-	ballotChan := make(chan decision.Ballot)
+	var said *campaignRecord
+	if rules.Campaign != nil {
+		said = campaign(gameState, agents, params, *rules.Campaign)
+	}
 
-	params := decision.NewElectionParams(agentManifestos, strategy, numberOfPreferences)
+	ballotMap := collectBallots(gameState, agents, func(a agent.Agent, agentState state.AgentState) agentBallot {
+		if strategy.Scored() {
+			return agentBallot{ID: a.ID(), Scores: a.HandleScoredElection(agentState, params)}
+		}
+		return agentBallot{ID: a.ID(), Ballot: a.HandleElection(agentState, params)}
+	})
+	result := &decision.ElectionResult{
+		Strategy:   strategy,
+		Candidates: make(map[commons.ID]decision.Manifesto, len(agentManifestos)),
+		Electorate: uint(len(agents)),
+	}
+	for id, manifesto := range agentManifestos {
+		result.Candidates[id] = manifesto
+	}
+	ballots := make([]decision.Ballot, 0, len(ballotMap))
+	scored := make([]decision.Scores, 0, len(ballotMap))
+	voters := make([]commons.ID, 0, len(ballotMap))
+	for _, id := range agentIDs {
+		if ballot, ok := ballotMap[id]; ok {
+			ballots = append(ballots, ballot.Ballot)
+			scored = append(scored, ballot.Scores)
+			voters = append(voters, id)
+			if len(ballot.Ballot) > 0 || len(ballot.Scores) > 0 {
+				result.Ballots++
+			}
+		}
+	}
+	result.Abstentions = result.Electorate - result.Ballots
+	weights := ballotWeights(ballots, voters, rules.Weights)
+	if weights != nil {
+		result.Weights = make(map[commons.ID]float64, len(voters))
+		for i, id := range voters {
+			result.Weights[id] = weights[i]
+		}
+	}
 
-	var wg sync.WaitGroup
-	for id, a := range agents {
-		wg.Add(1)
-		startAgentElectionHandlers(state.AgentState[id], a, params, ballotChan, &wg)
+	switch strategy {
+	case decision.VotingStrategy(decision.BordaCount):
+		result.Winner, result.Tally = BordaCount(ballots, weights, agentIDs, r)
+	case decision.VotingStrategy(decision.InstantRunoff):
+		result.Winner, result.Rounds = InstantRunoff(ballots, agentIDs, numberOfPreferences)
+		if len(result.Rounds) > 0 {
+			result.Tally = tally(result.Rounds[len(result.Rounds)-1].Votes)
+		}
+	case decision.VotingStrategy(decision.Copeland):
+		result.Winner, result.Condorcet = Copeland(ballots, agentIDs, numberOfPreferences, r)
+		if result.Condorcet != nil {
+			result.Tally = result.Condorcet.Copeland
+		}
+	case decision.VotingStrategy(decision.TwoRound):
+		twoRound(gameState, agents, agentIDs, ballotMap, params, result, r)
+	case decision.VotingStrategy(decision.ApprovalVoting):
+		var totals map[commons.ID]uint
+		result.Winner, totals = ScoreCount(scored, agentIDs, 1, r)
+		result.Tally = tally(totals)
+	case decision.VotingStrategy(decision.ScoreVoting):
+		var totals map[commons.ID]uint
+		result.Winner, totals = ScoreCount(scored, agentIDs, maxScore, r)
+		result.Tally = tally(totals)
+	default:
+		result.Winner, result.Tally = singleChoicePlurality(ballots, weights, agentIDs, r)
+	}
+	result.Manifesto = result.Candidates[result.Winner]
+	result.Margin = margin(result.Tally, result.Winner)
+	if said != nil {
+		said.keep(result.Candidates)
+		result.Campaign = said.log
 	}
 
-	go func(group *sync.WaitGroup) {
-		group.Wait()
-		close(ballotChan)
-	}(&wg)
+	return result
+}
 
-	for ballot := range ballotChan {
-		ballots = append(ballots, ballot)
+// ballotWeights is how much each of the ballots, cast by voters, counts by
+// weights, or nil if every ballot counts once.
+func ballotWeights(ballots []decision.Ballot, voters []commons.ID, weights map[commons.ID]float64) []float64 {
+	if weights == nil {
+		return nil
 	}
+	counted := make([]float64, len(ballots))
+	total := 0.0
+	for i, id := range voters {
+		counted[i] = weights[id]
+		if len(ballots[i]) > 0 {
+			total += counted[i]
+		}
+	}
+	if total <= 0 {
+		return nil
+	}
+	return counted
+}
 
-	switch strategy {
-	case decision.VotingStrategy(decision.SingleChoicePlurality):
-		winningID := singleChoicePlurality(ballots, agentIDs)
-		winningManifesto := agentManifestos[winningID]
-
-		return winningID, winningManifesto
+// twoRound elects the candidate with a majority of first preferences or,
+// failing that, the winner of a runoff between the top two, who may revise
+// their manifestos in result before agents vote again.
+func twoRound(gameState *state.State, agents map[commons.ID]agent.Agent, agentIDs []commons.ID,
+	first map[commons.ID]agentBallot, params *decision.ElectionParams, result *decision.ElectionResult, r *rand.Rand,
+) {
+	ballots := make([]decision.Ballot, 0, len(first))
+	for _, id := range agentIDs {
+		if ballot, ok := first[id]; ok {
+			ballots = append(ballots, ballot.Ballot)
+		}
+	}
 
-	case decision.VotingStrategy(decision.BordaCount):
-		winningID := BordaCount(ballots, agentIDs)
-		winningManifesto := agentManifestos[winningID]
+	winner, finalists, round := FirstRound(ballots, agentIDs, r)
+	result.Rounds = []logging.RunoffRound{round}
+	if winner != "" {
+		result.Winner, result.Tally = winner, tally(round.Votes)
+		return
+	}
 
-		return winningID, winningManifesto
-	default:
-		winningID := singleChoicePlurality(ballots, agentIDs)
-		winningManifesto := agentManifestos[winningID]
+	finalistManifestos := make(map[commons.ID]decision.Manifesto, len(finalists))
+	for _, id := range finalists {
+		finalistManifestos[id] = result.Candidates[id]
+	}
+	finalParams := decision.NewElectionParams(finalistManifestos, params.Strategy(), params.NumberOfPreferences(), params.MaxScore())
+	for _, id := range finalists {
+		a := agents[id]
+		revised := a.ReviseManifesto(gameState.AgentState[id], result.Candidates[id], finalParams)
+		if revised != result.Candidates[id] {
+			finalistManifestos[id] = revised
+			result.Candidates[id] = revised
+			result.Revised = append(result.Revised, id)
+		}
+	}
+	if len(result.Revised) > 0 {
+		finalParams = decision.NewElectionParams(finalistManifestos, params.Strategy(), params.NumberOfPreferences(), params.MaxScore())
+	}
 
-		return winningID, winningManifesto
+	second := collectBallots(gameState, agents, func(a agent.Agent, agentState state.AgentState) agentBallot {
+		return agentBallot{ID: a.ID(), Ballot: a.HandleRunoff(agentState, finalParams, first[a.ID()].Ballot)}
+	})
+	ballots = ballots[:0]
+	for _, id := range agentIDs {
+		if ballot, ok := second[id]; ok {
+			ballots = append(ballots, ballot.Ballot)
+		}
 	}
+	result.Winner, round = Runoff(ballots, finalists, r)
+	result.Rounds = append(result.Rounds, round)
+	result.Tally = tally(round.Votes)
 }
 
-// Create channel to a specific agent.
-func startAgentElectionHandlers(agentState state.AgentState, a agent.Agent, params *decision.ElectionParams, dChan chan<- decision.Ballot, wg *sync.WaitGroup) {
-	go func(group *sync.WaitGroup) {
-		dChan <- a.HandleElection(agentState, params)
+// collectBallots asks each agent to vote concurrently, returning their
+// ballots by ID.
+func collectBallots(gameState *state.State, agents map[commons.ID]agent.Agent, vote func(agent.Agent, state.AgentState) agentBallot) map[commons.ID]agentBallot {
+	ballotChan := make(chan agentBallot)
+
+	var wg sync.WaitGroup
+	for id, a := range agents {
+		wg.Add(1)
+		go func(a agent.Agent, agentState state.AgentState) {
+			ballotChan <- vote(a, agentState)
+			wg.Done()
+		}(a, gameState.AgentState[id])
+	}
+
+	go func() {
+		wg.Wait()
+		close(ballotChan)
+	}()
 
-		group.Done()
-	}(wg)
+	// ballots arrive in whatever order the agents finish, the caller counts them in ID order
+	ballots := make(map[commons.ID]agentBallot, len(agents))
+	for ballot := range ballotChan {
+		ballots[ballot.ID] = ballot
+	}
+	return ballots
 }
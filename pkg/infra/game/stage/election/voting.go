@@ -3,10 +3,13 @@ package election
 import (
 	"fmt"
 	"math/rand"
+	"sort"
 
 	"infra/game/commons"
 	"infra/game/decision"
 	"infra/logging"
+
+	"golang.org/x/exp/maps"
 )
 
 /*
@@ -22,21 +25,26 @@ import (
 	4. Borda Count
 	5. Instant Runoff
 	6. Approval
-	7. Copeland Scoring
+	7. Score
+	8. Copeland Scoring (condorcet.go)
 */
 
-func singleChoicePlurality(ballots []decision.Ballot, allAgents []commons.ID) commons.ID {
+// singleChoicePlurality counts each ballot for its first preference, weighing
+// ballot i by weights[i], or once if weights is nil.
+func singleChoicePlurality(ballots []decision.Ballot, weights []float64, allAgents []commons.ID, r *rand.Rand) (commons.ID, map[commons.ID]float64) {
 	// Count number of votes collected for each candidate
-	votes := make(map[commons.ID]uint)
+	votes := make(map[commons.ID]float64)
+	total := 0.0
 
-	for _, ballot := range ballots {
+	for i, ballot := range ballots {
 		if len(ballot) > 0 {
-			votes[ballot[0]]++
+			votes[ballot[0]] += weight(weights, i)
+			total += weight(weights, i)
 		}
 	}
 
 	// Find the candidate(s) with max number of votes
-	var maxNumVotes uint
+	var maxNumVotes float64
 	winners := make([]commons.ID, 0)
 
 	for agentID, numVotes := range votes {
@@ -56,40 +64,51 @@ func singleChoicePlurality(ballots []decision.Ballot, allAgents []commons.ID) co
 			nil,
 			"No votes cast. Random agent selected as leader",
 		)
-		return allAgents[0]
+		return allAgents[0], votes
 	} else if len(winners) > 1 {
 		logging.Log(
 			logging.Info,
 			logging.LogField{"winners": winners},
 			"Multiple candidates with a winning number of votes",
 		)
-		randIdx := rand.Intn(len(winners))
+		sort.Strings(winners)
+		randIdx := r.Intn(len(winners))
 		winner = winners[uint(randIdx)]
 	} else {
 		winner = winners[0]
 	}
 
-	pct := 100 * maxNumVotes / uint(len(ballots))
-	logging.Log(logging.Info, nil, fmt.Sprintf("New leader has been elected %s with %d of the vote", winner, pct))
+	pct := 100 * maxNumVotes / total
+	logging.Log(logging.Info, nil, fmt.Sprintf("New leader has been elected %s with %.0f%% of the vote", winner, pct))
 
-	return winner
+	return winner, votes
+}
+
+// weight is how much ballot i counts by weights, once if weights is nil.
+func weight(weights []float64, i int) float64 {
+	if weights == nil {
+		return 1
+	}
+	return weights[i]
 }
 
 // BordaCount
 // 1. ignore empty ballots
 // 2. assume points shared if not shown in non-empty ballots
 // 3. randomly select one if multiple agents get the max score.
-func BordaCount(ballots []decision.Ballot, aliveAgentIDs []commons.ID) commons.ID {
+// 4. weigh ballot i's points by weights[i], if weights is not nil.
+func BordaCount(ballots []decision.Ballot, weights []float64, aliveAgentIDs []commons.ID, r *rand.Rand) (commons.ID, map[commons.ID]float64) {
 	N := len(aliveAgentIDs)
 	updated := make(map[commons.ID]bool)
 	scores := make(map[commons.ID]float64)
 
 	// Fill scores
-	for _, ballot := range ballots {
+	for i, ballot := range ballots {
 		// Ignore empty ballot
 		if len(ballot) < 1 {
 			continue
 		}
+		w := weight(weights, i)
 
 		// Reset updated to false for all agents
 		for _, id := range aliveAgentIDs {
@@ -100,7 +119,7 @@ func BordaCount(ballots []decision.Ballot, aliveAgentIDs []commons.ID) commons.I
 		K := 0
 		for idx, candidateID := range ballot {
 			K = idx
-			scores[candidateID] += float64(N) - float64(K) + 1
+			scores[candidateID] += w * (float64(N) - float64(K) + 1)
 			updated[candidateID] = true
 		}
 
@@ -118,19 +137,25 @@ func BordaCount(ballots []decision.Ballot, aliveAgentIDs []commons.ID) commons.I
 			"remaining candidates",
 		)
 
-		sharedScore := (float64(N) - float64(K) + 1) / float64(remaining)
-		for candidateID := range updated {
-			scores[candidateID] += sharedScore
+		// a complete ballot leaves no points to share
+		if remaining == 0 {
+			continue
+		}
+		sharedScore := w * (float64(N) - float64(K) + 1) / float64(remaining)
+		for candidateID, isUpdated := range updated {
+			if !isUpdated {
+				scores[candidateID] += sharedScore
+			}
 		}
 	}
 
-	winner, score := FindBordaCountWinner(scores)
+	winner, score := FindBordaCountWinner(scores, r)
 	logging.Log(logging.Info, nil, fmt.Sprintf("New leader has been elected %s with BC %f", winner, score))
 
-	return winner
+	return winner, scores
 }
 
-func FindBordaCountWinner(scores map[commons.ID]float64) (commons.ID, float64) {
+func FindBordaCountWinner(scores map[commons.ID]float64, r *rand.Rand) (commons.ID, float64) {
 	// Find max score
 	winner := ""
 	maxScore := 0.0
@@ -155,7 +180,8 @@ func FindBordaCountWinner(scores map[commons.ID]float64) (commons.ID, float64) {
 			logging.LogField{"winners": winners},
 			"Multiple candidates with a winning number of votes",
 		)
-		randIdx := rand.Intn(len(winners))
+		sort.Strings(winners)
+		randIdx := r.Intn(len(winners))
 		winner = winners[uint(randIdx)]
 	} else {
 		if len(winners) > 0 {
@@ -165,3 +191,207 @@ func FindBordaCountWinner(scores map[commons.ID]float64) (commons.ID, float64) {
 
 	return winner, maxScore
 }
+
+// InstantRunoff counts each ballot, up to numberOfPreferences, for its
+// highest ranked candidate still standing, eliminating the candidate with the
+// fewest votes round by round until one holds a majority of the ballots not
+// yet exhausted. Ties for elimination go against the candidate with fewer
+// votes in the latest earlier round that separates them, and then the later
+// ID.
+func InstantRunoff(ballots []decision.Ballot, allAgents []commons.ID, numberOfPreferences uint) (commons.ID, []logging.RunoffRound) {
+	standing := make(map[commons.ID]bool)
+	ranked := make([]decision.Ballot, len(ballots))
+	for i, ballot := range ballots {
+		if uint(len(ballot)) > numberOfPreferences {
+			ballot = ballot[:numberOfPreferences]
+		}
+		ranked[i] = ballot
+		for _, candidate := range ballot {
+			standing[candidate] = true
+		}
+	}
+	if len(standing) == 0 {
+		logging.Log(logging.Info, nil, "No votes cast. Random agent selected as leader")
+		return allAgents[0], nil
+	}
+
+	var rounds []logging.RunoffRound
+	for {
+		round := logging.RunoffRound{Votes: make(map[commons.ID]uint, len(standing))}
+		for candidate := range standing {
+			round.Votes[candidate] = 0
+		}
+		for _, ballot := range ranked {
+			counted := false
+			for _, candidate := range ballot {
+				if standing[candidate] {
+					round.Votes[candidate]++
+					counted = true
+					break
+				}
+			}
+			if !counted {
+				round.Exhausted++
+			}
+		}
+
+		candidates := maps.Keys(standing)
+		sort.Strings(candidates)
+		active := uint(len(ballots)) - round.Exhausted
+		for _, candidate := range candidates {
+			if 2*round.Votes[candidate] > active || len(candidates) == 1 {
+				rounds = append(rounds, round)
+				logging.Log(logging.Info, nil, fmt.Sprintf("New leader has been elected %s after %d instant runoff rounds", candidate, len(rounds)))
+				return candidate, rounds
+			}
+		}
+
+		// eliminate the last candidate, ordered by votes this round and then
+		// in each earlier round
+		history := append(rounds, round)
+		sort.SliceStable(candidates, func(i, j int) bool {
+			for r := len(history) - 1; r >= 0; r-- {
+				vi, vj := history[r].Votes[candidates[i]], history[r].Votes[candidates[j]]
+				if vi != vj {
+					return vi > vj
+				}
+			}
+			return candidates[i] < candidates[j]
+		})
+		round.Eliminated = candidates[len(candidates)-1]
+		delete(standing, round.Eliminated)
+		rounds = append(rounds, round)
+	}
+}
+
+// ScoreCount totals the scores ballots give each candidate, each capped at
+// maxScore, and elects the candidate with the highest total. Approval ballots
+// are counted with a maxScore of 1. Ties are broken at random.
+func ScoreCount(ballots []decision.Scores, allAgents []commons.ID, maxScore uint, r *rand.Rand) (commons.ID, map[commons.ID]uint) {
+	totals := make(map[commons.ID]uint)
+	for _, ballot := range ballots {
+		for candidate, score := range ballot {
+			if score > maxScore {
+				score = maxScore
+			}
+			totals[candidate] += score
+		}
+	}
+
+	candidates := maps.Keys(totals)
+	sort.Strings(candidates)
+	var best uint
+	var winners []commons.ID
+	for _, candidate := range candidates {
+		switch total := totals[candidate]; {
+		case total > best:
+			best = total
+			winners = []commons.ID{candidate}
+		case total == best && total > 0:
+			winners = append(winners, candidate)
+		}
+	}
+	if len(winners) == 0 {
+		logging.Log(logging.Info, nil, "No votes cast. Random agent selected as leader")
+		return allAgents[0], totals
+	}
+
+	winner := winners[0]
+	if len(winners) > 1 {
+		logging.Log(
+			logging.Info,
+			logging.LogField{"winners": winners},
+			"Multiple candidates with a winning score",
+		)
+		winner = winners[r.Intn(len(winners))]
+	}
+	logging.Log(logging.Info, nil, fmt.Sprintf("New leader has been elected %s with a score of %d", winner, best))
+
+	return winner, totals
+}
+
+// tally converts a count of votes to an election result's tally.
+func tally(votes map[commons.ID]uint) map[commons.ID]float64 {
+	t := make(map[commons.ID]float64, len(votes))
+	for candidate, n := range votes {
+		t[candidate] = float64(n)
+	}
+	return t
+}
+
+// margin is how far winner's tally is ahead of the runner-up's.
+func margin(tally map[commons.ID]float64, winner commons.ID) float64 {
+	var runnerUp float64
+	for candidate, total := range tally {
+		if candidate != winner && total > runnerUp {
+			runnerUp = total
+		}
+	}
+	return tally[winner] - runnerUp
+}
+
+// FirstRound counts the first preferences of a two-round election, returning
+// the winner if a candidate has a majority of the ballots cast, or otherwise
+// the two candidates with the most votes to go to a runoff. Ties for a place
+// in the runoff are broken at random.
+func FirstRound(ballots []decision.Ballot, allAgents []commons.ID, r *rand.Rand) (commons.ID, []commons.ID, logging.RunoffRound) {
+	round := logging.RunoffRound{Votes: make(map[commons.ID]uint)}
+	for _, ballot := range ballots {
+		if len(ballot) > 0 {
+			round.Votes[ballot[0]]++
+		} else {
+			round.Exhausted++
+		}
+	}
+	if len(round.Votes) == 0 {
+		logging.Log(logging.Info, nil, "No votes cast. Random agent selected as leader")
+		return allAgents[0], nil, round
+	}
+
+	candidates := maps.Keys(round.Votes)
+	sort.Strings(candidates)
+	r.Shuffle(len(candidates), func(i, j int) { candidates[i], candidates[j] = candidates[j], candidates[i] })
+	sort.SliceStable(candidates, func(i, j int) bool { return round.Votes[candidates[i]] > round.Votes[candidates[j]] })
+
+	cast := uint(len(ballots)) - round.Exhausted
+	if len(candidates) == 1 || 2*round.Votes[candidates[0]] > cast {
+		logging.Log(logging.Info, nil, fmt.Sprintf("New leader has been elected %s with a first round majority", candidates[0]))
+		return candidates[0], nil, round
+	}
+	finalists := candidates[:2]
+	sort.Strings(finalists)
+	return "", finalists, round
+}
+
+// Runoff counts the second round of a two-round election between finalists,
+// each ballot for the finalist it ranks highest. A tie is broken at random.
+func Runoff(ballots []decision.Ballot, finalists []commons.ID, r *rand.Rand) (commons.ID, logging.RunoffRound) {
+	round := logging.RunoffRound{Votes: make(map[commons.ID]uint, len(finalists))}
+	for _, finalist := range finalists {
+		round.Votes[finalist] = 0
+	}
+	for _, ballot := range ballots {
+		counted := false
+		for _, candidate := range ballot {
+			if _, ok := round.Votes[candidate]; ok {
+				round.Votes[candidate]++
+				counted = true
+				break
+			}
+		}
+		if !counted {
+			round.Exhausted++
+		}
+	}
+
+	winner, loser := finalists[0], finalists[1]
+	switch a, b := round.Votes[winner], round.Votes[loser]; {
+	case b > a:
+		winner, loser = loser, winner
+	case a == b && r.Intn(2) == 1:
+		winner, loser = loser, winner
+	}
+	logging.Log(logging.Info, nil, fmt.Sprintf("New leader has been elected %s in a runoff with %d votes to %d", winner, round.Votes[winner], round.Votes[loser]))
+
+	return winner, round
+}
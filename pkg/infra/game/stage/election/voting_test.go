@@ -0,0 +1,229 @@
+package election_test
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"infra/game/commons"
+	"infra/game/decision"
+	"infra/game/stage/election"
+	"infra/logging"
+)
+
+func TestInstantRunoff(t *testing.T) {
+	t.Parallel()
+
+	agents := []commons.ID{"a", "b", "c", "d"}
+	tests := []struct {
+		name        string
+		ballots     []decision.Ballot
+		preferences uint
+		want        commons.ID
+		eliminated  []commons.ID
+	}{
+		{
+			name:        "first round majority",
+			ballots:     []decision.Ballot{{"a", "b"}, {"a"}, {"b", "a"}},
+			preferences: 2,
+			want:        "a",
+			eliminated:  []commons.ID{""},
+		},
+		{
+			name:        "transfers elect the runner up",
+			ballots:     []decision.Ballot{{"a"}, {"a"}, {"b"}, {"b"}, {"c", "b"}},
+			preferences: 2,
+			want:        "b",
+			eliminated:  []commons.ID{"c", ""},
+		},
+		{
+			name:        "preferences beyond the limit are ignored",
+			ballots:     []decision.Ballot{{"a"}, {"a"}, {"b"}, {"b"}, {"c", "b"}},
+			preferences: 1,
+			want:        "a",
+			eliminated:  []commons.ID{"c", "b", ""},
+		},
+		{
+			name:        "ties eliminate by the earlier round, then the later ID",
+			ballots:     []decision.Ballot{{"a"}, {"a"}, {"b"}, {"c"}, {"d", "c"}},
+			preferences: 2,
+			want:        "a",
+			eliminated:  []commons.ID{"d", "b", "c", ""},
+		},
+		{
+			name:        "no ballots",
+			ballots:     []decision.Ballot{{}, {}},
+			preferences: 2,
+			want:        "a",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, rounds := election.InstantRunoff(tt.ballots, agents, tt.preferences)
+			if got != tt.want {
+				t.Errorf("InstantRunoff() = %s, want %s", got, tt.want)
+			}
+			var eliminated []commons.ID
+			for _, round := range rounds {
+				eliminated = append(eliminated, round.Eliminated)
+			}
+			if !reflect.DeepEqual(eliminated, tt.eliminated) {
+				t.Errorf("InstantRunoff() eliminated %q, want %q", eliminated, tt.eliminated)
+			}
+		})
+	}
+}
+
+func TestBordaCount(t *testing.T) {
+	t.Parallel()
+
+	agents := []commons.ID{"a", "b", "c"}
+	ballots := []decision.Ballot{{"a", "b", "c"}, {"a", "b", "c"}, {"b", "a", "c"}}
+	tests := []struct {
+		name    string
+		weights []float64
+		want    commons.ID
+		scores  map[commons.ID]float64
+	}{
+		{
+			name:   "equal weights",
+			want:   "a",
+			scores: map[commons.ID]float64{"a": 11, "b": 10, "c": 6},
+		},
+		{
+			name:    "weighted",
+			weights: []float64{1, 1, 3},
+			want:    "b",
+			scores:  map[commons.ID]float64{"a": 17, "b": 18, "c": 10},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, scores := election.BordaCount(ballots, tt.weights, agents, rand.New(rand.NewSource(1)))
+			if got != tt.want {
+				t.Errorf("BordaCount() = %s, want %s", got, tt.want)
+			}
+			if !reflect.DeepEqual(scores, tt.scores) {
+				t.Errorf("BordaCount() scores %v, want %v", scores, tt.scores)
+			}
+		})
+	}
+}
+
+func TestScoreCount(t *testing.T) {
+	t.Parallel()
+
+	agents := []commons.ID{"a", "b", "c"}
+	tests := []struct {
+		name      string
+		ballots   []decision.Scores
+		maxScore  uint
+		want      commons.ID
+		wantTotal uint
+	}{
+		{
+			name:      "approval",
+			ballots:   []decision.Scores{{"a": 1, "b": 1}, {"b": 1}, {"c": 1, "b": 1}},
+			maxScore:  1,
+			want:      "b",
+			wantTotal: 3,
+		},
+		{
+			name:      "score",
+			ballots:   []decision.Scores{{"a": 10, "b": 9}, {"a": 0, "b": 9}, {"c": 10}},
+			maxScore:  10,
+			want:      "b",
+			wantTotal: 18,
+		},
+		{
+			name:      "scores are capped",
+			ballots:   []decision.Scores{{"a": 100}, {"b": 5}, {"b": 4}},
+			maxScore:  5,
+			want:      "b",
+			wantTotal: 9,
+		},
+		{
+			name:      "converted ranked ballots",
+			ballots:   []decision.Scores{decision.Ballot{"a", "b"}.Scores(decision.ScoreVoting, 10), decision.Ballot{"b", "c", "a"}.Scores(decision.ScoreVoting, 10)},
+			maxScore:  10,
+			want:      "b",
+			wantTotal: 15,
+		},
+		{
+			name:     "no votes",
+			ballots:  []decision.Scores{{}, {"b": 0}},
+			maxScore: 10,
+			want:     "a",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, totals := election.ScoreCount(tt.ballots, agents, tt.maxScore, rand.New(rand.NewSource(1)))
+			if got != tt.want || totals[got] != tt.wantTotal {
+				t.Errorf("ScoreCount() = %s with %d, want %s with %d", got, totals[got], tt.want, tt.wantTotal)
+			}
+		})
+	}
+}
+
+func TestTwoRound(t *testing.T) {
+	t.Parallel()
+
+	agents := []commons.ID{"a", "b", "c", "d"}
+	tests := []struct {
+		name      string
+		first     []decision.Ballot
+		finalists []commons.ID
+		second    []decision.Ballot
+		want      commons.ID
+	}{
+		{
+			name:  "first round majority",
+			first: []decision.Ballot{{"a"}, {"a", "b"}, {"b"}, {}},
+			want:  "a",
+		},
+		{
+			name:      "runoff between the top two",
+			first:     []decision.Ballot{{"a"}, {"a"}, {"b"}, {"b"}, {"b"}, {"c"}, {"c"}, {"d"}},
+			finalists: []commons.ID{"a", "b"},
+			second:    []decision.Ballot{{"a"}, {"a"}, {"b"}, {"b"}, {"b"}, {"c", "a"}, {"a"}, {"d"}},
+			want:      "a",
+		},
+		{
+			name:  "no ballots",
+			first: []decision.Ballot{{}, {}},
+			want:  "a",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			r := rand.New(rand.NewSource(1))
+			got, finalists, _ := election.FirstRound(tt.first, agents, r)
+			if !reflect.DeepEqual(finalists, tt.finalists) {
+				t.Fatalf("FirstRound() finalists = %q, want %q", finalists, tt.finalists)
+			}
+			if finalists != nil {
+				var round logging.RunoffRound
+				got, round = election.Runoff(tt.second, finalists, r)
+				if round.Exhausted != 1 {
+					t.Errorf("Runoff() exhausted %d ballots, want 1", round.Exhausted)
+				}
+			}
+			if got != tt.want {
+				t.Errorf("two-round election = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -2,7 +2,7 @@ package fight
 
 import (
 	"math"
-	"time"
+	"sort"
 
 	"infra/game/agent"
 	"infra/game/commons"
@@ -13,100 +13,84 @@ import (
 
 	"github.com/benbjohnson/immutable"
 	"github.com/google/uuid"
+	"golang.org/x/exp/maps"
 )
 
-func DealDamage(damageToDeal uint, agentsFighting []string, agentMap map[commons.ID]agent.Agent, globalState *state.State) {
-	splitDamage := damageToDeal / uint(len(agentsFighting))
-	for _, id := range agentsFighting {
-		agentState := globalState.AgentState[id]
-		newHP := commons.SaturatingSub(agentState.Hp, splitDamage)
+// DealDamage shares damageToDeal out among agentsFighting by policy, given
+// what each did in the round, killing those left without HP. It returns the
+// damage each agent took.
+func DealDamage(policy DamagePolicy, damageToDeal uint, agentsFighting []commons.ID, choices map[commons.ID]decision.FightAction, agentMap map[commons.ID]agent.Agent, globalState *state.State) map[commons.ID]uint {
+	shares := policy.Share(damageToDeal, agentsFighting, choices, globalState.AgentState)
+	taken := make(map[commons.ID]uint, len(agentsFighting))
+	for i, id := range agentsFighting {
+		agentState, ok := globalState.AgentState[id]
+		if !ok || shares[i] == 0 {
+			continue
+		}
+		taken[id] += shares[i]
+		newHP := commons.SaturatingSub(agentState.Hp, shares[i])
 		if newHP == 0 {
 			// kill agent
-			removeItems(globalState, globalState.AgentState[id])
+			globalState.InventoryMap.Remove(agentState)
+			globalState.Corpses = append(globalState.Corpses, state.Corpse{
+				ID:      id,
+				Weapons: commons.ImmutableListToSlice(agentState.Weapons),
+				Shields: commons.ImmutableListToSlice(agentState.Shields),
+				Stamina: agentState.Stamina,
+			})
 
 			delete(globalState.AgentState, id)
 			delete(agentMap, id)
 		} else {
-			globalState.AgentState[id] = state.AgentState{
-				Hp:          newHP,
-				Attack:      agentState.Attack,
-				Defense:     agentState.Defense,
-				Stamina:     agentState.Stamina,
-				Weapons:     agentState.Weapons,
-				Shields:     agentState.Shields,
-				WeaponInUse: agentState.WeaponInUse,
-				ShieldInUse: agentState.ShieldInUse,
-			}
+			agentState.Hp = newHP
+			globalState.AgentState[id] = agentState
 		}
 	}
+	return taken
 }
 
-func removeItems(globalState *state.State, agentState state.AgentState) {
-	removeItemsFromMap(globalState.InventoryMap.Weapons, agentState.Weapons)
-	removeItemsFromMap(globalState.InventoryMap.Shields, agentState.Shields)
-}
-
-func removeItemsFromMap(m map[commons.ID]uint, l immutable.List[state.Item]) {
-	iterator := l.Iterator()
-	for !iterator.Done() {
-		_, v := iterator.Next()
-		delete(m, v.Id())
+// AgentFightDecisions runs the fight discussion: every agent is told the fight
+// has started, the leader collects proposals and passes on the ones it accepts,
+// and every agent votes on what it is passed.
+func AgentFightDecisions(state state.State, agents map[commons.ID]agent.Agent, previousDecisions immutable.Map[commons.ID, decision.FightAction], rounds agent.Rounds) *tally.Tally[decision.FightAction] {
+	propTally := tally.NewTally[decision.FightAction](nil, nil, nil)
+	ballots := make(map[commons.ID]*agent.Ballot[decision.FightAction], len(agents))
+	for id := range agents {
+		ballots[id] = &agent.Ballot[decision.FightAction]{}
 	}
-}
 
-func AgentFightDecisions(state state.State, agents map[commons.ID]agent.Agent, previousDecisions immutable.Map[commons.ID, decision.FightAction], channelsMap map[commons.ID]chan message.TaggedMessage) *tally.Tally[decision.FightAction] {
-	proposalVotes := make(chan commons.ProposalID)
-	proposalSubmission := make(chan message.Proposal[decision.FightAction])
-	tallyClosure := make(chan struct{})
-
-	propTally := tally.NewTally(proposalVotes, proposalSubmission, tallyClosure)
-	go propTally.HandleMessages()
-	closures := make(map[commons.ID]chan<- struct{})
-	for id, a := range agents {
-		a := a
-		closure := make(chan struct{})
-		closures[id] = closure
-		agentState := state.AgentState[a.BaseAgent.ID()]
-		if a.BaseAgent.ID() == state.CurrentLeader {
-			go (&a).HandleFight(agentState, previousDecisions, proposalVotes, proposalSubmission, closure)
-		} else {
-			go (&a).HandleFight(agentState, previousDecisions, proposalVotes, nil, closure)
-		}
-	}
-	mID := uuid.Nil
-
-	for _, messages := range channelsMap {
-		messages <- *message.NewTaggedMessage("server", &message.StartFight{}, mID)
-	}
-	time.Sleep(25 * time.Millisecond)
-	for id, c := range channelsMap {
-		closures[id] <- struct{}{}
-		go func(recv <-chan message.TaggedMessage) {
-			for m := range recv {
-				switch m.Message().(type) {
-				case message.Request:
-					// todo: respond with nil thing here as we're closing! Or do we need to?
-					// maybe because we're closing there's no point...
-				default:
-				}
+	start := *message.NewTaggedMessage("server", &message.StartFight{}, uuid.Nil)
+	rounds.Run(agents, []message.TaggedMessage{start},
+		func(a *agent.Agent, inbox []message.TaggedMessage) {
+			a.HandleFight(state.AgentState[a.BaseAgent.ID()], previousDecisions, inbox, ballots[a.BaseAgent.ID()])
+		},
+		func(ids []commons.ID) {
+			for _, id := range ids {
+				castBallot(propTally, ballots[id])
 			}
-		}(c)
-	}
+		},
+	)
+	return propTally
+}
 
-	for _, c := range channelsMap {
-		close(c)
+func castBallot(t *tally.Tally[decision.FightAction], ballot *agent.Ballot[decision.FightAction]) {
+	for _, p := range ballot.Submissions {
+		t.Submit(p)
 	}
-
-	tallyClosure <- struct{}{}
-	close(tallyClosure)
-	return propTally
+	for _, vote := range ballot.Votes {
+		t.Vote(vote)
+	}
+	*ballot = agent.Ballot[decision.FightAction]{}
 }
 
 func HandleFightRound(state state.State, baseStamina uint, baseHealth uint, fightResult *decision.FightResult) *state.State {
 	var attackSum uint
 	var shieldSum uint
 
-	for agentID, d := range fightResult.Choices {
+	agentIDs := maps.Keys(fightResult.Choices)
+	sort.Strings(agentIDs)
+	for _, agentID := range agentIDs {
+		d := fightResult.Choices[agentID]
 		agentState := state.AgentState[agentID]
 
 		const scalingFactor = 0.02
@@ -0,0 +1,64 @@
+package fight_test
+
+import (
+	"testing"
+
+	"infra/game/agent"
+	"infra/game/commons"
+	"infra/game/stage/fight"
+	"infra/game/state"
+)
+
+func TestDealDamage(t *testing.T) {
+	t.Parallel()
+
+	armed := func(hp uint, weapon commons.ItemID) state.AgentState {
+		s := state.AgentState{Hp: hp, WeaponInUse: weapon}
+		s.AddWeapon(*state.NewItem(weapon, 5, state.SWORD))
+		s.Defector.SetFight(true)
+		return s
+	}
+	globalState := &state.State{
+		AgentState: map[commons.ID]state.AgentState{
+			"survivor": armed(100, "sword-1"),
+			"victim":   armed(10, "sword-2"),
+		},
+		InventoryMap: state.InventoryMap{
+			Weapons: map[commons.ItemID]uint{"sword-1": 5, "sword-2": 5},
+			Shields: map[commons.ItemID]uint{},
+		},
+	}
+	agents := map[commons.ID]agent.Agent{"survivor": {}, "victim": {}}
+
+	taken := fight.DealDamage(fight.EvenSplit{}, 20, []commons.ID{"survivor", "victim"}, nil, agents, globalState)
+
+	if taken["survivor"] != 10 || taken["victim"] != 10 {
+		t.Errorf("DealDamage() = %v, want 10 each", taken)
+	}
+	survivor := globalState.AgentState["survivor"]
+	if survivor.Hp != 90 {
+		t.Errorf("survivor HP = %d, want 90", survivor.Hp)
+	}
+	if !survivor.Defector.IsDefector() {
+		t.Error("survivor's defector flags were cleared by taking damage")
+	}
+	if survivor.WeaponInUse != "sword-1" || survivor.Weapons.Len() != 1 {
+		t.Errorf("survivor lost its weapon: in use %q, holding %d", survivor.WeaponInUse, survivor.Weapons.Len())
+	}
+
+	if _, ok := globalState.AgentState["victim"]; ok {
+		t.Error("victim still has a state")
+	}
+	if _, ok := agents["victim"]; ok {
+		t.Error("victim is still in the agent map")
+	}
+	if _, ok := globalState.InventoryMap.Weapons["sword-2"]; ok {
+		t.Error("victim's weapon is still in the inventory map")
+	}
+	if _, ok := globalState.InventoryMap.Weapons["sword-1"]; !ok {
+		t.Error("survivor's weapon was taken out of the inventory map")
+	}
+	if len(globalState.Corpses) != 1 || globalState.Corpses[0].ID != "victim" {
+		t.Errorf("corpses = %v, want the victim's", globalState.Corpses)
+	}
+}
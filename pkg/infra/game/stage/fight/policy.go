@@ -0,0 +1,179 @@
+package fight
+
+import (
+	"math/rand"
+
+	"infra/config"
+	"infra/game/commons"
+	"infra/game/decision"
+	"infra/game/state"
+)
+
+// DamagePolicy shares damage out among the agents it falls on.
+type DamagePolicy interface {
+	// Share returns the damage each of ids takes, in the same order, given
+	// what each did in the round. The shares add up to damage.
+	Share(damage uint, ids []commons.ID, choices map[commons.ID]decision.FightAction, agents map[commons.ID]state.AgentState) []uint
+}
+
+// NewDamagePolicy builds the damage policy gameConfig names, drawing any
+// randomness from r.
+func NewDamagePolicy(gameConfig config.GameConfig, r *rand.Rand) DamagePolicy {
+	switch gameConfig.DamagePolicy {
+	case config.Exposure:
+		return ExposurePolicy{}
+	case config.InverseDefence:
+		return InverseDefencePolicy{}
+	case config.FocusFire:
+		return FocusFirePolicy{K: gameConfig.FocusFire, Rand: r}
+	case config.ShieldWall:
+		return ShieldWallPolicy{}
+	default:
+		return EvenSplit{}
+	}
+}
+
+// EvenSplit shares damage out evenly, the remainder going one each to the
+// first agents.
+type EvenSplit struct{}
+
+func (EvenSplit) Share(damage uint, ids []commons.ID, _ map[commons.ID]decision.FightAction, _ map[commons.ID]state.AgentState) []uint {
+	shares := make([]uint, len(ids))
+	if len(ids) == 0 {
+		return shares
+	}
+	split, remainder := damage/uint(len(ids)), damage%uint(len(ids))
+	for i := range shares {
+		shares[i] = split
+		if uint(i) < remainder {
+			shares[i]++
+		}
+	}
+	return shares
+}
+
+// ExposurePolicy shares damage out by exposure: attackers take twice what
+// shielding or cowering agents do.
+type ExposurePolicy struct{}
+
+func (ExposurePolicy) Share(damage uint, ids []commons.ID, choices map[commons.ID]decision.FightAction, _ map[commons.ID]state.AgentState) []uint {
+	weights := make([]float64, len(ids))
+	for i, id := range ids {
+		weights[i] = 1
+		if choices[id] == decision.Attack {
+			weights[i] = 2
+		}
+	}
+	return weighted(damage, weights)
+}
+
+// InverseDefencePolicy shares damage out in inverse proportion to each
+// agent's defence, so the least protected take the most.
+type InverseDefencePolicy struct{}
+
+func (InverseDefencePolicy) Share(damage uint, ids []commons.ID, _ map[commons.ID]decision.FightAction, agents map[commons.ID]state.AgentState) []uint {
+	weights := make([]float64, len(ids))
+	for i, id := range ids {
+		agentState := agents[id]
+		weights[i] = 1 / float64(1+agentState.TotalDefense())
+	}
+	return weighted(damage, weights)
+}
+
+// FocusFirePolicy splits damage evenly among K agents picked at random, or
+// all of them when there are no more than K or K is zero.
+type FocusFirePolicy struct {
+	K    uint
+	Rand *rand.Rand
+}
+
+func (p FocusFirePolicy) Share(damage uint, ids []commons.ID, choices map[commons.ID]decision.FightAction, agents map[commons.ID]state.AgentState) []uint {
+	if p.K == 0 || uint(len(ids)) <= p.K {
+		return EvenSplit{}.Share(damage, ids, choices, agents)
+	}
+	focused := p.Rand.Perm(len(ids))[:p.K]
+	split := EvenSplit{}.Share(damage, make([]commons.ID, p.K), choices, agents)
+	shares := make([]uint, len(ids))
+	for i, idx := range focused {
+		shares[idx] = split[i]
+	}
+	return shares
+}
+
+// ShieldWallPolicy has the shielding agents absorb damage, evenly and up to
+// the HP each has left, before the rest is split evenly among the others.
+type ShieldWallPolicy struct{}
+
+func (ShieldWallPolicy) Share(damage uint, ids []commons.ID, choices map[commons.ID]decision.FightAction, agents map[commons.ID]state.AgentState) []uint {
+	var wall, others []int
+	for i, id := range ids {
+		if choices[id] == decision.Defend {
+			wall = append(wall, i)
+		} else {
+			others = append(others, i)
+		}
+	}
+	if len(wall) == 0 || len(others) == 0 {
+		return EvenSplit{}.Share(damage, ids, choices, agents)
+	}
+
+	shares := make([]uint, len(ids))
+	// fill the wall evenly, each shielding agent absorbing no more than its HP
+	standing := wall
+	for damage > 0 && len(standing) > 0 {
+		split := EvenSplit{}.Share(damage, make([]commons.ID, len(standing)), choices, agents)
+		var next []int
+		for i, idx := range standing {
+			absorbed := split[i]
+			if left := agents[ids[idx]].Hp - shares[idx]; absorbed >= left {
+				absorbed = left
+			} else {
+				next = append(next, idx)
+			}
+			shares[idx] += absorbed
+			damage -= absorbed
+		}
+		if len(next) == len(standing) {
+			break
+		}
+		standing = next
+	}
+
+	split := EvenSplit{}.Share(damage, make([]commons.ID, len(others)), choices, agents)
+	for i, idx := range others {
+		shares[idx] += split[i]
+	}
+	return shares
+}
+
+// weighted shares damage out in proportion to weights, handing what rounding
+// leaves over to the largest remainders, earliest first.
+func weighted(damage uint, weights []float64) []uint {
+	shares := make([]uint, len(weights))
+	total := 0.0
+	for _, w := range weights {
+		total += w
+	}
+	if total == 0 {
+		return shares
+	}
+	remainders := make([]float64, len(weights))
+	dealt := uint(0)
+	for i, w := range weights {
+		exact := float64(damage) * w / total
+		shares[i] = uint(exact)
+		remainders[i] = exact - float64(shares[i])
+		dealt += shares[i]
+	}
+	for ; dealt < damage; dealt++ {
+		largest := 0
+		for i := range remainders {
+			if remainders[i] > remainders[largest] {
+				largest = i
+			}
+		}
+		shares[largest]++
+		remainders[largest] = -1
+	}
+	return shares
+}
@@ -0,0 +1,88 @@
+package fight_test
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"infra/game/commons"
+	"infra/game/decision"
+	"infra/game/stage/fight"
+	"infra/game/state"
+)
+
+func TestDamagePolicies(t *testing.T) {
+	t.Parallel()
+
+	ids := []commons.ID{"a", "b", "c", "d"}
+	choices := map[commons.ID]decision.FightAction{
+		"a": decision.Attack,
+		"b": decision.Attack,
+		"c": decision.Defend,
+		"d": decision.Defend,
+	}
+	agents := map[commons.ID]state.AgentState{
+		"a": {Hp: 100, Defense: 0},
+		"b": {Hp: 100, Defense: 9},
+		"c": {Hp: 10, Defense: 0},
+		"d": {Hp: 100, Defense: 0},
+	}
+
+	tests := []struct {
+		name   string
+		policy fight.DamagePolicy
+		damage uint
+		want   []uint
+	}{
+		{name: "even split keeps the remainder", policy: fight.EvenSplit{}, damage: 10, want: []uint{3, 3, 2, 2}},
+		{name: "exposure", policy: fight.ExposurePolicy{}, damage: 60, want: []uint{20, 20, 10, 10}},
+		{name: "inverse defence", policy: fight.InverseDefencePolicy{}, damage: 31, want: []uint{10, 1, 10, 10}},
+		{name: "shield wall", policy: fight.ShieldWallPolicy{}, damage: 50, want: []uint{0, 0, 10, 40}},
+		{name: "shield wall overflows", policy: fight.ShieldWallPolicy{}, damage: 120, want: []uint{5, 5, 10, 100}},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := tt.policy.Share(tt.damage, ids, choices, agents); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Share() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFocusFire(t *testing.T) {
+	t.Parallel()
+
+	ids := []commons.ID{"a", "b", "c", "d", "e"}
+	tests := []struct {
+		name    string
+		k       uint
+		wantHit int
+	}{
+		{name: "focused", k: 2, wantHit: 2},
+		{name: "no more agents than K", k: 5, wantHit: 5},
+		{name: "zero K splits evenly", k: 0, wantHit: 5},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			policy := fight.FocusFirePolicy{K: tt.k, Rand: rand.New(rand.NewSource(1))}
+			shares := policy.Share(11, ids, nil, nil)
+
+			hit, total := 0, uint(0)
+			for _, share := range shares {
+				if share > 0 {
+					hit++
+				}
+				total += share
+			}
+			if hit != tt.wantHit || total != 11 {
+				t.Errorf("Share() = %v, want 11 damage on %d agents", shares, tt.wantHit)
+			}
+		})
+	}
+}
@@ -10,7 +10,10 @@ import (
 	"infra/logging"
 )
 
-func UpdateHpPool(agentMap map[commons.ID]agent.Agent, globalState *state.State) {
+// UpdateHpPool has every agent donate to the HP pool, taking at least tax
+// percent of each agent's HP, though never its last, whatever it offers. It
+// returns what each agent donated, and how much more than offered was taken.
+func UpdateHpPool(agentMap map[commons.ID]agent.Agent, globalState *state.State, tax uint) (map[commons.ID]uint, uint) {
 	var wg sync.WaitGroup
 	donationChan := make(chan decision.HpPoolDonation, len(agentMap))
 	for id, a := range agentMap {
@@ -30,11 +33,21 @@ func UpdateHpPool(agentMap map[commons.ID]agent.Agent, globalState *state.State)
 		close(donationChan)
 	}(&wg)
 
-	sum := uint(0)
+	sum, taxed := uint(0), uint(0)
+	donations := make(map[commons.ID]uint, len(agentMap))
 	for agentDonation := range donationChan {
 		agentHp := globalState.AgentState[agentDonation.AgentID].Hp
+		due := agentHp * tax / 100
+		if due >= agentHp {
+			due = commons.SaturatingSub(agentHp, 1)
+		}
+		if agentDonation.Donation < due {
+			taxed += due - agentDonation.Donation
+			agentDonation.Donation = due
+		}
 		if agentDonation.Donation >= agentHp {
 			agentDonation.Donation = agentHp
+			globalState.InventoryMap.Remove(globalState.AgentState[agentDonation.AgentID])
 			delete(globalState.AgentState, agentDonation.AgentID)
 			delete(agentMap, agentDonation.AgentID)
 		}
@@ -46,6 +59,7 @@ func UpdateHpPool(agentMap map[commons.ID]agent.Agent, globalState *state.State)
 		}, "HP Pool Donation")
 
 		sum += agentDonation.Donation
+		donations[agentDonation.AgentID] = agentDonation.Donation
 		if a, ok := globalState.AgentState[agentDonation.AgentID]; ok {
 			a.Hp = agentHp - agentDonation.Donation
 			globalState.AgentState[agentDonation.AgentID] = a
@@ -55,8 +69,10 @@ func UpdateHpPool(agentMap map[commons.ID]agent.Agent, globalState *state.State)
 	logging.Log(logging.Info, logging.LogField{
 		"Old HP Pool":           globalState.HpPool,
 		"HP Donated This Round": sum,
+		"HP Taxed This Round":   taxed,
 		"New Hp Pool":           globalState.HpPool + sum,
 	}, "HP Pool Donation")
 
 	globalState.HpPool += sum
+	return donations, taxed
 }
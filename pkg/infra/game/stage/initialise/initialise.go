@@ -4,6 +4,7 @@ import (
 	"infra/config"
 	"infra/game/agent"
 	"infra/game/commons"
+	"infra/game/rng"
 	"infra/game/state"
 
 	"github.com/benbjohnson/immutable"
@@ -17,11 +18,13 @@ func InstantiateAgent[S agent.Strategy](gameConfig config.GameConfig,
 	strategyConstructor func() S,
 	agentName string,
 	viewPtr *state.View,
+	source *rng.Source,
 ) {
+	ids := source.Stream("agent-ids", agentName)
 	for i := uint(0); i < quantity; i++ {
-		agentID := uuid.NewString()
+		agentID := rng.NewID(ids)
 		agentMap[agentID] = agent.Agent{
-			BaseAgent: agent.NewBaseAgent(nil, agentID, agentName, viewPtr),
+			BaseAgent: agent.NewBaseAgent(nil, agentID, agentName, viewPtr, source.Stream("agent", agentID)),
 			Strategy:  strategyConstructor(),
 		}
 
@@ -38,27 +41,11 @@ func InstantiateAgent[S agent.Strategy](gameConfig config.GameConfig,
 	}
 }
 
-func InitGameConfig() config.GameConfig {
-	gameConfig := config.GameConfig{
-		NumLevels:              config.EnvToUint("LEVELS", 60),
-		StartingHealthPoints:   config.EnvToUint("STARTING_HP", 1000),
-		StartingAttackStrength: config.EnvToUint("STARTING_ATTACK", 20),
-		StartingShieldStrength: config.EnvToUint("STARTING_SHIELD", 20),
-		ThresholdPercentage:    config.EnvToFloat("THRESHOLD_PCT", 0.01),
-		InitialNumAgents:       uint(0),
-		Stamina:                config.EnvToUint("BASE_STAMINA", 2000),
-		VotingStrategy:         config.EnvToUint("VOTING_STRATEGY", 0),
-		VotingPreferences:      config.EnvToUint("VOTING_PREFERENCES", 2),
-		Defection:              config.EnvToBool("DEFECTION", true),
-	}
-
-	return gameConfig
-}
-
 func InitAgents(
 	defaultStrategyMap map[commons.ID]func() agent.Strategy,
 	gameConfig config.GameConfig,
 	ptr *state.View,
+	source *rng.Source,
 ) (numAgents uint, agentMap map[commons.ID]agent.Agent, agentStateMap map[commons.ID]state.AgentState, inventoryMap state.InventoryMap) {
 	agentMap = make(map[commons.ID]agent.Agent)
 	agentStateMap = make(map[commons.ID]state.AgentState)
@@ -70,11 +57,13 @@ func InitAgents(
 	numAgents = 0
 
 	for agentName, strategy := range defaultStrategyMap {
-		expectedEnvName := "AGENT_" + agentName + "_QUANTITY"
-		quantity := config.EnvToUint(expectedEnvName, 30)
+		quantity, ok := gameConfig.AgentQuantities[agentName]
+		if !ok {
+			quantity = 30
+		}
 
 		numAgents += quantity
-		InstantiateAgent(gameConfig, agentMap, agentStateMap, quantity, strategy, agentName, ptr)
+		InstantiateAgent(gameConfig, agentMap, agentStateMap, quantity, strategy, agentName, ptr, source)
 	}
 
 	return
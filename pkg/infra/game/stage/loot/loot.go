@@ -1,22 +1,24 @@
 package loot
 
 import (
-	"fmt"
 	"infra/game/decision"
 	"infra/game/message"
 	"infra/game/tally"
 	"log"
+	"math/rand"
 	"sort"
 
 	// "math"
 	"sync"
-	"time"
 
 	// "github.com/benbjohnson/immutable"
 
 	"infra/game/agent"
 	"infra/game/commons"
 	"infra/game/state"
+
+	"github.com/google/uuid"
+	"golang.org/x/exp/maps"
 )
 
 type agentStateUpdate struct {
@@ -63,59 +65,43 @@ func UpdateItems(s state.State, agents map[commons.ID]agent.Agent) *state.State
 	return &updatedState
 }
 
+// AgentLootDecisions runs the loot discussion: every agent is shown the loot
+// on offer, the leader collects proposals and passes on the ones it accepts,
+// and every agent votes on what it is passed.
 func AgentLootDecisions(
 	state state.State,
 	availableLoot state.LootPool,
 	agents map[commons.ID]agent.Agent,
-	channelsMap map[commons.ID]chan message.TaggedMessage,
+	rounds agent.Rounds,
 ) *tally.Tally[decision.LootAction] {
-	proposalVotes := make(chan commons.ProposalID)
-	proposalSubmission := make(chan message.Proposal[decision.LootAction])
-	tallyClosure := make(chan struct{})
-
-	propTally := tally.NewTally(proposalVotes, proposalSubmission, tallyClosure)
-	go propTally.HandleMessages()
-	closures := make(map[commons.ID]chan<- struct{})
-	starts := make(map[commons.ID]chan<- message.StartLoot)
-	for id, a := range agents {
-		a := a
-		closure := make(chan struct{})
-		closures[id] = closure
-
-		start := make(chan message.StartLoot)
-		starts[id] = start
-
-		agentState := state.AgentState[a.BaseAgent.ID()]
-		if a.BaseAgent.ID() == state.CurrentLeader {
-			go (&a).HandleLoot(agentState, proposalVotes, proposalSubmission, closure, start) // <----- threaded for voting
-		} else {
-			go (&a).HandleLoot(agentState, proposalVotes, nil, closure, start)
-		}
+	propTally := tally.NewTally[decision.LootAction](nil, nil, nil)
+	ballots := make(map[commons.ID]*agent.Ballot[decision.LootAction], len(agents))
+	for id := range agents {
+		ballots[id] = &agent.Ballot[decision.LootAction]{}
 	}
 
-	startLootMessage := *message.NewStartLoot(availableLoot)
-	for _, start := range starts {
-		start <- startLootMessage
-	}
-
-	fmt.Println("Starts all sent")
-
-	time.Sleep(25 * time.Millisecond)
+	start := *message.NewTaggedMessage("server", *message.NewStartLoot(availableLoot), uuid.Nil)
+	rounds.Run(agents, []message.TaggedMessage{start},
+		func(a *agent.Agent, inbox []message.TaggedMessage) {
+			a.HandleLoot(state.AgentState[a.BaseAgent.ID()], inbox, ballots[a.BaseAgent.ID()])
+		},
+		func(ids []commons.ID) {
+			for _, id := range ids {
+				castBallot(propTally, ballots[id])
+			}
+		},
+	)
+	return propTally
+}
 
-	for _, c := range closures {
-		c <- struct{}{}
-		close(c)
+func castBallot(t *tally.Tally[decision.LootAction], ballot *agent.Ballot[decision.LootAction]) {
+	for _, p := range ballot.Submissions {
+		t.Submit(p)
 	}
-
-	fmt.Println("Channels closed")
-
-	for _, c := range channelsMap {
-		close(c)
+	for _, vote := range ballot.Votes {
+		t.Vote(vote)
 	}
-
-	tallyClosure <- struct{}{}
-	close(tallyClosure)
-	return propTally
+	*ballot = agent.Ballot[decision.LootAction]{}
 }
 
 // func HandleLootAllocation(globalState state.State, allocation map[commons.ID]map[commons.ItemID]struct{}, pool *state.LootPool, agentMap map[commons.ID]agent.Agent) *state.State {
@@ -154,7 +140,29 @@ func AgentLootDecisions(
 // 	return &globalState
 // }
 
-func HandleLootAllocationExhaustive(globalState state.State, pool *state.LootPool, looters []agent.Agent) *state.State {
+// OrderLooters orders agents to choose loot under allocation: by ID for
+// decision.RoundRobin, from the lowest HP up for decision.NeedsFirst, ties by
+// ID, and shuffled with r for decision.Lottery.
+func OrderLooters(allocation decision.LootAllocation, agents map[commons.ID]agent.Agent, agentState map[commons.ID]state.AgentState, r *rand.Rand) []agent.Agent {
+	ids := maps.Keys(agents)
+	sort.Strings(ids)
+	switch allocation {
+	case decision.NeedsFirst:
+		sort.SliceStable(ids, func(i, j int) bool { return agentState[ids[i]].Hp < agentState[ids[j]].Hp })
+	case decision.Lottery:
+		r.Shuffle(len(ids), func(i, j int) { ids[i], ids[j] = ids[j], ids[i] })
+	}
+	looters := make([]agent.Agent, 0, len(ids))
+	for _, id := range ids {
+		looters = append(looters, agents[id])
+	}
+	return looters
+}
+
+// HandleLootAllocationExhaustive has the looters take turns, in order, choosing
+// an item until the pool is empty. Looters with a limit stop choosing once
+// they have that many items, and what nobody may take is lost.
+func HandleLootAllocationExhaustive(globalState state.State, pool *state.LootPool, looters []agent.Agent, limits map[commons.ID]uint) *state.State {
 
 	if len(looters) == 0 {
 		return &globalState
@@ -167,9 +175,14 @@ func HandleLootAllocationExhaustive(globalState state.State, pool *state.LootPoo
 
 	totalNumItems := len(weaponSet) + len(shieldSet) + len(hpPotionSet) + len(staminaPotionSet)
 
+	received := make(map[commons.ID]uint)
 	for totalNumItems > 0 {
+		allocatedThisPass := false
 		for _, agent := range looters {
 			agentID := agent.ID()
+			if limit, ok := limits[agentID]; ok && received[agentID] >= limit {
+				continue
+			}
 			agentState := globalState.AgentState[agentID]
 			itemPreferenceOrder := agent.ChooseItem(*agent.BaseAgent, weaponSet, shieldSet, hpPotionSet, staminaPotionSet)
 			// itemPreferenceOrder := []state.ItemName{state.SWORD, state.SHIELD, state.HP_POTION, state.STAMINA_POTION}
@@ -188,6 +201,7 @@ func HandleLootAllocationExhaustive(globalState state.State, pool *state.LootPoo
 						continue
 					}
 					agentState.AddWeapon(weaponSet[0])
+					globalState.InventoryMap.Weapons[weaponSet[0].Id()] = weaponSet[0].Value()
 					weaponSet = weaponSet[1:]
 					itemAllocated = true
 					totalNumItems--
@@ -197,6 +211,7 @@ func HandleLootAllocationExhaustive(globalState state.State, pool *state.LootPoo
 						continue
 					}
 					agentState.AddShield(shieldSet[0])
+					globalState.InventoryMap.Shields[shieldSet[0].Id()] = shieldSet[0].Value()
 					shieldSet = shieldSet[1:]
 					itemAllocated = true
 					totalNumItems--
@@ -222,6 +237,8 @@ func HandleLootAllocationExhaustive(globalState state.State, pool *state.LootPoo
 					continue
 				}
 				if itemAllocated {
+					received[agentID]++
+					allocatedThisPass = true
 					break
 				}
 			}
@@ -231,6 +248,9 @@ func HandleLootAllocationExhaustive(globalState state.State, pool *state.LootPoo
 				break
 			}
 		}
+		if !allocatedThisPass {
+			break
+		}
 	}
 
 	return &globalState
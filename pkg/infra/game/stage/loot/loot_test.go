@@ -0,0 +1,119 @@
+package loot_test
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"infra/game/agent"
+	"infra/game/commons"
+	"infra/game/decision"
+	"infra/game/stage/loot"
+	"infra/game/state"
+)
+
+// swordsFirst is a strategy that always chooses a weapon if there is one.
+type swordsFirst struct {
+	agent.Strategy
+}
+
+func (swordsFirst) ChooseItem(agent.BaseAgent, []state.Item, []state.Item, []state.Item, []state.Item) []state.ItemName {
+	return []state.ItemName{state.SWORD, state.SHIELD, state.HP_POTION, state.STAMINA_POTION}
+}
+
+// newLooters makes an agent choosing swords first, and its state, for each
+// HP given, with IDs "a", "b", ... in order.
+func newLooters(hp ...uint) (map[commons.ID]agent.Agent, state.State) {
+	view := state.View{}
+	agents := make(map[commons.ID]agent.Agent, len(hp))
+	globalState := state.State{
+		AgentState:   make(map[commons.ID]state.AgentState, len(hp)),
+		InventoryMap: state.InventoryMap{Weapons: map[commons.ItemID]uint{}, Shields: map[commons.ItemID]uint{}},
+	}
+	for i, v := range hp {
+		id := string(rune('a' + i))
+		agents[id] = agent.Agent{
+			BaseAgent: agent.NewBaseAgent(nil, id, id, &view, rand.New(rand.NewSource(int64(i)))),
+			Strategy:  swordsFirst{},
+		}
+		globalState.AgentState[id] = state.AgentState{Hp: v}
+	}
+	return agents, globalState
+}
+
+// weapons makes a loot pool of weapons with the values given.
+func weapons(values ...uint) *state.LootPool {
+	items := make([]state.Item, 0, len(values))
+	for i, v := range values {
+		items = append(items, *state.NewItem(fmt.Sprint("weapon-", i), v, state.SWORD))
+	}
+	none := commons.NewImmutableList([]state.Item{})
+	return state.NewLootPool(commons.NewImmutableList(items), none, none, none)
+}
+
+func TestOrderLooters(t *testing.T) {
+	t.Parallel()
+
+	agents, globalState := newLooters(50, 10, 30, 10)
+
+	tests := []struct {
+		allocation decision.LootAllocation
+		want       []commons.ID
+	}{
+		{allocation: decision.RoundRobin, want: []commons.ID{"a", "b", "c", "d"}},
+		{allocation: decision.NeedsFirst, want: []commons.ID{"b", "d", "c", "a"}},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(string(tt.allocation), func(t *testing.T) {
+			t.Parallel()
+
+			looters := loot.OrderLooters(tt.allocation, agents, globalState.AgentState, rand.New(rand.NewSource(1)))
+			got := make([]commons.ID, 0, len(looters))
+			for _, a := range looters {
+				got = append(got, a.ID())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("OrderLooters() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNeedsFirstAllocation(t *testing.T) {
+	t.Parallel()
+
+	agents, globalState := newLooters(50, 10, 30, 20)
+	looters := loot.OrderLooters(decision.NeedsFirst, agents, globalState.AgentState, nil)
+	after := loot.HandleLootAllocationExhaustive(globalState, weapons(1, 4, 2, 3), looters, nil)
+
+	// weapons are handed out from the least valuable up, so the agent
+	// choosing first, the weakest, gets the least valuable
+	want := map[commons.ID]uint{"b": 1, "d": 2, "c": 3, "a": 4}
+	for id, value := range want {
+		held := commons.ImmutableListToSlice(after.AgentState[id].Weapons)
+		if len(held) != 1 || held[0].Value() != value {
+			t.Errorf("agent %s with %d HP holds %v, want a weapon worth %d", id, after.AgentState[id].Hp, held, value)
+		}
+	}
+}
+
+func TestLeaderLootShare(t *testing.T) {
+	t.Parallel()
+
+	agents, globalState := newLooters(100, 100, 100)
+	looters := loot.OrderLooters(decision.RoundRobin, agents, globalState.AgentState, nil)
+	pool := weapons(1, 2, 3, 4, 5, 6, 7, 8, 9, 10)
+	// the leader may take 20% of the pool's 10 items
+	const share = 20
+	limits := map[commons.ID]uint{"a": 10 * share / 100}
+	after := loot.HandleLootAllocationExhaustive(globalState, pool, looters, limits)
+
+	want := map[commons.ID]int{"a": 2, "b": 4, "c": 4}
+	for id, n := range want {
+		if got := len(commons.ImmutableListToSlice(after.AgentState[id].Weapons)); got != n {
+			t.Errorf("agent %s took %d items, want %d", id, got, n)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package referendum
+
+import (
+	"sort"
+	"sync"
+
+	"infra/game/agent"
+	"infra/game/commons"
+	"infra/game/decision"
+	"infra/game/state"
+
+	"golang.org/x/exp/maps"
+)
+
+// Proposal is an amendment and the agent proposing it.
+type Proposal struct {
+	Proposer  commons.ID
+	Amendment decision.Amendment
+}
+
+// Result is the outcome of a referendum.
+type Result struct {
+	// Votes is how each agent voted.
+	Votes map[commons.ID]decision.Intent
+	// Tally is the number of votes cast with each intent.
+	Tally map[decision.Intent]uint
+	// Passed reports whether the amendment passed.
+	Passed bool
+}
+
+// Proposals asks every agent for the amendment it proposes, returning those
+// proposed in proposer ID order.
+func Proposals(gameState *state.State, agents map[commons.ID]agent.Agent) []Proposal {
+	ids := maps.Keys(agents)
+	sort.Strings(ids)
+	var proposals []Proposal
+	for _, id := range ids {
+		a := agents[id]
+		if amendment := a.HandleProposeAmendment(gameState.AgentState[id]); amendment != nil {
+			proposals = append(proposals, Proposal{Proposer: id, Amendment: *amendment})
+		}
+	}
+	return proposals
+}
+
+// HandleReferendum polls every agent on p, concurrently, and decides it by
+// supermajority.
+func HandleReferendum(gameState *state.State, agents map[commons.ID]agent.Agent, p Proposal, supermajority float32) Result {
+	type vote struct {
+		id     commons.ID
+		intent decision.Intent
+	}
+	voteChan := make(chan vote)
+
+	var wg sync.WaitGroup
+	for id, a := range agents {
+		wg.Add(1)
+		go func(id commons.ID, a agent.Agent, agentState state.AgentState) {
+			voteChan <- vote{id: id, intent: a.HandleReferendum(agentState, p.Proposer, p.Amendment)}
+			wg.Done()
+		}(id, a, gameState.AgentState[id])
+	}
+
+	go func() {
+		wg.Wait()
+		close(voteChan)
+	}()
+
+	votes := make(map[commons.ID]decision.Intent, len(agents))
+	for v := range voteChan {
+		votes[v.id] = v.intent
+	}
+	return Count(votes, supermajority)
+}
+
+// Count decides a referendum: the amendment passes if at least supermajority
+// of the votes for and against it are for it. Abstentions are not counted.
+func Count(votes map[commons.ID]decision.Intent, supermajority float32) Result {
+	result := Result{Votes: votes, Tally: make(map[decision.Intent]uint)}
+	for _, intent := range votes {
+		result.Tally[intent]++
+	}
+	votesFor, counted := result.Tally[decision.Positive], result.Tally[decision.Positive]+result.Tally[decision.Negative]
+	// allow for supermajority's float32 rounding, so that 2/3 of 3 is 2
+	result.Passed = votesFor > 0 && float64(votesFor) >= float64(supermajority)*float64(counted)-1e-6
+	return result
+}
@@ -0,0 +1,53 @@
+package referendum_test
+
+import (
+	"testing"
+
+	"infra/game/commons"
+	"infra/game/decision"
+	"infra/game/stage/referendum"
+)
+
+func TestCount(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		votes         map[commons.ID]decision.Intent
+		supermajority float32
+		want          bool
+	}{
+		{
+			name:          "supermajority met exactly",
+			votes:         map[commons.ID]decision.Intent{"a": decision.Positive, "b": decision.Positive, "c": decision.Negative},
+			supermajority: 2.0 / 3,
+			want:          true,
+		},
+		{
+			name:          "majority short of a supermajority",
+			votes:         map[commons.ID]decision.Intent{"a": decision.Positive, "b": decision.Positive, "c": decision.Negative},
+			supermajority: 0.75,
+		},
+		{
+			name:          "abstentions not counted",
+			votes:         map[commons.ID]decision.Intent{"a": decision.Positive, "b": decision.Abstain, "c": decision.Abstain},
+			supermajority: 1,
+			want:          true,
+		},
+		{
+			name:          "no votes",
+			votes:         map[commons.ID]decision.Intent{"a": decision.Abstain},
+			supermajority: 2.0 / 3,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := referendum.Count(tt.votes, tt.supermajority); got.Passed != tt.want {
+				t.Errorf("Count() passed %v, want %v", got.Passed, tt.want)
+			}
+		})
+	}
+}
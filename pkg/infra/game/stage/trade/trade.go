@@ -5,10 +5,15 @@ import (
 	"infra/game/agent"
 	"infra/game/commons"
 	"infra/game/message"
+	"infra/game/rng"
 	"infra/game/stage/trade/internal"
 	"infra/game/state"
 	"infra/logging"
+	"math/rand"
+	"sort"
 	"time"
+
+	"golang.org/x/exp/maps"
 )
 
 // HandleTrade
@@ -17,7 +22,10 @@ import (
 // 1. Each agent can respond to one of the trading negotiations it is involved in OR propose a new trade to another agent.
 // 2. Main thread collects trade messages from all agents, and updated the state accordingly.
 // 3. Collected message will be forwarded to corresponding target agents in the start of next round.
-func HandleTrade(s state.State, agents map[commons.ID]agent.Agent, round uint, roundLimit uint) {
+// An agent that has not responded by the deadline abstains for that round and takes no further part in the stage.
+// A zero deadline waits for every agent.
+// Negotiation IDs are drawn from r.
+func HandleTrade(s state.State, agents map[commons.ID]agent.Agent, round uint, roundLimit uint, deadline time.Duration, r *rand.Rand) {
 	// track offers made by each agent, no repeated offers are allowed
 	// i.e. only one offer of a specific item from an agent to another agent is allowed to exist simultaneously
 	availableWeapons := make(map[commons.ID][]state.Item)
@@ -31,18 +39,23 @@ func HandleTrade(s state.State, agents map[commons.ID]agent.Agent, round uint, r
 		info.Inventory.Shields()[agentID] = commons.ImmutableListToSlice(agentState.Shields)
 	}
 
-	for r := uint(0); r < round; r++ {
+	late := make(map[commons.ID]bool)
+	for roundNum := uint(0); roundNum < round; roundNum++ {
 		starts := make(map[commons.ID]chan interface{})
 		closures := make(map[commons.ID]chan interface{})
 		responses := make(map[commons.ID]chan message.TradeMessage)
 
 		for id, a := range agents {
+			if late[id] {
+				continue
+			}
 			a := a
 			start := make(chan interface{})
 			starts[id] = start
 			closure := make(chan interface{})
 			closures[id] = closure
-			response := make(chan message.TradeMessage)
+			// buffered, so an agent that misses the deadline can still respond and exit
+			response := make(chan message.TradeMessage, 1)
 			responses[id] = response
 
 			go (&a).HandleTrade(s.AgentState[a.BaseAgent.ID()], NewTradeInfo(id, info), start, closure, response)
@@ -51,18 +64,41 @@ func HandleTrade(s state.State, agents map[commons.ID]agent.Agent, round uint, r
 		for _, startMessage := range starts {
 			startMessage <- nil
 		}
-		// handle responses from agents
-		for agentID, response := range responses {
-			negotiation := <-response
-			HandleTradeMessage(agentID, negotiation, info, s.AgentState)
+		var timeout <-chan time.Time
+		if deadline > 0 {
+			timeout = time.After(deadline)
+		}
+		// handle responses from agents in ID order, as earlier messages can claim items first
+		agentIDs := maps.Keys(responses)
+		sort.Strings(agentIDs)
+		expired := false
+		for _, agentID := range agentIDs {
+			var negotiation message.TradeMessage
+			responded := false
+			if !expired {
+				select {
+				case negotiation = <-responses[agentID]:
+					responded = true
+				case <-timeout:
+					expired = true
+				}
+			}
+			if expired && !responded {
+				select {
+				case negotiation = <-responses[agentID]:
+					responded = true
+				default:
+				}
+			}
+			if !responded {
+				late[agentID] = true
+				logging.Log(logging.Warn, logging.LogField{"agentID": agentID, "round": roundNum}, "Agent missed the trade deadline")
+				continue
+			}
+			HandleTradeMessage(agentID, negotiation, info, s.AgentState, r)
 		}
-		// timeout for agents to respond
-		time.Sleep(25 * time.Millisecond)
-		for id, closure := range closures {
-			closure <- nil
+		for _, closure := range closures {
 			close(closure)
-			close(starts[id])
-			close(responses[id])
 		}
 		// filter out outdated negotiations
 		for id, negotiation := range negotiations {
@@ -100,21 +136,23 @@ func NewTradeInfo(agentID commons.ID, info *internal.Info) message.TradeInfo {
 func HandleTradeMessage(agentID commons.ID, negotiation message.TradeMessage,
 	info *internal.Info,
 	agentState map[commons.ID]state.AgentState,
+	r *rand.Rand,
 ) {
 	switch msg := negotiation.(type) {
 	case message.TradeAbstain:
 	case message.TradeResponse:
 		HandleTradeResponse(agentID, msg, info, agentState)
 	case message.TradeRequest:
-		HandleTradeRequest(agentID, msg, info)
+		HandleTradeRequest(agentID, msg, info, rng.NewID(r))
 	}
 }
 
 func HandleTradeRequest(agentID commons.ID, msg message.TradeRequest,
 	info *internal.Info,
+	tradeID commons.TradeID,
 ) {
 	// add new negotiation to ongoing negotiations
-	negotiation := message.NewTradeNegotiation(agentID, msg.CounterPartyID, msg.Offer, msg.Demand)
+	negotiation := message.NewTradeNegotiation(tradeID, agentID, msg.CounterPartyID, msg.Offer, msg.Demand)
 	info.Negotiations()[negotiation.Id] = negotiation
 	// remove offered item from available items
 	if msg.Offer.ItemType == commons.Weapon {
@@ -11,7 +11,7 @@ import (
 	"github.com/benbjohnson/immutable"
 )
 
-func UpdateInternalStates(agentMap map[commons.ID]agent.Agent, globalState *state.State, immutableFightRounds *commons.ImmutableList[decision.ImmutableFightResult], votesResult *immutable.Map[decision.Intent, uint]) map[commons.ID]logging.AgentLog {
+func UpdateInternalStates(agentMap map[commons.ID]agent.Agent, globalState *state.State, immutableFightRounds *commons.ImmutableList[decision.ImmutableFightResult], votesResult *immutable.Map[decision.Intent, uint], electionResult *decision.ElectionResult) map[commons.ID]logging.AgentLog {
 	var wg sync.WaitGroup
 	agentLogChan := make(chan logging.AgentLog)
 	for id, a := range agentMap {
@@ -19,20 +19,22 @@ func UpdateInternalStates(agentMap map[commons.ID]agent.Agent, globalState *stat
 		a := a
 		wg.Add(1)
 		go func(wait *sync.WaitGroup) {
-			a.HandleUpdateInternalState(globalState.AgentState[id], immutableFightRounds, votesResult, agentLogChan)
+			a.HandleUpdateInternalState(globalState.AgentState[id], immutableFightRounds, votesResult, electionResult, agentLogChan)
 			wait.Done()
 		}(&wg)
 	}
 
 	agentLogs := make(map[commons.ID]logging.AgentLog)
+	collected := make(chan struct{})
 	go func(agentLogChan chan logging.AgentLog, agentLogs map[commons.ID]logging.AgentLog) {
 		for log := range agentLogChan {
 			agentLogs[log.ID] = log
 		}
+		close(collected)
 	}(agentLogChan, agentLogs)
 	wg.Wait()
-	// fmt.Println(agentLogs)
 	close(agentLogChan)
-	// fmt.Println(agentLogs)
+	// wait for the last logs to be stored, otherwise they may be missing from the output
+	<-collected
 	return agentLogs
 }
@@ -6,6 +6,7 @@ import (
 	"infra/game/commons"
 	"infra/game/decision"
 	"infra/game/message"
+	"infra/game/rng"
 	"infra/game/stage/fight"
 	"infra/game/stage/initialise"
 	"infra/game/stage/loot"
@@ -13,19 +14,17 @@ import (
 	"infra/game/state"
 	"infra/game/tally"
 	"infra/logging"
-	"time"
+	"sort"
 
 	"github.com/benbjohnson/immutable"
+	"golang.org/x/exp/maps"
 	//? Add you team folder like this:
 	// t0 "infra/teams/team0"
 	// t1 "infra/teams/team1"
 )
 
-// Mode ? Changed at compile time. eg change in .env to `MODE=0` to set this to '0'.
-var Mode string
-
-func ChooseDefaultStrategyMap(defaultStrategyMap map[commons.ID]func() agent.Strategy) map[commons.ID]func() agent.Strategy {
-	switch Mode {
+func ChooseDefaultStrategyMap(mode string, defaultStrategyMap map[commons.ID]func() agent.Strategy) map[commons.ID]func() agent.Strategy {
+	switch mode {
 	// case "0":
 	// 	return t0.InitAgentMap
 	// case "1":
@@ -35,119 +34,136 @@ func ChooseDefaultStrategyMap(defaultStrategyMap map[commons.ID]func() agent.Str
 	}
 }
 
-func InitGameConfig() config.GameConfig {
-	switch Mode {
+// LoadConfig reads the configuration file at path (see config.Load).
+func LoadConfig(mode string, path string, dotenv config.Env) (config.File, error) {
+	switch mode {
 	case "0":
-		return initialise.InitGameConfig() // ? Can choose to just call the default function
+		return config.Load(path, dotenv) // ? Can choose to just call the default function
 	default:
-		return initialise.InitGameConfig()
+		return config.Load(path, dotenv)
 	}
 }
 
-func InitAgents(defaultStrategyMap map[commons.ID]func() agent.Strategy, gameConfig config.GameConfig, ptr *state.View) (numAgents uint, agentMap map[commons.ID]agent.Agent, agentStateMap map[commons.ID]state.AgentState, inventoryMap state.InventoryMap) {
-	switch Mode {
+func InitAgents(mode string, defaultStrategyMap map[commons.ID]func() agent.Strategy, gameConfig config.GameConfig, ptr *state.View, source *rng.Source) (numAgents uint, agentMap map[commons.ID]agent.Agent, agentStateMap map[commons.ID]state.AgentState, inventoryMap state.InventoryMap) {
+	switch mode {
 	// case "0":
-	// 	return t0.InitAgents(defaultStrategyMap, gameConfig, ptr)
+	// 	return t0.InitAgents(defaultStrategyMap, gameConfig, ptr, source)
 	// case "1":
-	// 	return t1.InitAgents(defaultStrategyMap, gameConfig, ptr)
+	// 	return t1.InitAgents(defaultStrategyMap, gameConfig, ptr, source)
 	default:
-		return initialise.InitAgents(defaultStrategyMap, gameConfig, ptr)
+		return initialise.InitAgents(defaultStrategyMap, gameConfig, ptr, source)
 	}
 }
 
-func AgentLootDecisions(globalState state.State, availableLoot state.LootPool, agents map[commons.ID]agent.Agent, channelsMap map[commons.ID]chan message.TaggedMessage) *tally.Tally[decision.LootAction] {
-	switch Mode {
+func AgentLootDecisions(mode string, globalState state.State, availableLoot state.LootPool, agents map[commons.ID]agent.Agent, rounds agent.Rounds) *tally.Tally[decision.LootAction] {
+	switch mode {
 	default:
-		return loot.AgentLootDecisions(globalState, availableLoot, agents, channelsMap)
+		return loot.AgentLootDecisions(globalState, availableLoot, agents, rounds)
 	}
 }
 
-func AgentFightDecisions(state state.State, agents map[commons.ID]agent.Agent, previousDecisions immutable.Map[commons.ID, decision.FightAction], channelsMap map[commons.ID]chan message.TaggedMessage) *tally.Tally[decision.FightAction] {
-	switch Mode {
+func AgentFightDecisions(mode string, state state.State, agents map[commons.ID]agent.Agent, previousDecisions immutable.Map[commons.ID, decision.FightAction], rounds agent.Rounds) *tally.Tally[decision.FightAction] {
+	switch mode {
 	// case "0":
 	// 	//? Not necessary to use all function arguments
 	// 	return t0.AllDefend(agents)
 	default:
-		return fight.AgentFightDecisions(state, agents, previousDecisions, channelsMap)
+		return fight.AgentFightDecisions(state, agents, previousDecisions, rounds)
 	}
 }
 
-func UpdateInternalStates(agentMap map[commons.ID]agent.Agent, globalState *state.State, immutableFightRounds *commons.ImmutableList[decision.ImmutableFightResult], votesResult *immutable.Map[decision.Intent, uint]) map[commons.ID]logging.AgentLog {
-	switch Mode {
+func UpdateInternalStates(mode string, agentMap map[commons.ID]agent.Agent, globalState *state.State, immutableFightRounds *commons.ImmutableList[decision.ImmutableFightResult], votesResult *immutable.Map[decision.Intent, uint], electionResult *decision.ElectionResult) map[commons.ID]logging.AgentLog {
+	switch mode {
 	// case "1":
 	// 	return t1.UpdateInternalStates(agentMap, globalState, immutableFightRounds, votesResult)
 	default:
-		return update.UpdateInternalStates(agentMap, globalState, immutableFightRounds, votesResult)
+		return update.UpdateInternalStates(agentMap, globalState, immutableFightRounds, votesResult, electionResult)
 	}
 }
 
-func HandleTrustStage(agentMap map[commons.ID]agent.Agent, channelsMap map[commons.ID]chan message.TaggedMessage) {
-	closures := make(map[commons.ID]chan<- struct{})
-
+// HandleTrustStage has every agent send its trust message and handle those it
+// receives. It returns the reputation the messages give each living agent
+// they mention: the mean of what they said of it.
+func HandleTrustStage(agentMap map[commons.ID]agent.Agent, rounds agent.Rounds) map[commons.ID]float64 {
 	// SEND ALL MESSAGES OUT
-	for _, a := range agentMap {
+	// in ID order, so that every agent receives its trust messages in the same order on every run
+	gossip := make(map[commons.ID][]float64)
+	senderIDs := maps.Keys(agentMap)
+	sort.Strings(senderIDs)
+	for _, senderID := range senderIDs {
+		a := agentMap[senderID]
 		msg := a.Strategy.CompileTrustMessage(agentMap)
 		senderList := msg.Recipients
 
+		sent := false
 		for _, ag := range senderList {
-			// fmt.Println("SENDING:")
 			if a.ID() == ag {
 				continue
 			}
-			a.SendBlockingMessage(ag, msg)
+			if a.SendBlockingMessage(ag, msg) == nil {
+				sent = true
+			}
+		}
+		if !sent {
+			continue
+		}
+		for id, rep := range msg.Gossip {
+			if _, alive := agentMap[id]; alive {
+				gossip[id] = append(gossip[id], rep)
+			}
 		}
 	}
 
-	for id, a := range agentMap {
-		a := a
-		closure := make(chan struct{})
-		closures[id] = closure
-
-		go (&a).HandleTrust(closure)
-	}
-
-	// timeout for agents to respond
-	time.Sleep(25 * time.Millisecond)
-	for _, closure := range closures {
-		closure <- struct{}{}
-		close(closure)
-	}
-
-	for _, c := range channelsMap {
-		close(c)
+	rounds.Run(agentMap, nil,
+		func(a *agent.Agent, inbox []message.TaggedMessage) {
+			a.HandleTrust(inbox)
+		},
+		func([]commons.ID) {},
+	)
+
+	reputation := make(map[commons.ID]float64, len(gossip))
+	for id, reps := range gossip {
+		// summed in sender ID order, so the mean is the same on every run
+		sum := 0.0
+		for _, rep := range reps {
+			sum += rep
+		}
+		reputation[id] = sum / float64(len(reps))
 	}
+	return reputation
 }
 
-func AgentPruneMapping(agentMap map[commons.ID]agent.Agent, globalState *state.State) map[commons.ID]agent.Agent {
-	leaderId := globalState.CurrentLeader
-	leader, leaderIsAlive := agentMap[leaderId]
+// AgentPruneMapping is the agents the quartermaster, who is never sanctioned
+// itself, has not sanctioned out of the loot.
+func AgentPruneMapping(agentMap map[commons.ID]agent.Agent, quartermaster commons.ID) map[commons.ID]agent.Agent {
+	holder, holderIsAlive := agentMap[quartermaster]
 
-	if leaderIsAlive {
-		prunedMap := leader.PruneAgentList(agentMap)
-		prunedMap[leaderId] = leader
+	if holderIsAlive {
+		prunedMap := holder.PruneAgentList(*holder.BaseAgent, agentMap)
+		prunedMap[quartermaster] = holder
 
 		return prunedMap
 	}
-	// leader has died, hence no sanctioning
+	// quartermaster has died, hence no sanctioning
 	return agentMap
 
 }
 
-func AgentMapToSortedArray(prunedMap map[commons.ID]agent.Agent, globalState *state.State) []agent.Agent {
-	leaderId := globalState.CurrentLeader
-	leader, leaderIsAlive := prunedMap[leaderId]
+// AgentMapToSortedArray is the order the quartermaster has the agents in
+// prunedMap choose loot in, by ID if it has died.
+func AgentMapToSortedArray(prunedMap map[commons.ID]agent.Agent, quartermaster commons.ID) []agent.Agent {
+	holder, holderIsAlive := prunedMap[quartermaster]
 
-	if leaderIsAlive {
-		prunedArray := leader.SortAgentsArray(prunedMap)
+	if holderIsAlive {
+		prunedArray := holder.SortAgentsArray(prunedMap)
 		return prunedArray
 	}
 
-	defaultArray := make([]agent.Agent, len(prunedMap))
-
-	idx := 0
-	for _, ag := range prunedMap {
-		defaultArray[idx] = ag
-		idx++
+	ids := maps.Keys(prunedMap)
+	sort.Strings(ids)
+	defaultArray := make([]agent.Agent, len(ids))
+	for idx, id := range ids {
+		defaultArray[idx] = prunedMap[id]
 	}
 	return defaultArray
 }
@@ -0,0 +1,12 @@
+package state
+
+import "infra/game/commons"
+
+// Corpse is what an agent killed in a fight leaves behind, until it is
+// looted or cleared away.
+type Corpse struct {
+	ID      commons.ID
+	Weapons []Item
+	Shields []Item
+	Stamina uint
+}
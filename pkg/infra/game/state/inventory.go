@@ -13,6 +13,17 @@ type InventoryMap struct {
 	Weapons map[commons.ItemID]uint
 }
 
+// Remove takes the weapons and shields of agentState, eg. an agent that has
+// died, out of the map.
+func (m InventoryMap) Remove(agentState AgentState) {
+	for _, w := range commons.ImmutableListToSlice(agentState.Weapons) {
+		delete(m.Weapons, w.Id())
+	}
+	for _, s := range commons.ImmutableListToSlice(agentState.Shields) {
+		delete(m.Shields, s.Id())
+	}
+}
+
 // Add an InventoryItem to an immutable list of InventoryItem.
 // return a sorted immutable.List with 0th InventoryItem has the greatest value.
 func addToInventory(items immutable.List[Item], item Item) immutable.List[Item] {
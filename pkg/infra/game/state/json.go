@@ -0,0 +1,112 @@
+package state
+
+import (
+	"encoding/json"
+
+	"infra/game/commons"
+)
+
+// JSON encodings, so that a State can be written to and read back from a
+// checkpoint.
+
+type itemJSON struct {
+	ID    commons.ItemID
+	Value uint
+	Name  ItemName
+}
+
+func (i Item) MarshalJSON() ([]byte, error) {
+	return json.Marshal(itemJSON{ID: i.id, Value: i.value, Name: i.name})
+}
+
+func (i *Item) UnmarshalJSON(data []byte) error {
+	var j itemJSON
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	*i = Item{id: j.ID, value: j.Value, name: j.Name}
+	return nil
+}
+
+type defectorJSON struct {
+	Fight bool
+	Loot  bool
+}
+
+func (d Defector) MarshalJSON() ([]byte, error) {
+	return json.Marshal(defectorJSON{Fight: d.fight, Loot: d.loot})
+}
+
+func (d *Defector) UnmarshalJSON(data []byte) error {
+	var j defectorJSON
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	*d = Defector{fight: j.Fight, loot: j.Loot}
+	return nil
+}
+
+type agentStateJSON struct {
+	Hp          uint
+	Stamina     uint
+	Attack      uint
+	Defense     uint
+	WeaponInUse commons.ItemID
+	ShieldInUse commons.ItemID
+	Weapons     []Item
+	Shields     []Item
+	Defector    Defector
+}
+
+func (s AgentState) MarshalJSON() ([]byte, error) {
+	return json.Marshal(agentStateJSON{
+		Hp:          s.Hp,
+		Stamina:     s.Stamina,
+		Attack:      s.Attack,
+		Defense:     s.Defense,
+		WeaponInUse: s.WeaponInUse,
+		ShieldInUse: s.ShieldInUse,
+		Weapons:     commons.ImmutableListToSlice(s.Weapons),
+		Shields:     commons.ImmutableListToSlice(s.Shields),
+		Defector:    s.Defector,
+	})
+}
+
+func (s *AgentState) UnmarshalJSON(data []byte) error {
+	var j agentStateJSON
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	*s = AgentState{
+		Hp:          j.Hp,
+		Stamina:     j.Stamina,
+		Attack:      j.Attack,
+		Defense:     j.Defense,
+		WeaponInUse: j.WeaponInUse,
+		ShieldInUse: j.ShieldInUse,
+		Weapons:     commons.ListToImmutableList(j.Weapons),
+		Shields:     commons.ListToImmutableList(j.Shields),
+		Defector:    j.Defector,
+	}
+	return nil
+}
+
+type lootPoolJSON struct {
+	Weapons        *commons.ImmutableList[Item]
+	Shields        *commons.ImmutableList[Item]
+	HpPotions      *commons.ImmutableList[Item]
+	StaminaPotions *commons.ImmutableList[Item]
+}
+
+func (l LootPool) MarshalJSON() ([]byte, error) {
+	return json.Marshal(lootPoolJSON{Weapons: l.weapons, Shields: l.shields, HpPotions: l.hpPotions, StaminaPotions: l.staminaPotions})
+}
+
+func (l *LootPool) UnmarshalJSON(data []byte) error {
+	var j lootPoolJSON
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	*l = *NewLootPool(j.Weapons, j.Shields, j.HpPotions, j.StaminaPotions)
+	return nil
+}
@@ -0,0 +1,59 @@
+package state
+
+import "sort"
+
+// Ability is a special ability a monster may have on a level.
+type Ability string
+
+const (
+	// StaminaDrain drains the stamina of every agent that fights, by the
+	// ability's strength each round.
+	StaminaDrain Ability = "stamina-drain"
+	// TargetWeakest turns the strength, a fraction, of the monster's damage
+	// on the fighting agent with the least HP.
+	TargetWeakest Ability = "target-weakest"
+	// TargetLeader turns the strength, a fraction, of the monster's damage
+	// on the leader, when they fight.
+	TargetLeader Ability = "target-leader"
+	// Armour ignores the strength, a fraction, of the agents' attack.
+	Armour Ability = "armour"
+	// AreaDamage deals the strength, a fraction, of the monster's attack to
+	// every agent, ignoring shields.
+	AreaDamage Ability = "area-damage"
+	// Regeneration restores the strength in health to the monster after each
+	// round it survives.
+	Regeneration Ability = "regeneration"
+)
+
+// Abilities lists every ability, in the order they are drawn.
+var Abilities = []Ability{StaminaDrain, TargetWeakest, TargetLeader, Armour, AreaDamage, Regeneration}
+
+// MonsterAbilities are a monster's abilities, each with its strength.
+type MonsterAbilities map[Ability]float64
+
+// Has reports whether the monster has ability a.
+func (m MonsterAbilities) Has(a Ability) bool {
+	_, ok := m[a]
+	return ok
+}
+
+// Sorted lists the monster's abilities by name.
+func (m MonsterAbilities) Sorted() []Ability {
+	abilities := make([]Ability, 0, len(m))
+	for a := range m {
+		abilities = append(abilities, a)
+	}
+	sort.Slice(abilities, func(i, j int) bool { return abilities[i] < abilities[j] })
+	return abilities
+}
+
+func (m MonsterAbilities) clone() MonsterAbilities {
+	if m == nil {
+		return nil
+	}
+	c := make(MonsterAbilities, len(m))
+	for a, strength := range m {
+		c[a] = strength
+	}
+	return c
+}
@@ -62,3 +62,37 @@ func (l LootPool) StaminaPotions() *commons.ImmutableList[Item] {
 func NewLootPool(weapons *commons.ImmutableList[Item], shields *commons.ImmutableList[Item], hpPotions *commons.ImmutableList[Item], staminaPotions *commons.ImmutableList[Item]) *LootPool {
 	return &LootPool{weapons: weapons, shields: shields, hpPotions: hpPotions, staminaPotions: staminaPotions}
 }
+
+// With returns the pool with items added, each to the list of its kind.
+func (l LootPool) With(items []Item) *LootPool {
+	lists := map[ItemName][]Item{
+		SWORD:          listItems(l.weapons),
+		SHIELD:         listItems(l.shields),
+		HP_POTION:      listItems(l.hpPotions),
+		STAMINA_POTION: listItems(l.staminaPotions),
+	}
+	for _, item := range items {
+		if list, ok := lists[item.name]; ok {
+			lists[item.name] = append(list, item)
+		}
+	}
+	return NewLootPool(
+		commons.NewImmutableList(lists[SWORD]),
+		commons.NewImmutableList(lists[SHIELD]),
+		commons.NewImmutableList(lists[HP_POTION]),
+		commons.NewImmutableList(lists[STAMINA_POTION]),
+	)
+}
+
+func listItems(list *commons.ImmutableList[Item]) []Item {
+	if list == nil {
+		return nil
+	}
+	items := make([]Item, 0, list.Len())
+	iterator := list.Iterator()
+	for !iterator.Done() {
+		item, _ := iterator.Next()
+		items = append(items, item)
+	}
+	return items
+}
@@ -1,8 +1,10 @@
 package state
 
 import (
+	cmdline "infra/cmdLine"
 	"infra/game/commons"
 	"infra/game/decision"
+	sanctions "infra/sanctionUtils"
 
 	"github.com/benbjohnson/immutable"
 )
@@ -108,13 +110,34 @@ func (s *AgentState) ChangeShieldInUse(shieldIdx decision.ItemIdx) {
 }
 
 type State struct {
-	CurrentLevel    uint
-	HpPool          uint
-	MonsterHealth   uint
-	MonsterAttack   uint
-	AgentState      map[commons.ID]AgentState
-	InventoryMap    InventoryMap
-	CurrentLeader   commons.ID
-	LeaderManifesto decision.Manifesto
-	Defection       bool
+	CurrentLevel  uint
+	HpPool        uint
+	MonsterHealth uint
+	MonsterAttack uint
+	// MonsterAbilities are the abilities drawn for the level's monster.
+	MonsterAbilities MonsterAbilities
+	AgentState       map[commons.ID]AgentState
+	InventoryMap     InventoryMap
+	CurrentLeader    commons.ID
+	LeaderManifesto  decision.Manifesto
+	Defection        bool
+	SanctionConfig   cmdline.CmdLine
+	SanctionLedger   *sanctions.Ledger
+	// ConfidenceVotes are how each agent voted in the last no-confidence
+	// motion, when votes are public.
+	ConfidenceVotes map[commons.ID]decision.Intent
+	// Corpses are the agents killed in fights whose inventory has yet to be
+	// looted.
+	Corpses []Corpse
+	// Contributions are the HP each agent has donated to the HP pool so far.
+	Contributions map[commons.ID]uint
+	// Reputation is what the last trust messages said of each agent, on
+	// average.
+	Reputation map[commons.ID]float64
+	// VoteCredits are the credits each agent has left to spend on votes this
+	// term, under quadratic voting.
+	VoteCredits map[commons.ID]uint
+	// Cabinet is who holds each of the leader's delegated roles, empty
+	// without a cabinet.
+	Cabinet map[decision.Role]commons.ID
 }
@@ -1,20 +1,30 @@
 package state
 
 import (
+	"sort"
+
+	cmdline "infra/cmdLine"
 	"infra/game/commons"
 	"infra/game/decision"
+	sanctions "infra/sanctionUtils"
 
 	"github.com/benbjohnson/immutable"
+	"golang.org/x/exp/maps"
 )
 
 type View struct {
-	currentLevel    uint
-	hpPool          uint
-	monsterHealth   uint
-	monsterAttack   uint
-	agentState      *immutable.Map[commons.ID, HiddenAgentState]
-	currentLeader   commons.ID
-	leaderManifesto decision.Manifesto
+	currentLevel     uint
+	hpPool           uint
+	monsterHealth    uint
+	monsterAttack    uint
+	monsterAbilities MonsterAbilities
+	agentState       *immutable.Map[commons.ID, HiddenAgentState]
+	currentLeader    commons.ID
+	leaderManifesto  decision.Manifesto
+	sanctionConfig   cmdline.CmdLine
+	sanctionLedger   *sanctions.Ledger
+	confidenceVotes  immutable.Map[commons.ID, decision.Intent]
+	cabinet          immutable.Map[decision.Role, commons.ID]
 }
 
 type (
@@ -61,6 +71,12 @@ func (v *View) MonsterAttack() uint {
 	return v.monsterAttack
 }
 
+// MonsterAbilities are the abilities of the level's monster, so that
+// strategies can react to them.
+func (v *View) MonsterAbilities() MonsterAbilities {
+	return v.monsterAbilities.clone()
+}
+
 func (v *View) AgentState() immutable.Map[commons.ID, HiddenAgentState] {
 	return *v.agentState
 }
@@ -73,10 +89,37 @@ func (v *View) LeaderManifesto() decision.Manifesto {
 	return v.leaderManifesto
 }
 
+// ConfidenceVotes are how each agent voted in the last no-confidence motion
+// when votes are public, and empty when they are secret.
+func (v *View) ConfidenceVotes() immutable.Map[commons.ID, decision.Intent] {
+	return v.confidenceVotes
+}
+
+// Cabinet is who holds each of the leader's delegated roles, empty without a
+// cabinet. The leader holds any role missing from it or whose holder has
+// died.
+func (v *View) Cabinet() immutable.Map[decision.Role, commons.ID] {
+	return v.cabinet
+}
+
+func (v *View) SanctionConfig() cmdline.CmdLine {
+	return v.sanctionConfig
+}
+
+// SanctionLedger is shared by every leader of the game, so that sanctions
+// can persist across leadership when SanctionConfig().PersistentSanctions is set.
+func (v *View) SanctionLedger() *sanctions.Ledger {
+	return v.sanctionLedger
+}
+
 func (s *State) ToView() View {
 	b := immutable.NewMapBuilder[commons.ID, HiddenAgentState](nil)
 
-	for uuid, state := range s.AgentState {
+	// in ID order, as small immutable maps iterate in insertion order
+	ids := maps.Keys(s.AgentState)
+	sort.Strings(ids)
+	for _, uuid := range ids {
+		state := s.AgentState[uuid]
 		healthRange := (state.Hp / uint(HealthQuant)) * uint(HealthQuant)
 
 		staminaRange := (state.Stamina / uint(StaminaQuant)) * uint(StaminaQuant)
@@ -93,12 +136,17 @@ func (s *State) ToView() View {
 	}
 
 	return View{
-		currentLevel:    s.CurrentLevel,
-		hpPool:          s.HpPool,
-		monsterHealth:   s.MonsterHealth,
-		monsterAttack:   s.MonsterAttack,
-		agentState:      b.Map(),
-		currentLeader:   s.CurrentLeader,
-		leaderManifesto: s.LeaderManifesto,
+		currentLevel:     s.CurrentLevel,
+		hpPool:           s.HpPool,
+		monsterHealth:    s.MonsterHealth,
+		monsterAttack:    s.MonsterAttack,
+		monsterAbilities: s.MonsterAbilities.clone(),
+		agentState:       b.Map(),
+		currentLeader:    s.CurrentLeader,
+		leaderManifesto:  s.LeaderManifesto,
+		sanctionConfig:   s.SanctionConfig,
+		sanctionLedger:   s.SanctionLedger,
+		confidenceVotes:  commons.MapToImmutable(s.ConfidenceVotes),
+		cabinet:          commons.MapToImmutable(s.Cabinet),
 	}
 }
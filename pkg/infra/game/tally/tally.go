@@ -43,20 +43,31 @@ func (t *Tally[A]) HandleMessages() {
 	for {
 		select {
 		case p := <-t.proposals:
-			t.proposalMap[p.ProposalID()] = p.Rules()
-			t.proposalTally[p.ProposalID()] = 0
+			t.Submit(p)
 		case vote := <-t.votes:
-			t.proposalTally[vote]++
-			if t.currMax.Count < t.proposalTally[vote] {
-				t.currMax.ID = vote
-				t.currMax.Count = t.proposalTally[vote]
-			}
+			t.Vote(vote)
 		case <-t.closure:
 			return
 		}
 	}
 }
 
+// Submit puts a proposal up for the vote.
+func (t *Tally[A]) Submit(p message.Proposal[A]) {
+	t.proposalMap[p.ProposalID()] = p.Rules()
+	t.proposalTally[p.ProposalID()] = 0
+}
+
+// Vote counts a vote for a proposal. Ties go to the proposal that reached the
+// count first, so votes should be cast in a deterministic order.
+func (t *Tally[A]) Vote(vote commons.ProposalID) {
+	t.proposalTally[vote]++
+	if t.currMax.Count < t.proposalTally[vote] {
+		t.currMax.ID = vote
+		t.currMax.Count = t.proposalTally[vote]
+	}
+}
+
 // GetMax call from thread after goroutine closes.
 func (t *Tally[A]) GetMax() message.Proposal[A] {
 	return *message.NewProposalInternal(t.currMax.ID, t.proposalMap[t.currMax.ID])
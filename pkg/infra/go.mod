@@ -9,6 +9,7 @@ require (
 	github.com/google/uuid v1.3.0
 	github.com/joho/godotenv v1.4.0
 	golang.org/x/exp v0.0.0-20220518171630-0b5c67f07fdf
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
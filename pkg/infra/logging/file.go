@@ -5,7 +5,7 @@ import (
 	"fmt"
 	"infra/game/commons"
 	"os"
-	"path"
+	"path/filepath"
 )
 
 // type LevelStage uint
@@ -17,9 +17,6 @@ import (
 // 	HPPool
 // )
 
-var fileLog GameLog
-var runID string
-
 type Outcome bool
 
 const (
@@ -46,9 +43,31 @@ type GameLog struct {
 	Outcome  Outcome
 	Config   Config
 	Levels   []LevelStages
+	// Amendments are the amendments to the game's institutional rules
+	// proposed in referendums, in the order they were put.
+	Amendments []Amendment `json:",omitempty"`
+}
+
+// Amendment is an amendment to one of the game's institutional rules and
+// how the referendum on it went.
+type Amendment struct {
+	Level    uint
+	Proposer commons.ID
+	Setting  string
+	Value    string
+	// Rejected is why the amendment was not put to a vote, if it was not.
+	Rejected string `json:",omitempty"`
+	For      uint
+	Against  uint
+	Abstain  uint
+	// Passed reports whether the amendment passed, to take effect from the
+	// next level.
+	Passed bool
 }
 
 type Config struct {
+	RunID             string
+	Seed              int64
 	Mode              Mode
 	Levels            uint
 	StartingHP        uint
@@ -73,8 +92,10 @@ type LevelStages struct {
 	VONCStage     VONCStage
 	FightStage    FightStage
 	LootStage     LootStage
-	HPPoolStage   HPPoolStage
-	AgentLogs     map[commons.ID]AgentLog
+	// CorpseLootStage is empty unless the dead were looted after the fight.
+	CorpseLootStage CorpseLootStage
+	HPPoolStage     HPPoolStage
+	AgentLogs       map[commons.ID]AgentLog
 }
 
 type AgentLog struct {
@@ -90,6 +111,8 @@ type LevelStats struct {
 	HPPool               uint
 	MonsterHealth        uint
 	MonsterAttack        uint
+	// MonsterAbilities are the strengths of the monster's abilities.
+	MonsterAbilities     map[string]float64 `json:",omitempty"`
 	LeaderBeforeElection commons.ID
 	LeaderAfterElection  commons.ID
 	AverageAgentHealth   uint
@@ -103,6 +126,65 @@ type ElectionStage struct {
 	Winner    commons.ID
 	Team      string
 	Manifesto ManifestoLog
+	// Candidates are the manifestos every candidate stood on.
+	Candidates map[commons.ID]ManifestoLog
+	// Tally is what each candidate counted for the result (see
+	// decision.ElectionResult).
+	Tally map[commons.ID]float64
+	// Electorate is the number of agents asked to vote, of which Ballots
+	// named at least one candidate and Abstentions none. Turnout is the
+	// fraction that did.
+	Electorate  uint
+	Ballots     uint
+	Abstentions uint
+	Turnout     float64
+	// Weights is how much each voter's ballot counted, if they were
+	// weighted.
+	Weights map[commons.ID]float64 `json:",omitempty"`
+	// Margin is how far the winner finished ahead of the runner-up, in votes,
+	// points or score depending on the voting strategy.
+	Margin float64
+	// Rounds are the counts of an instant runoff or two-round election,
+	// round by round.
+	Rounds []RunoffRound `json:",omitempty"`
+	// Revised are the runoff candidates of a two-round election who revised
+	// their manifestos.
+	Revised []commons.ID `json:",omitempty"`
+	// Condorcet is the head-to-head count of a Copeland election.
+	Condorcet *CondorcetCount `json:",omitempty"`
+	// Campaign is what was promised and endorsed before the vote.
+	Campaign *CampaignLog `json:",omitempty"`
+	// Cabinet is who was appointed or elected to each of the leader's
+	// delegated roles, empty without a cabinet.
+	Cabinet map[string]commons.ID `json:",omitempty"`
+}
+
+// CondorcetCount is the head-to-head count of a Copeland election.
+type CondorcetCount struct {
+	// Pairwise holds, for each pair of candidates, the number of ballots
+	// ranking the first above the second.
+	Pairwise map[commons.ID]map[commons.ID]uint
+	// Copeland is each candidate's head-to-head wins, plus a half for each
+	// tie.
+	Copeland map[commons.ID]float64
+	// Cycle is a cycle of head-to-head wins among the candidates, each
+	// beating the next and the last the first, when there is no Condorcet
+	// winner.
+	Cycle []commons.ID `json:",omitempty"`
+	// Completion is the rule that picked the winner without a Condorcet
+	// winner: "copeland", or "copeland, random" when Copeland scores tied.
+	Completion string `json:",omitempty"`
+}
+
+// RunoffRound is a round of an instant runoff or two-round count.
+type RunoffRound struct {
+	// Votes are the ballots counted for each candidate still standing.
+	Votes map[commons.ID]uint
+	// Exhausted is the number of ballots ranking no candidate still standing.
+	Exhausted uint `json:",omitempty"`
+	// Eliminated is the candidate knocked out in the round, empty in the
+	// last.
+	Eliminated commons.ID `json:",omitempty"`
 }
 
 type ManifestoLog struct {
@@ -110,6 +192,38 @@ type ManifestoLog struct {
 	LootImposition      bool
 	TermLength          uint
 	ThresholdPercentage uint
+	// The policies the manifesto commits the leader to, if any.
+	PoliciesLog
+}
+
+// PoliciesLog records the policies of a manifesto or campaign promise (see
+// decision.Policies).
+type PoliciesLog struct {
+	HPPoolTax          uint   `json:",omitempty"`
+	LootAllocation     string `json:",omitempty"`
+	Sanctions          string `json:",omitempty"`
+	MaxLeaderLootShare uint   `json:",omitempty"`
+}
+
+// CampaignLog records the campaign before an election.
+type CampaignLog struct {
+	// Promises are the candidates' promises, in the order they were made.
+	Promises []PromiseLog `json:",omitempty"`
+	// Endorsements lists each candidate's endorsers in ID order.
+	Endorsements map[commons.ID][]commons.ID `json:",omitempty"`
+	// Questions is how many questions voters put to candidates.
+	Questions uint
+}
+
+// PromiseLog is a promise a candidate made in the campaign round Round.
+type PromiseLog struct {
+	Candidate commons.ID
+	Round     uint
+	Statement string `json:",omitempty"`
+	PoliciesLog
+	// Kept reports whether the manifesto the candidate stood on at the vote
+	// commits to every policy it promised.
+	Kept bool
 }
 
 type VONCStage struct {
@@ -118,11 +232,47 @@ type VONCStage struct {
 	Against   uint
 	Abstain   uint
 	Threshold uint
+	// Quorate reports whether enough votes were counted for the motion to
+	// stand, and Passed whether it ousted the leader.
+	Quorate bool
+	Passed  bool
+	// Weights is how much each agent's vote counted, and WeightFor and
+	// WeightAgainst how much the votes for and against the leader did, if
+	// votes were weighted.
+	Weights       map[commons.ID]float64 `json:",omitempty"`
+	WeightFor     float64                `json:",omitempty"`
+	WeightAgainst float64                `json:",omitempty"`
+	// Votes are how each agent voted, "for", "against" or "abstain", logged
+	// only when votes are public.
+	Votes map[commons.ID]string `json:",omitempty"`
+	// Cabinet are the motions held on the cabinet's members after the
+	// leader survived its own.
+	Cabinet []CabinetMotion `json:",omitempty"`
+}
+
+// CabinetMotion is a no-confidence motion on the holder of one of the
+// leader's delegated roles.
+type CabinetMotion struct {
+	Role    string
+	Holder  commons.ID
+	For     uint
+	Against uint
+	Abstain uint
+	Quorate bool
+	Passed  bool
+	// Successor is who took the role over from an ousted holder.
+	Successor commons.ID `json:",omitempty"`
 }
 
 type FightStage struct {
 	Occurred bool
 	Rounds   []FightLog
+	// Stalemate is the consequence applied when the fight rounds ran out,
+	// empty if they did not.
+	Stalemate string `json:",omitempty"`
+	// StalematePenalty is the HP, or for a retreat the HP pool, lost to the
+	// stalemate.
+	StalematePenalty uint `json:",omitempty"`
 }
 
 type FightLog struct {
@@ -132,53 +282,104 @@ type FightLog struct {
 	AttackSum       uint
 	ShieldSum       uint
 	AgentsRemaining uint
+	// DamageTaken is the damage each agent took in the round.
+	DamageTaken map[commons.ID]uint `json:",omitempty"`
+	// Abilities are what the monster's abilities did in the round: the
+	// attack absorbed by armour, the stamina drained and otherwise the
+	// damage dealt or health regained.
+	Abilities map[string]uint `json:",omitempty"`
 }
 
 type LootStage struct {
 	Occurred bool
 }
 
+// CorpseLootStage records what the agents killed in a fight left to loot.
+type CorpseLootStage struct {
+	Occurred bool
+	Corpses  uint
+	Items    uint
+}
+
+func (s CorpseLootStage) Merge(l *LevelStages) {
+	l.CorpseLootStage.Occurred = l.CorpseLootStage.Occurred || s.Occurred
+	l.CorpseLootStage.Corpses += s.Corpses
+	l.CorpseLootStage.Items += s.Items
+}
+
 type HPPoolStage struct {
 	Occurred         bool
 	DonatedThisRound uint
 	OldHPPool        uint
 	NewHPPool        uint
+	// Taxed is how much of the donations the leader's HP pool tax took
+	// beyond what the agents offered.
+	Taxed uint `json:",omitempty"`
 }
 
-func AgentLogToFile(fields LogField, msg string) {
-	agentLog := AgentLog{}
-	for k, v := range fields {
-		if k == "agentName" {
-			if name, ok := v.(string); ok {
-				agentLog.Name = name
-			}
-		} else if k == "agentID" {
-			if id, ok := v.(string); ok {
-				agentLog.ID = id
-			}
-		} else {
-			if num, ok := v.(float32); ok {
-				agentLog.Properties[k] = num
-			}
-		}
+// SkippedThroughHpPool records that the HP pool killed the monster.
+type SkippedThroughHpPool bool
+
+// AgentLogs are the agents' own reports at the end of a level.
+type AgentLogs map[commons.ID]AgentLog
+
+// Each stage's log is merged into the level's LevelStages. A stage that runs
+// more than once in a level overwrites its earlier entry, except for fights,
+// whose rounds accumulate.
+
+func (s LevelStats) Merge(l *LevelStages) {
+	l.LevelStats = s
+}
+
+func (s SkippedThroughHpPool) Merge(l *LevelStages) {
+	l.LevelStats.SkippedThroughHpPool = bool(s)
+}
+
+func (s ElectionStage) Merge(l *LevelStages) {
+	l.ElectionStage = s
+}
+
+func (s VONCStage) Merge(l *LevelStages) {
+	l.VONCStage = s
+}
+
+func (s FightStage) Merge(l *LevelStages) {
+	l.FightStage.Occurred = l.FightStage.Occurred || s.Occurred
+	l.FightStage.Rounds = append(l.FightStage.Rounds, s.Rounds...)
+	if s.Stalemate != "" {
+		l.FightStage.Stalemate = s.Stalemate
+		l.FightStage.StalematePenalty += s.StalematePenalty
 	}
-	fmt.Println(gameState.CurrentLeader)
-	fmt.Println(fileLog.Levels)
-	fileLog.Levels[gameState.CurrentLevel-1].AgentLogs[agentLog.ID] = agentLog
 }
 
-func LogToFile(lvl Level, fields LogField, msg string, level LevelStages) {
+func (s LootStage) Merge(l *LevelStages) {
+	l.LootStage = s
+}
+
+func (s HPPoolStage) Merge(l *LevelStages) {
+	l.HPPoolStage = s
+}
+
+func (s AgentLogs) Merge(l *LevelStages) {
+	l.AgentLogs = s
+}
+
+func NewGameLog(config Config) *GameLog {
+	return &GameLog{Config: config}
+}
+
+func (g *GameLog) LogToFile(lvl Level, fields LogField, msg string, level LevelStages) {
 	switch lvl {
 	case Error:
-		fileLog.Errors = append(fileLog.Errors, CombineMessageToFields(fields, msg))
+		g.Errors = append(g.Errors, CombineMessageToFields(fields, msg))
 		Log(lvl, fields, msg)
 		return
 	case Warn:
-		fileLog.Warnings = append(fileLog.Warnings, CombineMessageToFields(fields, msg))
+		g.Warnings = append(g.Warnings, CombineMessageToFields(fields, msg))
 		Log(lvl, fields, msg)
 		return
 	case Info:
-		fileLog.Levels = append(fileLog.Levels, level)
+		g.Levels = append(g.Levels, level)
 		return
 	}
 }
@@ -193,25 +394,20 @@ func CombineMessageToFields(fields LogField, msg string) LogField {
 	return fields
 }
 
-func OutputLog(outcome Outcome) {
-	fileLog.Outcome = outcome
-	jsonBuf, err := json.MarshalIndent(fileLog, "", "\t")
+// WriteFile writes the game log as indented JSON to outputPath, creating
+// any missing parent directories.
+func (g *GameLog) WriteFile(outputPath string) error {
+	jsonBuf, err := json.MarshalIndent(g, "", "\t")
 	if err != nil {
-		log.Fatalf("Failed to Marshal gameStates: %v", err)
-		return
+		return fmt.Errorf("failed to marshal game log: %w", err)
 	}
 
-	wd, err := os.Getwd()
-	if err != nil {
-		log.Fatalf("%v", err)
-		return
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0777); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
 	}
 
-	outputDir := path.Join(wd, "output/output.json")
-
-	err = os.WriteFile(outputDir, jsonBuf, 0777)
-	if err != nil {
-		log.Fatalf("Failed to write file: %v", err)
-		return
+	if err := os.WriteFile(outputPath, jsonBuf, 0777); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
 	}
+	return nil
 }
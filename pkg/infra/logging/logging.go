@@ -2,7 +2,6 @@ package logging
 
 import (
 	"fmt"
-	"infra/game/state"
 	"io"
 	"os"
 
@@ -10,7 +9,6 @@ import (
 )
 
 var log = logrus.New()
-var gameState *state.State
 
 type LogField = logrus.Fields
 
@@ -24,7 +22,7 @@ const (
 	Error
 )
 
-func InitLogger(verbose, useJSONFormatter, debug bool, id string, state *state.State) {
+func InitLogger(verbose, useJSONFormatter, debug bool) {
 	if useJSONFormatter {
 		log.SetFormatter(&logrus.JSONFormatter{})
 	} else {
@@ -36,13 +34,10 @@ func InitLogger(verbose, useJSONFormatter, debug bool, id string, state *state.S
 	} else {
 		log.SetOutput(io.Discard)
 	}
-	runID = id
-	gameState = state
-
 	if debug {
 		log.SetLevel(logrus.TraceLevel)
 	} else {
-		LogToFile(Warn, nil, "'Trace' and 'Debug' messages hidden. Run with '-d' or 'make runDebug' to see these logs.", LevelStages{})
+		Log(Warn, nil, "'Trace' and 'Debug' messages hidden. Run with '-d' or 'make runDebug' to see these logs.")
 		log.SetLevel(logrus.InfoLevel)
 	}
 }
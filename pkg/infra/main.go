@@ -1,30 +1,29 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
-	cmdline "infra/cmdLine"
-	"infra/game/agent"
-	"infra/game/commons"
-	"infra/game/decision"
-	gamemath "infra/game/math"
-	"infra/game/message"
-	"infra/game/stage/discussion"
-	"infra/game/stage/fight"
-	"infra/game/stage/hppool"
-	"infra/game/stage/loot"
-	"infra/game/stage/trade"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"infra/batch"
+	"infra/config"
+	"infra/engine"
 	"infra/game/stages"
 	"infra/logging"
-	statscalc "infra/statsCalc"
+	"infra/replay"
+	"infra/sweep"
 	"infra/teams/team3"
-	"math"
-	"time"
 
-	"github.com/benbjohnson/immutable"
+	"github.com/joho/godotenv"
 )
 
-var InitAgentMap = map[commons.ID]func() agent.Strategy{
+var InitAgentMap = engine.Registry{
 	// "RANDOM": example.NewRandomAgent,
 	// "TEAM1":  team1.NewSocialAgent,
 	// "TEAM2":  team2.NewAgent2,
@@ -37,242 +36,313 @@ var InitAgentMap = map[commons.ID]func() agent.Strategy{
 }
 
 func main() {
-	// define flags
-	time := time.Now()
-	useJSONFormatter := flag.Bool("j", false, "Whether to output logs in JSON")
-	debug := flag.Bool("d", false, "Whether to run in debug mode. If false, only logs with level info or above will be shown")
-	id := flag.String("i", time.String(), "Provide an ID for a given run")
-	fixedSanction := flag.Int("fSanc", 1, "Provide fixed sanction length")
-	graduatedSanction := flag.Bool("gSanc", false, "Toggle graduated sanctioning")
-	maxGradSanction := flag.Int("gSancMax", 5, "Maximum graduated sanction length")
-	dynamicSanction := flag.Bool("dSanc", false, "Toggle dynamic sanctioning")
-	verbose := flag.Bool("verbose", true, "Toggle logger")
-	persistentSanction := flag.Bool("pSanc", false, "Toggles whether sanctions persist across leadership")
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "batch":
+			runBatch(os.Args[2:])
+			return
+		case "sweep":
+			runSweep(os.Args[2:])
+			return
+		case "replay":
+			runReplay(os.Args[2:])
+			return
+		}
+	}
+	runGame()
+}
 
+// runGame plays a single game, configured by the command line flags.
+func runGame() {
+	gameConfig := gameFlags(flag.CommandLine, true)
+	checkpointLevels := flag.String("checkpoint", "", "Comma separated levels to checkpoint the game at the start of, eg. 10,40")
+	checkpointDir := flag.String("checkpointDir", "checkpoints", "Directory checkpoints are written to")
+	resume := flag.String("resume", "", "Checkpoint file to resume the game from")
+	record := flag.String("record", "", "File to record every agent decision to, for the replay command")
 	flag.Parse()
 
-	if *dynamicSanction && *graduatedSanction {
-		fmt.Println("Cannot have both dynamic and graduated sanctions.")
-		return
+	cfg, err := gameConfig()
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	cfg.OutputPath = "output/output.json"
+	cfg.CSVPath = "logCSV/gameLog.csv"
+	if *checkpointLevels != "" {
+		levels, err := parseLevels(*checkpointLevels)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		cfg.CheckpointDir = *checkpointDir
+		cfg.CheckpointLevels = levels
+	}
+	if *record != "" && *resume != "" {
+		fmt.Println("a resumed game cannot be recorded, as replays start from the first level")
+		os.Exit(1)
+	}
+	var recorder *replay.Recorder
+	if *record != "" {
+		recorder = replay.NewRecorder(replay.Record, nil)
+		recorder.Configure(&cfg)
 	}
 
-	cmdline.CmdLineInits.FixedSanctionDuration = *fixedSanction
-	cmdline.CmdLineInits.GraduatedSanctions = *graduatedSanction
-	cmdline.CmdLineInits.MaxGraduatedSanctionDuration = *maxGradSanction
-	cmdline.CmdLineInits.DynamicSanctions = *dynamicSanction
-	cmdline.CmdLineInits.PersistentSanctions = *persistentSanction
+	var game *engine.Game
+	if *resume != "" {
+		var cp *engine.Checkpoint
+		if cp, err = engine.ReadCheckpoint(*resume); err == nil {
+			game, err = engine.Resume(cp, cfg, registryFor(cfg.Game))
+		}
+	} else {
+		game, err = engine.New(cfg, registryFor(cfg.Game))
+	}
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
 
-	logging.InitLogger(*verbose, *useJSONFormatter, *debug, *id, globalState)
-	initGame()
-	startGameLoop()
-}
+	result, err := game.Run(context.Background())
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	if recorder != nil {
+		if err := recorder.Err(); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		if err := recorder.Log(cfg).WriteFile(*record); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	}
 
-func startGameLoop() {
-	var decisionMap map[commons.ID]decision.FightAction
-	var channelsMap map[commons.ID]chan message.TaggedMessage
-	var termLeft uint
-	channelsMap = addCommsChannels()
-	*viewPtr = globalState.ToView()
+	if result.Outcome == logging.Win {
+		fmt.Println("Iteration Complete - Game won")
+	} else {
+		fmt.Printf("Iteration Complete - Game Lost On Level %d \n", result.Level)
+	}
+}
 
-	w, csvFile := initCsvLogging()
+// runReplay replays a game recorded with -record, eg.
+// `go run ./pkg/infra replay -log game.json`. With -diverge the agents' own
+// strategies play instead, and the first decision that differs from the log
+// is reported.
+func runReplay(args []string) {
+	fs := flag.NewFlagSet("replay", flag.ExitOnError)
+	path := fs.String("log", "", "Recorded game to replay")
+	diverge := fs.Bool("diverge", false, "Play the agents' strategies and report the first decision that differs from the log")
+	verbose := fs.Bool("verbose", false, "Toggle logger")
+	_ = fs.Parse(args)
+
+	logging.InitLogger(*verbose, false, false)
+	l, err := replay.Read(*path)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	cfg := l.Config()
+	mode, registry := replay.Replay, l.Registry()
+	if *diverge {
+		mode, registry = replay.Diverge, registryFor(cfg.Game)
+	}
+	recorder := replay.NewRecorder(mode, l)
+	recorder.Configure(&cfg)
 
-	initialAgents := len(agentMap)
+	game, err := engine.New(cfg, registry)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	result, err := recorder.Run(context.Background(), game)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
 
-	statscalc.Calc.SetStatsCalcData(agentMap)
+	if d := recorder.Divergence(); d != nil {
+		fmt.Printf("Diverged: %s\n", d)
+		os.Exit(1)
+	}
+	if result.Outcome == logging.Win {
+		fmt.Println("Replay Complete - Game won")
+	} else {
+		fmt.Printf("Replay Complete - Game Lost On Level %d \n", result.Level)
+	}
+}
 
-	for globalState.CurrentLevel = 1; globalState.CurrentLevel < (gameConfig.NumLevels + 1); globalState.CurrentLevel++ {
-		levelLog := logging.LevelStages{}
-		// Election Stage
-		_, alive := agentMap[globalState.CurrentLeader]
-		var votes map[decision.Intent]uint
-		leaderBeforeElection := globalState.CurrentLeader
-		if termLeft == 0 || !alive {
-			termLeft = runElection()
-			// fmt.Println(globalState.LeaderManifesto)
-			levelLog.ElectionStage = logging.ElectionStage{
-				Occurred: true,
-				Winner:   globalState.CurrentLeader,
-				Team:     agentMap[globalState.CurrentLeader].BaseAgent.Name(),
-				Manifesto: logging.ManifestoLog{
-					FightImposition:     globalState.LeaderManifesto.FightDecisionPower(),
-					LootImposition:      globalState.LeaderManifesto.LootDecisionPower(),
-					TermLength:          globalState.LeaderManifesto.TermLength(),
-					ThresholdPercentage: globalState.LeaderManifesto.OverthrowThreshold(),
-				},
-			}
-			logging.Log(logging.Info, logging.LogField{
-				"Fight Imp": globalState.LeaderManifesto.FightDecisionPower(),
-				"Loot Imp":  globalState.LeaderManifesto.LootDecisionPower(),
-				"Term":      globalState.LeaderManifesto.TermLength(),
-				"Threshold": globalState.LeaderManifesto.OverthrowThreshold(),
-				"Winner":    globalState.CurrentLeader,
-				"Team":      agentMap[globalState.CurrentLeader].BaseAgent.Name(),
-			}, "Re-Election Vote")
-		} else {
-			levelLog.VONCStage = logging.VONCStage{Occurred: true, Threshold: globalState.LeaderManifesto.OverthrowThreshold()}
-			termLeft, votes = runConfidenceVote(termLeft)
-			levelLog.VONCStage.For = votes[decision.Positive]
-			levelLog.VONCStage.Against = votes[decision.Negative]
-			levelLog.VONCStage.Abstain = votes[decision.Abstain]
+// parseLevels parses a comma separated list of levels.
+func parseLevels(list string) ([]uint, error) {
+	var levels []uint
+	for _, field := range strings.Split(list, ",") {
+		level, err := strconv.ParseUint(strings.TrimSpace(field), 10, 0)
+		if err != nil {
+			return nil, fmt.Errorf("invalid checkpoint level %q: %w", field, err)
 		}
+		levels = append(levels, uint(level))
+	}
+	return levels, nil
+}
 
-		levelLog.LevelStats.SkippedThroughHpPool = checkHpPool()
-
-		// allow agents to change the weapon and the shield in use
-		globalState = loot.UpdateItems(*globalState, agentMap)
-		*viewPtr = globalState.ToView()
-
-		// Battle Rounds
-		// TODO: Ambiguity in specification - do agents have a upper limit of rounds to try and slay the monster?
-		fightResultSlice := make([]decision.ImmutableFightResult, 0)
-		roundNum := uint(0)
-		for globalState.MonsterHealth != 0 {
-			levelLog.FightStage.Occurred = true
-			// find out the maximum attack from alive agents
-			maxAttack := uint(0)
-			for _, agentState := range globalState.AgentState {
-				if agentState.Hp > 0 {
-					maxAttack += agentState.TotalAttack()
-				}
-			}
-			// calculate average stats
-			avgHP, avgAT, avgSH, avgST := uint(0), uint(0), uint(0), uint(0)
-			for _, a := range agentMap {
-				state := a.AgentState()
-				avgHP += a.AgentState().Hp
-				avgAT += state.TotalAttack()
-				avgSH += state.TotalDefense()
-				avgST += a.AgentState().Stamina
-				// avgPers += a.personality
-			}
-			agents := uint(len(agentMap))
-			avgHP, avgAT, avgSH, avgST = avgHP/agents, avgAT/agents, avgSH/agents, avgST/agents
+// runBatch plays many games concurrently and summarises them, eg.
+// `go run ./pkg/infra batch -n 200 -workers 8 -seed 1`.
+func runBatch(args []string) {
+	fs := flag.NewFlagSet("batch", flag.ExitOnError)
+	gameConfig := gameFlags(fs, false)
+	games := fs.Int("n", 100, "Number of games to play")
+	workers := fs.Int("workers", runtime.NumCPU(), "Maximum number of games played at once")
+	dir := fs.String("out", filepath.Join("runs", time.Now().Format("20060102-150405")), "Run directory for per-game logs and the summary")
+	_ = fs.Parse(args)
+
+	cfg, err := gameConfig()
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	summary, err := batch.Run(context.Background(), batch.Options{
+		Games:    *games,
+		Workers:  *workers,
+		Seed:     cfg.Seed,
+		Dir:      *dir,
+		Config:   cfg,
+		Registry: registryFor(cfg.Game),
+	})
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
 
-			levelLog.LevelStats = logging.LevelStats{
-				NumberOfAgents:       uint(len(agentMap)),
-				CurrentLevel:         globalState.CurrentLevel,
-				LeaderBeforeElection: leaderBeforeElection,
-				LeaderAfterElection:  globalState.CurrentLeader,
-				HPPool:               globalState.HpPool,
-				MonsterHealth:        globalState.MonsterHealth,
-				MonsterAttack:        globalState.MonsterAttack,
-				AverageAgentHealth:   avgHP,
-				AverageAgentAttack:   avgAT,
-				AverageAgentShield:   avgSH,
-				AverageAgentStamina:  avgST,
-			}
-			// end calc average stats
+	fmt.Printf("Games: %d, Wins: %d\n", summary.Games, summary.Wins)
+	fmt.Printf("Win rate: %.3f (95%% CI %.3f-%.3f)\n", summary.WinRate, summary.WinRateCI.Low, summary.WinRateCI.High)
+	fmt.Printf("Mean level: %.2f (95%% CI %.2f-%.2f)\n", summary.MeanLevel, summary.MeanLevelCI.Low, summary.MeanLevelCI.High)
+	fmt.Printf("Survivors: min %d, median %.1f, max %d\n", summary.Survivors.Min, summary.Survivors.Median, summary.Survivors.Max)
+	fmt.Println(filepath.Join(*dir, "summary.json"))
+}
 
-			decisionMapView := immutable.NewMapBuilder[commons.ID, decision.FightAction](nil)
-			for u, action := range decisionMap {
-				decisionMapView.Set(u, action)
-			}
-			fightTally := stages.AgentFightDecisions(*globalState, agentMap, *decisionMapView.Map(), channelsMap)
-			fightActions := discussion.ResolveFightDiscussion(*globalState, agentMap, agentMap[globalState.CurrentLeader], globalState.LeaderManifesto, fightTally)
-			// Printing voted rules
-			// prop := fightTally.GetMax()
-			// rules := prop.Rules()
-			// iterator := rules.Iterator()
-			// for !iterator.Done() {
-			// 	rule, _ := iterator.Next()
-			// 	condition := rule.Condition()
-			// 	associatedAction := ""
-			// 	switch rule.Action() {
-			// 	case 0:
-			// 		associatedAction = "Defend"
-			// 	case 1:
-			// 		associatedAction = "Cower"
-			// 	default:
-			// 		associatedAction = "Attack"
-			// 	}
-			// 	fmt.Println(associatedAction, condition)
-			// }
-			globalState = fight.HandleFightRound(*globalState, gameConfig.Stamina, gameConfig.StartingHealthPoints, &fightActions)
-			*viewPtr = globalState.ToView()
+// runSweep plays every combination of a sweep spec as a batch and tabulates
+// the results, eg. `go run ./pkg/infra sweep -spec sweep.json -workers 8`.
+func runSweep(args []string) {
+	fs := flag.NewFlagSet("sweep", flag.ExitOnError)
+	gameConfig := gameFlags(fs, false)
+	specPath := fs.String("spec", "sweep.json", "Sweep spec file")
+	workers := fs.Int("workers", runtime.NumCPU(), "Maximum number of games played at once")
+	dir := fs.String("out", filepath.Join("runs", time.Now().Format("20060102-150405")), "Run directory for per-combination results and the results table")
+	_ = fs.Parse(args)
+
+	spec, err := sweep.Load(*specPath)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
 
-			logging.Log(logging.Info, logging.LogField{
-				"currLevel":     globalState.CurrentLevel,
-				"monsterHealth": globalState.MonsterHealth,
-				"monsterDamage": globalState.MonsterAttack,
-				"numCoward":     len(fightActions.CoweringAgents),
-				"attackSum":     fightActions.AttackSum,
-				"shieldSum":     fightActions.ShieldSum,
-				"numAgents":     len(agentMap),
-				"maxAttack":     maxAttack,
-				"AvHP":          avgHP,
-				"AvST":          avgST,
-				"AvAttack":      avgAT,
-				"AvDefence":     avgSH,
-			}, "Battle Summary")
-			// NOTE: update the following function when you change AgentState
-			damageCalculation(fightActions)
-			levelLog.FightStage.Rounds = append(levelLog.FightStage.Rounds, logging.FightLog{
-				AttackingAgents: fightActions.AttackingAgents,
-				CoweringAgents:  fightActions.CoweringAgents,
-				ShieldingAgents: fightActions.ShieldingAgents,
-				AttackSum:       fightActions.AttackSum,
-				ShieldSum:       fightActions.ShieldSum,
-				AgentsRemaining: uint(len(agentMap)),
-			})
+	base, err := gameConfig()
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	build := func(point sweep.Point) (engine.Config, engine.Registry, error) {
+		cfg := base
+		cfg.Game.AgentQuantities = make(map[string]uint)
+		for name, quantity := range base.Game.AgentQuantities {
+			cfg.Game.AgentQuantities[name] = quantity
+		}
+		err := sweep.Apply(point, sweep.Targets{
+			"Game":      &cfg.Game,
+			"Agents":    &cfg.Game.AgentQuantities,
+			"Sanctions": &cfg.Sanctions,
+			"Team3":     &cfg.Game.Team3,
+		})
+		return cfg, registryFor(cfg.Game), err
+	}
 
-			channelsMap = addCommsChannels()
+	rows, err := sweep.Run(context.Background(), sweep.Options{
+		Spec:    spec,
+		Workers: *workers,
+		Dir:     *dir,
+		Build:   build,
+	})
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
 
-			// log last round if we lose
-			if float64(len(agentMap)) < math.Ceil(float64(gameConfig.ThresholdPercentage)*float64(gameConfig.InitialNumAgents)) {
+	fmt.Printf("Played %d combinations of %d games\n", len(rows), spec.Replicates)
+	fmt.Println(filepath.Join(*dir, "results.csv"))
+}
 
-				logLevel(levelLog, agentMap, w)
+// registryFor is InitAgentMap, with the teams' settings taken from game.
+func registryFor(game config.GameConfig) engine.Registry {
+	registry := make(engine.Registry, len(InitAgentMap))
+	team3Strategies := team3.Strategies(game.Team3)
+	for name, strategy := range InitAgentMap {
+		if team3Strategy, ok := team3Strategies[name]; ok {
+			strategy = team3Strategy
+		}
+		registry[name] = strategy
+	}
+	return registry
+}
 
-				logging.Log(logging.Info, nil, fmt.Sprintf("Lost on level %d  with %d remaining", globalState.CurrentLevel, len(agentMap)))
-				logging.LogToFile(logging.Info, nil, "", levelLog)
-				logging.OutputLog(logging.Loss)
+// gameFlags registers the flags shared by single games and batches. The
+// returned function builds the game config once fs has been parsed: the
+// defaults, overridden by .env, the -config file, the environment and any
+// flags given, in that order.
+func gameFlags(fs *flag.FlagSet, defaultVerbose bool) func() (engine.Config, error) {
+	time := time.Now()
+	configPath := fs.String("config", "", "YAML or JSON config file, eg. config.yaml. Environment variables and flags override it")
+	useJSONFormatter := fs.Bool("j", false, "Whether to output logs in JSON")
+	debug := fs.Bool("d", false, "Whether to run in debug mode. If false, only logs with level info or above will be shown")
+	id := fs.String("i", "", "Provide an ID for a given run. Defaults to the seed")
+	fixedSanction := fs.Int("fSanc", 1, "Provide fixed sanction length")
+	graduatedSanction := fs.Bool("gSanc", false, "Toggle graduated sanctioning")
+	maxGradSanction := fs.Int("gSancMax", 5, "Maximum graduated sanction length")
+	dynamicSanction := fs.Bool("dSanc", false, "Toggle dynamic sanctioning")
+	verbose := fs.Bool("verbose", defaultVerbose, "Toggle logger")
+	persistentSanction := fs.Bool("pSanc", false, "Toggles whether sanctions persist across leadership")
+	strict := fs.Bool("strict", false, "Check the game state's invariants after every stage, failing on the first broken")
+	seed := fs.Int64("seed", time.UnixNano(), "Seed for all game randomness. Runs with the same seed and configuration are identical")
+
+	return func() (engine.Config, error) {
+		logging.InitLogger(*verbose, *useJSONFormatter, *debug)
+
+		dotenv, err := godotenv.Read()
+		if err != nil {
+			logging.Log(logging.Error, nil, "No .env file located, using defaults")
+		}
+		mode := config.EnvToString("MODE", dotenv["MODE"])
+		if mode == "" {
+			mode = string(logging.Default)
+		}
 
-				csvFile.Close()
-				fmt.Printf("Iteration Complete - Game Lost On Level %d \n", globalState.CurrentLevel)
-				return
+		file, err := stages.LoadConfig(mode, *configPath, dotenv)
+		if err != nil {
+			return engine.Config{}, err
+		}
+		fs.Visit(func(f *flag.Flag) {
+			switch f.Name {
+			case "fSanc":
+				file.Sanctions.FixedSanctionDuration = *fixedSanction
+			case "gSanc":
+				file.Sanctions.GraduatedSanctions = *graduatedSanction
+			case "gSancMax":
+				file.Sanctions.MaxGraduatedSanctionDuration = *maxGradSanction
+			case "dSanc":
+				file.Sanctions.DynamicSanctions = *dynamicSanction
+			case "pSanc":
+				file.Sanctions.PersistentSanctions = *persistentSanction
 			}
-			fightResultSlice = append(fightResultSlice, *decision.NewImmutableFightResult(fightActions, roundNum))
-			roundNum++
+		})
+		if err := file.Validate(); err != nil {
+			return engine.Config{}, err
 		}
 
-		// TODO: Loot Discussion Stage
-
-		lootPool := generateLootPool(uint(initialAgents))
-		prunedAgentMap := stages.AgentPruneMapping(agentMap, globalState)
-		sortedAgentArray := stages.AgentMapToSortedArray(prunedAgentMap, globalState)
-		// lootTally := stages.AgentLootDecisions(*globalState, *lootPool, prunedAgentMap, channelsMap)
-		// lootActions := discussion.ResolveLootDiscussion(*globalState, prunedAgentMap, lootPool, agentMap[globalState.CurrentLeader], globalState.LeaderManifesto, lootTally)
-		// globalState = loot.HandleLootAllocation(*globalState, lootActions, lootPool, prunedAgentMap)
-		globalState = loot.HandleLootAllocationExhaustive(*globalState, lootPool, sortedAgentArray)
-
-		trade.HandleTrade(*globalState, agentMap, 5, 3)
-
-		channelsMap = addCommsChannels()
-
-		stages.HandleTrustStage(agentMap, channelsMap)
-
-		channelsMap = addCommsChannels()
-
-		levelLog.HPPoolStage = logging.HPPoolStage{Occurred: true, OldHPPool: globalState.HpPool}
-		hppool.UpdateHpPool(agentMap, globalState)
-		levelLog.HPPoolStage.NewHPPool = globalState.HpPool
-		levelLog.HPPoolStage.DonatedThisRound = levelLog.HPPoolStage.NewHPPool - levelLog.HPPoolStage.OldHPPool
-
-		// TODO: End of level Updates
-		termLeft--
-		globalState.MonsterHealth, globalState.MonsterAttack = gamemath.GetNextLevelMonsterValues(*gameConfig, globalState.CurrentLevel+1)
-		*viewPtr = globalState.ToView()
-		logging.Log(logging.Info, nil, fmt.Sprintf("------------------------------ Level %d Ended ----------------------------", globalState.CurrentLevel))
-
-		immutableFightRounds := commons.NewImmutableList(fightResultSlice)
-		votesResult := commons.MapToImmutable(votes)
-		levelLog.AgentLogs = stages.UpdateInternalStates(agentMap, globalState, immutableFightRounds, &votesResult)
-
-		logging.LogToFile(logging.Info, nil, "", levelLog)
-
-		logLevel(levelLog, agentMap, w)
+		return engine.Config{
+			Game:      file.Game,
+			Sanctions: file.Sanctions,
+			Seed:      *seed,
+			RunID:     *id,
+			Strict:    *strict,
+			Mode:      mode,
+		}, nil
 	}
-	logging.Log(logging.Info, nil, fmt.Sprintf("Congratulations, The Peasants have escaped the pit with %d remaining.", len(agentMap)))
-	logging.OutputLog(logging.Win)
-	csvFile.Close()
-	fmt.Println("Iteration Complete - Game won")
 }
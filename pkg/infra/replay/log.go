@@ -0,0 +1,109 @@
+package replay
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	cmdline "infra/cmdLine"
+	"infra/config"
+	"infra/engine"
+	"infra/game/agent"
+	"infra/game/commons"
+	"infra/game/message"
+
+	"github.com/google/uuid"
+)
+
+// Log is a recorded game: its configuration and every call the engine made to
+// each agent's strategy, in the order the agent received them.
+type Log struct {
+	Seed      int64
+	RunID     string
+	Mode      string
+	Game      config.GameConfig
+	Sanctions cmdline.CmdLine
+	// Agents are in ID order.
+	Agents []Agent
+}
+
+// Agent is the calls made to a single agent's strategy.
+type Agent struct {
+	ID    commons.ID
+	Name  string
+	Calls []Call
+}
+
+// Call is a single call to a strategy: what it was given, what it returned and
+// the messages it sent while handling the call.
+type Call struct {
+	Level  uint
+	Stage  string
+	Method string
+	Input  json.RawMessage `json:",omitempty"`
+	Output json.RawMessage `json:",omitempty"`
+	Sent   []Sent          `json:",omitempty"`
+}
+
+// Sent is a message sent by a strategy.
+type Sent struct {
+	To      commons.ID
+	MID     uuid.UUID
+	Message message.Typed
+}
+
+// Config is the configuration the recorded game was played with.
+func (l *Log) Config() engine.Config {
+	game := l.Game
+	game.AgentQuantities = make(map[string]uint)
+	for _, a := range l.Agents {
+		game.AgentQuantities[a.Name]++
+	}
+	return engine.Config{
+		Game:      game,
+		Sanctions: l.Sanctions,
+		Seed:      l.Seed,
+		RunID:     l.RunID,
+		Mode:      l.Mode,
+	}
+}
+
+// Registry has an entry for every name of agent in the log. Its strategies
+// are nil, which is all a replay needs.
+func (l *Log) Registry() engine.Registry {
+	registry := make(engine.Registry)
+	for _, a := range l.Agents {
+		registry[a.Name] = func() agent.Strategy { return nil }
+	}
+	return registry
+}
+
+// Read reads a log written by WriteFile.
+func Read(path string) (*Log, error) {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("replay: failed reading log: %w", err)
+	}
+	var l Log
+	if err := json.Unmarshal(buf, &l); err != nil {
+		return nil, fmt.Errorf("replay: failed parsing log %s: %w", path, err)
+	}
+	return &l, nil
+}
+
+// WriteFile writes the log as JSON to path, creating any missing parent
+// directories.
+func (l *Log) WriteFile(path string) error {
+	buf, err := json.Marshal(l)
+	if err != nil {
+		return fmt.Errorf("replay: failed to marshal log: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0777); err != nil {
+		return fmt.Errorf("replay: failed to create log directory: %w", err)
+	}
+	if err := os.WriteFile(path, buf, 0666); err != nil {
+		return fmt.Errorf("replay: failed to write log: %w", err)
+	}
+	return nil
+}
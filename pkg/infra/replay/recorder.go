@@ -0,0 +1,286 @@
+package replay
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+
+	"infra/engine"
+	"infra/game/agent"
+	"infra/game/commons"
+	"infra/game/message"
+)
+
+// Mode is what a Recorder does with the calls made to strategies.
+type Mode int
+
+const (
+	// Record calls the strategies and records every call.
+	Record Mode = iota
+	// Replay answers every call from a log, without calling the strategies.
+	Replay
+	// Diverge calls the strategies and compares every call with a log.
+	Diverge
+)
+
+// Recorder sits between the engine and the agents' strategies (see
+// Recorder.Configure).
+type Recorder struct {
+	mode Mode
+
+	mu    sync.Mutex
+	level uint
+	stage string
+	// step counts the stages played so far
+	step   int
+	agents map[commons.ID]*recording
+	err    error
+	// divergences are the calls found to differ, the earliest of which is
+	// reported
+	divergences []Divergence
+}
+
+// recording is one agent's calls. Only the agent's own calls touch it, and an
+// agent is never called from two goroutines at once.
+type recording struct {
+	name  string
+	calls []Call
+	next  int
+	// step is the stage the agent was last called in, and stepStart the
+	// index of its first call in that stage
+	step      int
+	stepStart int
+}
+
+// Divergence is the first call whose outcome differs from the log. Want is
+// nil if the log has no such call.
+type Divergence struct {
+	Agent commons.ID
+	Name  string
+	Index int
+	Want  *Call
+	Got   Call
+	step  int
+	// inStep is how many calls the agent had in the stage before this one
+	inStep int
+}
+
+func (d Divergence) String() string {
+	want := "nothing"
+	if d.Want != nil {
+		want = describe(*d.Want)
+	}
+	return fmt.Sprintf("level %d, stage %s: call %d to agent %s (%s) was %s, recorded %s",
+		d.Got.Level, d.Got.Stage, d.Index, d.Agent, d.Name, describe(d.Got), want)
+}
+
+func describe(c Call) string {
+	buf, _ := json.Marshal(struct {
+		Method string
+		Output json.RawMessage `json:",omitempty"`
+		Sent   []Sent          `json:",omitempty"`
+	}{c.Method, c.Output, c.Sent})
+	return string(buf)
+}
+
+// NewRecorder returns a recorder. Replay and Diverge compare against log,
+// which Record ignores.
+func NewRecorder(mode Mode, log *Log) *Recorder {
+	r := &Recorder{mode: mode, agents: make(map[commons.ID]*recording)}
+	if mode != Record {
+		for _, a := range log.Agents {
+			r.agents[a.ID] = &recording{name: a.Name, calls: a.Calls}
+		}
+	}
+	return r
+}
+
+// Configure sets up cfg so that the game's strategies go through r.
+func (r *Recorder) Configure(cfg *engine.Config) {
+	cfg.WrapStrategy = r.wrap
+	cfg.OnStage = r.onStage
+}
+
+func (r *Recorder) wrap(baseAgent *agent.BaseAgent, inner agent.Strategy) agent.Strategy {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	rec, ok := r.agents[baseAgent.ID()]
+	if !ok {
+		rec = &recording{name: baseAgent.Name()}
+		r.agents[baseAgent.ID()] = rec
+		if r.mode != Record {
+			r.fail(fmt.Errorf("replay: agent %s (%s) is not in the log", baseAgent.ID(), baseAgent.Name()))
+		}
+	}
+	return &strategy{recorder: r, baseAgent: baseAgent, inner: inner, rec: rec}
+}
+
+func (r *Recorder) onStage(level uint, stage string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.level = level
+	r.stage = stage
+	r.step++
+}
+
+func (r *Recorder) position() (uint, string, int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.level, r.stage, r.step
+}
+
+// fail records the first error. r.mu must be held.
+func (r *Recorder) fail(err error) {
+	if r.err == nil {
+		r.err = err
+	}
+}
+
+func (r *Recorder) failf(format string, args ...any) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.fail(fmt.Errorf(format, args...))
+}
+
+func (r *Recorder) diverge(d Divergence) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.divergences = append(r.divergences, d)
+}
+
+// Err is the first error met while recording or replaying, eg. a call that
+// is not in the log.
+func (r *Recorder) Err() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.err
+}
+
+// Divergence is the earliest call that differed from the log: the one in the
+// earliest stage, then the one fewest calls into the stage, then the one of
+// the agent with the lowest ID. Calls are only compared in Diverge mode.
+func (r *Recorder) Divergence() *Divergence {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.divergences) == 0 {
+		return nil
+	}
+	sort.Slice(r.divergences, func(i, j int) bool {
+		a, b := r.divergences[i], r.divergences[j]
+		if a.step != b.step {
+			return a.step < b.step
+		}
+		if a.inStep != b.inStep {
+			return a.inStep < b.inStep
+		}
+		return a.Agent < b.Agent
+	})
+	d := r.divergences[0]
+	return &d
+}
+
+// Run plays game, which must have been set up with a config passed to
+// Configure, level by level. A replay stops at the first error, and a
+// divergence run at the end of the first level that differed from the log.
+func (r *Recorder) Run(ctx context.Context, game *engine.Game) (engine.Result, error) {
+	for !game.Done() {
+		if err := ctx.Err(); err != nil {
+			return game.Result(), err
+		}
+		if err := game.Step(); err != nil {
+			return game.Result(), err
+		}
+		if err := r.Err(); err != nil {
+			return game.Result(), err
+		}
+		if r.mode == Diverge && r.Divergence() != nil {
+			break
+		}
+	}
+	return game.Result(), nil
+}
+
+// Log is what was recorded, for the game played with cfg.
+func (r *Recorder) Log(cfg engine.Config) *Log {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	l := &Log{
+		Seed:      cfg.Seed,
+		RunID:     cfg.RunID,
+		Mode:      cfg.Mode,
+		Game:      cfg.Game,
+		Sanctions: cfg.Sanctions,
+	}
+	l.Game.AgentQuantities = nil
+	for id, rec := range r.agents {
+		l.Agents = append(l.Agents, Agent{ID: id, Name: rec.name, Calls: rec.calls})
+	}
+	sort.Slice(l.Agents, func(i, j int) bool {
+		return l.Agents[i].ID < l.Agents[j].ID
+	})
+	return l
+}
+
+// next returns the agent's next call from the log, or nil if the log has no
+// more calls for the agent.
+func (s *strategy) next() (int, *Call) {
+	rec := s.rec
+	index := rec.next
+	rec.next++
+	if _, _, step := s.recorder.position(); step != rec.step {
+		rec.step, rec.stepStart = step, index
+	}
+	if index < len(rec.calls) {
+		return index, &rec.calls[index]
+	}
+	return index, nil
+}
+
+func sameCall(want *Call, got Call) bool {
+	if want == nil || want.Method != got.Method || !bytes.Equal(want.Output, got.Output) || len(want.Sent) != len(got.Sent) {
+		return false
+	}
+	for i := range want.Sent {
+		a, b := want.Sent[i], got.Sent[i]
+		if a.To != b.To || a.MID != b.MID || a.Message.Type != b.Message.Type || !bytes.Equal(a.Message.Body, b.Message.Body) {
+			return false
+		}
+	}
+	return true
+}
+
+// encodeSent encodes the messages the agent sent, as found in its outbox.
+func encodeSent(sent []agent.Sent) ([]Sent, error) {
+	var out []Sent
+	for _, s := range sent {
+		m, err := message.Encode(s.Message.Message())
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, Sent{To: s.To, MID: s.Message.MID(), Message: m})
+	}
+	return out, nil
+}
+
+// post sends recorded messages as the agent.
+func post(baseAgent *agent.BaseAgent, sent []Sent) error {
+	for _, s := range sent {
+		m, err := message.Decode(s.Message)
+		if err != nil {
+			return err
+		}
+		msg, ok := m.(message.Message)
+		if !ok {
+			return fmt.Errorf("replay: %s is not a message", s.Message.Type)
+		}
+		baseAgent.Post(agent.Sent{To: s.To, Message: *message.NewTaggedMessage(baseAgent.ID(), msg, s.MID)})
+	}
+	return nil
+}
@@ -0,0 +1,155 @@
+package replay_test
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	cmdline "infra/cmdLine"
+	"infra/config"
+	"infra/engine"
+	"infra/replay"
+	"infra/teams/team3"
+)
+
+var registry = engine.Registry{
+	"COLLECTIVE": team3.NewAgentThreeNeutral,
+	"SELFLESS":   team3.NewAgentThreePassive,
+	"SELFISH":    team3.NewAgentThreeAggressive,
+}
+
+func testConfig() engine.Config {
+	return engine.Config{
+		Game: config.GameConfig{
+			NumLevels:              60,
+			StartingHealthPoints:   1000,
+			StartingAttackStrength: 20,
+			StartingShieldStrength: 20,
+			ThresholdPercentage:    0.01,
+			Stamina:                2000,
+			VotingPreferences:      2,
+			MaxScore:               10,
+			CampaignRounds:         3,
+			Defection:              true,
+			PotionScarcity:         0.2,
+			EquipmentScarcity:      0.15,
+			AgentQuantities:        map[string]uint{"COLLECTIVE": 5, "SELFLESS": 5, "SELFISH": 5},
+		},
+		Sanctions: cmdline.CmdLine{FixedSanctionDuration: 1, MaxGraduatedSanctionDuration: 5},
+		Seed:      7,
+	}
+}
+
+// play runs game through recorder, returning its JSON game log.
+func play(t *testing.T, recorder *replay.Recorder, game *engine.Game) (engine.Result, []byte) {
+	t.Helper()
+
+	result, err := recorder.Run(context.Background(), game)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	buf, err := json.Marshal(result.Log)
+	if err != nil {
+		t.Fatalf("failed to marshal game log: %v", err)
+	}
+	return result, buf
+}
+
+// record plays a game, returning its game log and the recording of it read
+// back from disk.
+func record(t *testing.T) ([]byte, *replay.Log) {
+	t.Helper()
+
+	cfg := testConfig()
+	recorder := replay.NewRecorder(replay.Record, nil)
+	recorder.Configure(&cfg)
+	game, err := engine.New(cfg, registry)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	result, recorded := play(t, recorder, game)
+	if result.Level < 5 {
+		t.Fatalf("game ended on level %d, too early to show the games agree", result.Level)
+	}
+	if err := recorder.Err(); err != nil {
+		t.Fatalf("recording error = %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "game.json")
+	if err := recorder.Log(cfg).WriteFile(path); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	l, err := replay.Read(path)
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	return recorded, l
+}
+
+func TestReplay(t *testing.T) {
+	t.Parallel()
+
+	recorded, l := record(t)
+
+	cfg := l.Config()
+	recorder := replay.NewRecorder(replay.Replay, l)
+	recorder.Configure(&cfg)
+	game, err := engine.New(cfg, l.Registry())
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if _, replayed := play(t, recorder, game); !reflect.DeepEqual(recorded, replayed) {
+		t.Errorf("replayed game differs from the recorded one:\n%s\n%s", recorded, replayed)
+	}
+}
+
+func TestDiverge(t *testing.T) {
+	t.Parallel()
+
+	_, l := record(t)
+
+	tests := []struct {
+		name     string
+		registry engine.Registry
+		wantDiff bool
+	}{
+		{
+			name:     "same strategies",
+			registry: registry,
+		},
+		{
+			name: "changed strategy",
+			registry: engine.Registry{
+				"COLLECTIVE": team3.NewAgentThreeNeutral,
+				"SELFLESS":   team3.NewAgentThreePassive,
+				"SELFISH":    team3.NewAgentThreePassive,
+			},
+			wantDiff: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := l.Config()
+			recorder := replay.NewRecorder(replay.Diverge, l)
+			recorder.Configure(&cfg)
+			game, err := engine.New(cfg, tt.registry)
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			play(t, recorder, game)
+
+			d := recorder.Divergence()
+			if (d != nil) != tt.wantDiff {
+				t.Fatalf("Divergence() = %v, want a divergence %v", d, tt.wantDiff)
+			}
+			if d != nil && d.Name != "SELFISH" {
+				t.Errorf("first divergence is of a %s agent, want SELFISH: %s", d.Name, d)
+			}
+		})
+	}
+}
@@ -0,0 +1,640 @@
+package replay
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"infra/game/agent"
+	"infra/game/commons"
+	"infra/game/decision"
+	"infra/game/message"
+	"infra/game/message/proposal"
+	"infra/game/state"
+	"infra/logging"
+
+	"github.com/benbjohnson/immutable"
+	"github.com/google/uuid"
+	"golang.org/x/exp/maps"
+)
+
+// strategy records, replays or checks the calls made to inner.
+type strategy struct {
+	recorder  *Recorder
+	baseAgent *agent.BaseAgent
+	inner     agent.Strategy
+	rec       *recording
+}
+
+// codec turns what a strategy returns into something encoding/json handles,
+// and back. A nil encoding records no output.
+type codec[O any] struct {
+	encode func(O) (any, error)
+	decode func(json.RawMessage) (O, error)
+}
+
+func plain[O any]() codec[O] {
+	return codec[O]{
+		encode: func(o O) (any, error) { return o, nil },
+		decode: func(raw json.RawMessage) (O, error) {
+			var o O
+			err := json.Unmarshal(raw, &o)
+			return o, err
+		},
+	}
+}
+
+var none = codec[struct{}]{
+	encode: func(struct{}) (any, error) { return nil, nil },
+	decode: func(json.RawMessage) (struct{}, error) { return struct{}{}, nil },
+}
+
+// messageCodec encodes messages, which strategies return as interfaces.
+func messageCodec[O any]() codec[O] {
+	return codec[O]{
+		encode: func(o O) (any, error) { return message.Encode(o) },
+		decode: func(raw json.RawMessage) (O, error) {
+			var o O
+			var t message.Typed
+			if err := json.Unmarshal(raw, &t); err != nil {
+				return o, err
+			}
+			m, err := message.Decode(t)
+			if err != nil || m == nil {
+				return o, err
+			}
+			o, ok := m.(O)
+			if !ok {
+				return o, fmt.Errorf("replay: recorded %s where %T was expected", t.Type, o)
+			}
+			return o, nil
+		},
+	}
+}
+
+// call makes a call to the strategy, or answers it from the log, depending on
+// the recorder's mode.
+func call[O any](s *strategy, method string, input any, c codec[O], live func() O) O {
+	r := s.recorder
+	level, stage, step := r.position()
+	got := Call{Level: level, Stage: stage, Method: method}
+
+	if r.mode == Replay {
+		var out O
+		index, want := s.next()
+		switch {
+		case want == nil:
+			r.failf("replay: agent %s has no call %d (%s on level %d) in the log", s.baseAgent.ID(), index, method, level)
+		case want.Level != level || want.Method != method:
+			r.failf("replay: call %d to agent %s is %s on level %d, recorded %s on level %d", index, s.baseAgent.ID(), method, level, want.Method, want.Level)
+		default:
+			if err := post(s.baseAgent, want.Sent); err != nil {
+				r.failf("replay: call %d to agent %s: %w", index, s.baseAgent.ID(), err)
+				return out
+			}
+			var err error
+			if out, err = c.decode(want.Output); err != nil {
+				r.failf("replay: call %d to agent %s: %w", index, s.baseAgent.ID(), err)
+			}
+		}
+		return out
+	}
+
+	before := len(s.baseAgent.Outbox())
+	out := live()
+	if err := encodeCall(&got, input, c, out, s.baseAgent.Outbox()[before:]); err != nil {
+		r.failf("replay: %s of agent %s on level %d: %w", method, s.baseAgent.ID(), level, err)
+	}
+
+	if r.mode == Record {
+		s.rec.calls = append(s.rec.calls, got)
+		return out
+	}
+	if index, want := s.next(); !sameCall(want, got) {
+		r.diverge(Divergence{Agent: s.baseAgent.ID(), Name: s.rec.name, Index: index, Want: want, Got: got, step: step, inStep: index - s.rec.stepStart})
+	}
+	return out
+}
+
+// encodeCall fills in what the strategy was given, returned and sent.
+func encodeCall[O any](got *Call, input any, c codec[O], out O, sent []agent.Sent) error {
+	var err error
+	if input != nil {
+		if got.Input, err = json.Marshal(input); err != nil {
+			return fmt.Errorf("cannot encode input: %w", err)
+		}
+	}
+	encoded, err := c.encode(out)
+	if err != nil {
+		return err
+	}
+	if encoded != nil {
+		if got.Output, err = json.Marshal(encoded); err != nil {
+			return fmt.Errorf("cannot encode output: %w", err)
+		}
+	}
+	got.Sent, err = encodeSent(sent)
+	return err
+}
+
+/*
+	Inputs, where they are more than the agent's own view of the game
+*/
+
+// taggedInput identifies a message an agent received. The message itself is
+// in the sender's calls, if the sender is an agent.
+type taggedInput struct {
+	Sender commons.ID
+	MID    uuid.UUID
+	Type   string
+}
+
+func tagged(sender commons.ID, m any, mID uuid.UUID) taggedInput {
+	typed, err := message.Encode(m)
+	if err != nil {
+		typed.Type = fmt.Sprintf("%T", m)
+	}
+	return taggedInput{Sender: sender, MID: mID, Type: typed.Type}
+}
+
+// proposalInput identifies a proposal, whose rules are in the proposer's
+// calls.
+type proposalInput struct {
+	ProposalID commons.ProposalID
+	ProposerID commons.ID
+}
+
+func proposed[A decision.ProposalAction](p message.Proposal[A]) proposalInput {
+	return proposalInput{ProposalID: p.ProposalID(), ProposerID: p.ProposerID()}
+}
+
+func sortedIDs[V any](m map[commons.ID]V) []commons.ID {
+	ids := maps.Keys(m)
+	sort.Strings(ids)
+	return ids
+}
+
+func sortedSetToSlice(set immutable.SortedMap[commons.ItemID, struct{}]) []commons.ItemID {
+	ids := make([]commons.ItemID, 0, set.Len())
+	itr := set.Iterator()
+	for !itr.Done() {
+		id, _, _ := itr.Next()
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+func sliceToSortedSet(ids []commons.ItemID) immutable.SortedMap[commons.ItemID, struct{}] {
+	return commons.ListToImmutableSortedSet(ids)
+}
+
+func immutableToMap[V any](m immutable.Map[commons.ID, V]) map[commons.ID]V {
+	out := make(map[commons.ID]V, m.Len())
+	itr := m.Iterator()
+	for !itr.Done() {
+		k, v, _ := itr.Next()
+		out[k] = v
+	}
+	return out
+}
+
+/*
+	Fight
+*/
+
+func (s *strategy) HandleFightInformation(m message.TaggedInformMessage[message.FightInform], baseAgent agent.BaseAgent, log *immutable.Map[commons.ID, decision.FightAction]) {
+	call(s, "HandleFightInformation", tagged(m.Sender(), m.Message(), m.MID()), none, func() struct{} {
+		s.inner.HandleFightInformation(m, baseAgent, log)
+		return struct{}{}
+	})
+}
+
+func (s *strategy) HandleFightRequest(m message.TaggedRequestMessage[message.FightRequest], log *immutable.Map[commons.ID, decision.FightAction]) message.FightInform {
+	return call(s, "HandleFightRequest", tagged(m.Sender(), m.Message(), m.MID()), messageCodec[message.FightInform](), func() message.FightInform {
+		return s.inner.HandleFightRequest(m, log)
+	})
+}
+
+func (s *strategy) FightResolution(baseAgent agent.BaseAgent, prop commons.ImmutableList[proposal.Rule[decision.FightAction]], proposedActions immutable.Map[commons.ID, decision.FightAction]) immutable.Map[commons.ID, decision.FightAction] {
+	input := struct {
+		Rules    commons.ImmutableList[proposal.Rule[decision.FightAction]]
+		Proposed map[commons.ID]decision.FightAction
+	}{prop, immutableToMap(proposedActions)}
+	c := codec[immutable.Map[commons.ID, decision.FightAction]]{
+		encode: func(m immutable.Map[commons.ID, decision.FightAction]) (any, error) { return immutableToMap(m), nil },
+		decode: func(raw json.RawMessage) (immutable.Map[commons.ID, decision.FightAction], error) {
+			var m map[commons.ID]decision.FightAction
+			err := json.Unmarshal(raw, &m)
+			return commons.MapToImmutable(m), err
+		},
+	}
+	return call(s, "FightResolution", input, c, func() immutable.Map[commons.ID, decision.FightAction] {
+		return s.inner.FightResolution(baseAgent, prop, proposedActions)
+	})
+}
+
+func (s *strategy) HandleFightProposal(p message.Proposal[decision.FightAction], baseAgent agent.BaseAgent) decision.Intent {
+	return call(s, "HandleFightProposal", proposed(p), plain[decision.Intent](), func() decision.Intent {
+		return s.inner.HandleFightProposal(p, baseAgent)
+	})
+}
+
+func (s *strategy) HandleFightProposalRequest(p message.Proposal[decision.FightAction], baseAgent agent.BaseAgent, log *immutable.Map[commons.ID, decision.FightAction]) bool {
+	return call(s, "HandleFightProposalRequest", proposed(p), plain[bool](), func() bool {
+		return s.inner.HandleFightProposalRequest(p, baseAgent, log)
+	})
+}
+
+func (s *strategy) FightActionNoProposal(baseAgent agent.BaseAgent) decision.FightAction {
+	return call(s, "FightActionNoProposal", nil, plain[decision.FightAction](), func() decision.FightAction {
+		return s.inner.FightActionNoProposal(baseAgent)
+	})
+}
+
+func (s *strategy) FightAction(baseAgent agent.BaseAgent, proposedAction decision.FightAction, acceptedProposal message.Proposal[decision.FightAction]) decision.FightAction {
+	input := struct {
+		Proposed decision.FightAction
+		Accepted commons.ProposalID
+	}{proposedAction, acceptedProposal.ProposalID()}
+	return call(s, "FightAction", input, plain[decision.FightAction](), func() decision.FightAction {
+		return s.inner.FightAction(baseAgent, proposedAction, acceptedProposal)
+	})
+}
+
+/*
+	Election
+*/
+
+func (s *strategy) CreateManifesto(baseAgent agent.BaseAgent) *decision.Manifesto {
+	return call(s, "CreateManifesto", nil, plain[*decision.Manifesto](), func() *decision.Manifesto {
+		return s.inner.CreateManifesto(baseAgent)
+	})
+}
+
+func (s *strategy) HandleConfidencePoll(baseAgent agent.BaseAgent) decision.Intent {
+	return call(s, "HandleConfidencePoll", nil, plain[decision.Intent](), func() decision.Intent {
+		return s.inner.HandleConfidencePoll(baseAgent)
+	})
+}
+
+func (s *strategy) HandleElectionBallot(baseAgent agent.BaseAgent, params *decision.ElectionParams) decision.Ballot {
+	input := struct {
+		Candidates  map[commons.ID]decision.Manifesto
+		Strategy    decision.VotingStrategy
+		Preferences uint
+	}{immutableToMap(*params.CandidateList()), params.Strategy(), params.NumberOfPreferences()}
+	return call(s, "HandleElectionBallot", input, plain[decision.Ballot](), func() decision.Ballot {
+		return s.inner.HandleElectionBallot(baseAgent, params)
+	})
+}
+
+func (s *strategy) HandleRunoffBallot(baseAgent agent.BaseAgent, params *decision.ElectionParams, firstBallot decision.Ballot) decision.Ballot {
+	input := struct {
+		Candidates  map[commons.ID]decision.Manifesto
+		FirstBallot decision.Ballot
+	}{immutableToMap(*params.CandidateList()), firstBallot}
+	return call(s, "HandleRunoffBallot", input, plain[decision.Ballot](), func() decision.Ballot {
+		return s.inner.HandleRunoffBallot(baseAgent, params, firstBallot)
+	})
+}
+
+func (s *strategy) ReviseManifesto(baseAgent agent.BaseAgent, manifesto decision.Manifesto, params *decision.ElectionParams) *decision.Manifesto {
+	input := struct {
+		Manifesto  decision.Manifesto
+		Candidates map[commons.ID]decision.Manifesto
+	}{manifesto, immutableToMap(*params.CandidateList())}
+	return call(s, "ReviseManifesto", input, plain[*decision.Manifesto](), func() *decision.Manifesto {
+		return agent.RevisedManifesto(s.inner, baseAgent, manifesto, params)
+	})
+}
+
+func (s *strategy) HandleScoredBallot(baseAgent agent.BaseAgent, params *decision.ElectionParams) decision.Scores {
+	input := struct {
+		Candidates map[commons.ID]decision.Manifesto
+		Strategy   decision.VotingStrategy
+		MaxScore   uint
+	}{immutableToMap(*params.CandidateList()), params.Strategy(), params.MaxScore()}
+	return call(s, "HandleScoredBallot", input, plain[decision.Scores](), func() decision.Scores {
+		return agent.ScoredBallot(s.inner, baseAgent, params)
+	})
+}
+
+func (s *strategy) VoteCredits(baseAgent agent.BaseAgent, remaining uint) uint {
+	input := struct{ Remaining uint }{remaining}
+	return call(s, "VoteCredits", input, plain[uint](), func() uint {
+		return agent.VoteCredits(s.inner, baseAgent, remaining)
+	})
+}
+
+func (s *strategy) AppointCabinet(baseAgent agent.BaseAgent, roles []decision.Role) map[decision.Role]commons.ID {
+	input := struct{ Roles []decision.Role }{roles}
+	return call(s, "AppointCabinet", input, plain[map[decision.Role]commons.ID](), func() map[decision.Role]commons.ID {
+		return agent.AppointCabinet(s.inner, baseAgent, roles)
+	})
+}
+
+func (s *strategy) HandleCabinetConfidencePoll(baseAgent agent.BaseAgent, role decision.Role, holder commons.ID) decision.Intent {
+	input := struct {
+		Role   decision.Role
+		Holder commons.ID
+	}{role, holder}
+	return call(s, "HandleCabinetConfidencePoll", input, plain[decision.Intent](), func() decision.Intent {
+		return agent.CabinetConfidencePoll(s.inner, baseAgent, role, holder)
+	})
+}
+
+func (s *strategy) ProposeAmendment(baseAgent agent.BaseAgent) *decision.Amendment {
+	return call(s, "ProposeAmendment", nil, plain[*decision.Amendment](), func() *decision.Amendment {
+		return agent.ProposeAmendment(s.inner, baseAgent)
+	})
+}
+
+func (s *strategy) HandleReferendum(baseAgent agent.BaseAgent, proposer commons.ID, amendment decision.Amendment) decision.Intent {
+	input := struct {
+		Proposer  commons.ID
+		Amendment decision.Amendment
+	}{proposer, amendment}
+	return call(s, "HandleReferendum", input, plain[decision.Intent](), func() decision.Intent {
+		return agent.ReferendumBallot(s.inner, baseAgent, proposer, amendment)
+	})
+}
+
+func (s *strategy) HandleCampaignInformation(m message.TaggedInformMessage[message.CampaignInform], baseAgent agent.BaseAgent, params *decision.ElectionParams) {
+	call(s, "HandleCampaignInformation", tagged(m.Sender(), m.Message(), m.MID()), none, func() struct{} {
+		s.inner.HandleCampaignInformation(m, baseAgent, params)
+		return struct{}{}
+	})
+}
+
+func (s *strategy) HandleCampaignRequest(m message.TaggedRequestMessage[message.CampaignRequest], baseAgent agent.BaseAgent, params *decision.ElectionParams) message.CampaignInform {
+	return call(s, "HandleCampaignRequest", tagged(m.Sender(), m.Message(), m.MID()), messageCodec[message.CampaignInform](), func() message.CampaignInform {
+		return s.inner.HandleCampaignRequest(m, baseAgent, params)
+	})
+}
+
+/*
+	Loot
+*/
+
+func (s *strategy) HandleLootInformation(m message.TaggedInformMessage[message.LootInform], baseAgent agent.BaseAgent) {
+	call(s, "HandleLootInformation", tagged(m.Sender(), m.Message(), m.MID()), none, func() struct{} {
+		s.inner.HandleLootInformation(m, baseAgent)
+		return struct{}{}
+	})
+}
+
+func (s *strategy) HandleLootRequest(m message.TaggedRequestMessage[message.LootRequest]) message.LootInform {
+	return call(s, "HandleLootRequest", tagged(m.Sender(), m.Message(), m.MID()), messageCodec[message.LootInform](), func() message.LootInform {
+		return s.inner.HandleLootRequest(m)
+	})
+}
+
+func (s *strategy) HandleLootProposal(p message.Proposal[decision.LootAction], baseAgent agent.BaseAgent) decision.Intent {
+	return call(s, "HandleLootProposal", proposed(p), plain[decision.Intent](), func() decision.Intent {
+		return s.inner.HandleLootProposal(p, baseAgent)
+	})
+}
+
+func (s *strategy) HandleLootProposalRequest(p message.Proposal[decision.LootAction], baseAgent agent.BaseAgent) bool {
+	return call(s, "HandleLootProposalRequest", proposed(p), plain[bool](), func() bool {
+		return s.inner.HandleLootProposalRequest(p, baseAgent)
+	})
+}
+
+func (s *strategy) LootAllocation(baseAgent agent.BaseAgent, p message.Proposal[decision.LootAction], proposedAllocations map[commons.ID]map[commons.ItemID]struct{}) immutable.Map[commons.ID, immutable.SortedMap[commons.ItemID, struct{}]] {
+	type allocation = immutable.Map[commons.ID, immutable.SortedMap[commons.ItemID, struct{}]]
+	input := struct {
+		Proposal proposalInput
+		Proposed map[commons.ID]map[commons.ItemID]struct{}
+	}{proposed(p), proposedAllocations}
+	c := codec[allocation]{
+		encode: func(a allocation) (any, error) {
+			out := make(map[commons.ID][]commons.ItemID, a.Len())
+			for id, items := range immutableToMap(a) {
+				out[id] = sortedSetToSlice(items)
+			}
+			return out, nil
+		},
+		decode: func(raw json.RawMessage) (allocation, error) {
+			var m map[commons.ID][]commons.ItemID
+			err := json.Unmarshal(raw, &m)
+			sets := make(map[commons.ID]immutable.SortedMap[commons.ItemID, struct{}], len(m))
+			for id, items := range m {
+				sets[id] = sliceToSortedSet(items)
+			}
+			return commons.MapToImmutable(sets), err
+		},
+	}
+	return call(s, "LootAllocation", input, c, func() allocation {
+		return s.inner.LootAllocation(baseAgent, p, proposedAllocations)
+	})
+}
+
+// itemSetCodec encodes a set of items as a sorted list.
+var itemSetCodec = codec[immutable.SortedMap[commons.ItemID, struct{}]]{
+	encode: func(set immutable.SortedMap[commons.ItemID, struct{}]) (any, error) {
+		return sortedSetToSlice(set), nil
+	},
+	decode: func(raw json.RawMessage) (immutable.SortedMap[commons.ItemID, struct{}], error) {
+		var ids []commons.ItemID
+		err := json.Unmarshal(raw, &ids)
+		return sliceToSortedSet(ids), err
+	},
+}
+
+func (s *strategy) LootActionNoProposal(baseAgent agent.BaseAgent) immutable.SortedMap[commons.ItemID, struct{}] {
+	return call(s, "LootActionNoProposal", nil, itemSetCodec, func() immutable.SortedMap[commons.ItemID, struct{}] {
+		return s.inner.LootActionNoProposal(baseAgent)
+	})
+}
+
+func (s *strategy) LootAction(baseAgent agent.BaseAgent, proposedLoot immutable.SortedMap[commons.ItemID, struct{}], acceptedProposal message.Proposal[decision.LootAction]) immutable.SortedMap[commons.ItemID, struct{}] {
+	input := struct {
+		Proposed []commons.ItemID
+		Accepted commons.ProposalID
+	}{sortedSetToSlice(proposedLoot), acceptedProposal.ProposalID()}
+	return call(s, "LootAction", input, itemSetCodec, func() immutable.SortedMap[commons.ItemID, struct{}] {
+		return s.inner.LootAction(baseAgent, proposedLoot, acceptedProposal)
+	})
+}
+
+// agentsCodec encodes agents by ID, looking them up in agentMap when decoding.
+func agentsCodec[O any](agentMap map[commons.ID]agent.Agent, ids func(O) []commons.ID, build func([]agent.Agent) O) codec[O] {
+	return codec[O]{
+		encode: func(o O) (any, error) { return ids(o), nil },
+		decode: func(raw json.RawMessage) (O, error) {
+			var recorded []commons.ID
+			if err := json.Unmarshal(raw, &recorded); err != nil {
+				var o O
+				return o, err
+			}
+			agents := make([]agent.Agent, 0, len(recorded))
+			for _, id := range recorded {
+				a, ok := agentMap[id]
+				if !ok {
+					var o O
+					return o, fmt.Errorf("replay: recorded agent %s is not in the game", id)
+				}
+				agents = append(agents, a)
+			}
+			return build(agents), nil
+		},
+	}
+}
+
+func (s *strategy) PruneAgentList(baseAgent agent.BaseAgent, agentMap map[commons.ID]agent.Agent) map[commons.ID]agent.Agent {
+	c := agentsCodec(agentMap, sortedIDs[agent.Agent], func(agents []agent.Agent) map[commons.ID]agent.Agent {
+		pruned := make(map[commons.ID]agent.Agent, len(agents))
+		for _, a := range agents {
+			pruned[a.BaseAgent.ID()] = a
+		}
+		return pruned
+	})
+	return call(s, "PruneAgentList", sortedIDs(agentMap), c, func() map[commons.ID]agent.Agent {
+		return s.inner.PruneAgentList(baseAgent, agentMap)
+	})
+}
+
+func (s *strategy) SortAgentsArray(agentMap map[commons.ID]agent.Agent) []agent.Agent {
+	ids := func(agents []agent.Agent) []commons.ID {
+		out := make([]commons.ID, 0, len(agents))
+		for _, a := range agents {
+			out = append(out, a.BaseAgent.ID())
+		}
+		return out
+	}
+	c := agentsCodec(agentMap, ids, func(agents []agent.Agent) []agent.Agent { return agents })
+	return call(s, "SortAgentsArray", sortedIDs(agentMap), c, func() []agent.Agent {
+		return s.inner.SortAgentsArray(agentMap)
+	})
+}
+
+func (s *strategy) ChooseItem(baseAgent agent.BaseAgent, weaponSet []state.Item, shieldSet []state.Item, hpPotionSet []state.Item, staminaPotionSet []state.Item) []state.ItemName {
+	input := struct {
+		Weapons        []state.Item
+		Shields        []state.Item
+		HpPotions      []state.Item
+		StaminaPotions []state.Item
+	}{weaponSet, shieldSet, hpPotionSet, staminaPotionSet}
+	return call(s, "ChooseItem", input, plain[[]state.ItemName](), func() []state.ItemName {
+		return s.inner.ChooseItem(baseAgent, weaponSet, shieldSet, hpPotionSet, staminaPotionSet)
+	})
+}
+
+func (s *strategy) RequestLootProposal(baseAgent agent.BaseAgent) {
+	call(s, "RequestLootProposal", nil, none, func() struct{} {
+		s.inner.RequestLootProposal(baseAgent)
+		return struct{}{}
+	})
+}
+
+func (s *strategy) GetStats() (int, int) {
+	stats := call(s, "GetStats", nil, plain[[2]int](), func() [2]int {
+		personality, sanctioned := s.inner.GetStats()
+		return [2]int{personality, sanctioned}
+	})
+	return stats[0], stats[1]
+}
+
+/*
+	HP pool, trade and trust
+*/
+
+func (s *strategy) SpendHpPool(baseAgent agent.BaseAgent) bool {
+	return call(s, "SpendHpPool", nil, plain[bool](), func() bool {
+		return agent.SpendHpPool(s.inner, baseAgent)
+	})
+}
+
+func (s *strategy) DonateToHpPool(baseAgent agent.BaseAgent) uint {
+	return call(s, "DonateToHpPool", nil, plain[uint](), func() uint {
+		return s.inner.DonateToHpPool(baseAgent)
+	})
+}
+
+func (s *strategy) HandleTradeNegotiation(baseAgent agent.BaseAgent, info message.TradeInfo) message.TradeMessage {
+	// the items on offer are the game's, so only the negotiations are recorded
+	return call(s, "HandleTradeNegotiation", info.Negotiations, messageCodec[message.TradeMessage](), func() message.TradeMessage {
+		return s.inner.HandleTradeNegotiation(baseAgent, info)
+	})
+}
+
+func (s *strategy) CompileTrustMessage(agentMap map[commons.ID]agent.Agent) message.Trust {
+	return call(s, "CompileTrustMessage", nil, plain[message.Trust](), func() message.Trust {
+		return s.inner.CompileTrustMessage(agentMap)
+	})
+}
+
+func (s *strategy) HandleTrustMessage(m message.TaggedMessage) {
+	call(s, "HandleTrustMessage", tagged(m.Sender(), m.Message(), m.MID()), none, func() struct{} {
+		s.inner.HandleTrustMessage(m)
+		return struct{}{}
+	})
+}
+
+/*
+	Items and internal state
+*/
+
+func (s *strategy) HandleUpdateWeapon(baseAgent agent.BaseAgent) decision.ItemIdx {
+	return call(s, "HandleUpdateWeapon", nil, plain[decision.ItemIdx](), func() decision.ItemIdx {
+		return s.inner.HandleUpdateWeapon(baseAgent)
+	})
+}
+
+func (s *strategy) HandleUpdateShield(baseAgent agent.BaseAgent) decision.ItemIdx {
+	return call(s, "HandleUpdateShield", nil, plain[decision.ItemIdx](), func() decision.ItemIdx {
+		return s.inner.HandleUpdateShield(baseAgent)
+	})
+}
+
+func (s *strategy) UpdateInternalState(baseAgent agent.BaseAgent, fightResult *commons.ImmutableList[decision.ImmutableFightResult], voteResult *immutable.Map[decision.Intent, uint], electionResult *decision.ElectionResult, logChan chan<- logging.AgentLog) {
+	input := struct {
+		FightRounds int
+		Votes       map[decision.Intent]uint
+		Election    *decision.ElectionResult `json:",omitempty"`
+	}{fightResult.Len(), make(map[decision.Intent]uint), electionResult}
+	itr := voteResult.Iterator()
+	for !itr.Done() {
+		intent, votes, _ := itr.Next()
+		input.Votes[intent] = votes
+	}
+
+	// the agent's logs are its output, so they are gathered rather than
+	// passed straight on
+	logs := call(s, "UpdateInternalState", input, plain[[]logging.AgentLog](), func() []logging.AgentLog {
+		agentLogs := make(chan logging.AgentLog)
+		gathered := make(chan []logging.AgentLog)
+		go func() {
+			var logs []logging.AgentLog
+			for log := range agentLogs {
+				logs = append(logs, log)
+			}
+			gathered <- logs
+		}()
+		s.inner.UpdateInternalState(baseAgent, fightResult, voteResult, electionResult, agentLogs)
+		close(agentLogs)
+		return <-gathered
+	})
+	for _, log := range logs {
+		logChan <- log
+	}
+}
+
+/*
+	Checkpoints
+*/
+
+func (s *strategy) Snapshot(baseAgent agent.BaseAgent) ([]byte, error) {
+	if snapshotter, ok := s.inner.(agent.Snapshotter); ok {
+		return snapshotter.Snapshot(baseAgent)
+	}
+	return nil, nil
+}
+
+func (s *strategy) Restore(baseAgent agent.BaseAgent, snapshot []byte) error {
+	if snapshotter, ok := s.inner.(agent.Snapshotter); ok {
+		return snapshotter.Restore(baseAgent, snapshot)
+	}
+	return nil
+}
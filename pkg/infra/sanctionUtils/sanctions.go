@@ -1,6 +1,10 @@
 package sanctions
 
-import "infra/game/commons"
+import (
+	"encoding/json"
+
+	"infra/game/commons"
+)
 
 type SanctionActivity struct {
 	sanctionActive bool
@@ -33,5 +37,34 @@ func (s *SanctionActivity) UpdateSanction() {
 
 }
 
-var GlobalSanctionMap map[commons.ID]SanctionActivity = make(map[commons.ID]SanctionActivity)
-var GlobalSanctionHistory map[commons.ID]([]int) = make(map[commons.ID]([]int))
+type sanctionActivityJSON struct {
+	Active   bool
+	Duration int
+}
+
+func (s SanctionActivity) MarshalJSON() ([]byte, error) {
+	return json.Marshal(sanctionActivityJSON{Active: s.sanctionActive, Duration: s.duration})
+}
+
+func (s *SanctionActivity) UnmarshalJSON(data []byte) error {
+	var j sanctionActivityJSON
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	*s = SanctionActivity{sanctionActive: j.Active, duration: j.Duration}
+	return nil
+}
+
+// Ledger holds the sanctions that outlive a single leader's term when
+// persistent sanctions are enabled. There is one ledger per game.
+type Ledger struct {
+	Active  map[commons.ID]SanctionActivity
+	History map[commons.ID]([]int)
+}
+
+func NewLedger() *Ledger {
+	return &Ledger{
+		Active:  make(map[commons.ID]SanctionActivity),
+		History: make(map[commons.ID]([]int)),
+	}
+}
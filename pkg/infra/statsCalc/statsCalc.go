@@ -66,5 +66,3 @@ func (s *StatsCalc) GetStdDev(m Metric) float64 {
 	stArray := s.selectStat(m)
 	return stat.StdDev(stArray, nil)
 }
-
-var Calc StatsCalc = StatsCalc{}
@@ -0,0 +1,98 @@
+package sweep
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+)
+
+// Targets maps the first part of a parameter name to what it sets: a pointer
+// to a struct, whose fields are set by name, or a pointer to a map, whose keys
+// are set.
+type Targets map[string]any
+
+// Apply sets every parameter of point on its target.
+func Apply(point Point, targets Targets) error {
+	for name, value := range point {
+		targetName, field, ok := strings.Cut(name, ".")
+		if !ok {
+			return fmt.Errorf("sweep: parameter %q is not of the form <Target>.<Field>", name)
+		}
+		target, ok := targets[targetName]
+		if !ok {
+			return fmt.Errorf("sweep: parameter %q has unknown target %q", name, targetName)
+		}
+		if err := set(reflect.ValueOf(target), field, value); err != nil {
+			return fmt.Errorf("sweep: parameter %q: %w", name, err)
+		}
+	}
+	return nil
+}
+
+func set(target reflect.Value, field string, value any) error {
+	if target.Kind() != reflect.Pointer || target.IsNil() {
+		return fmt.Errorf("target must be a non-nil pointer, got %s", target.Type())
+	}
+	target = target.Elem()
+
+	switch target.Kind() {
+	case reflect.Struct:
+		f := target.FieldByName(field)
+		if !f.IsValid() || !f.CanSet() {
+			return fmt.Errorf("%s has no field %q", target.Type(), field)
+		}
+		return convert(f, value)
+	case reflect.Map:
+		if target.IsNil() {
+			target.Set(reflect.MakeMap(target.Type()))
+		}
+		elem := reflect.New(target.Type().Elem()).Elem()
+		if err := convert(elem, value); err != nil {
+			return err
+		}
+		target.SetMapIndex(reflect.ValueOf(field).Convert(target.Type().Key()), elem)
+		return nil
+	default:
+		return fmt.Errorf("cannot set fields of %s", target.Type())
+	}
+}
+
+// convert stores value, as decoded from JSON, in dst.
+func convert(dst reflect.Value, value any) error {
+	switch dst.Kind() {
+	case reflect.Bool:
+		b, ok := value.(bool)
+		if !ok {
+			return fmt.Errorf("want a bool, got %v", value)
+		}
+		dst.SetBool(b)
+	case reflect.String:
+		s, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("want a string, got %v", value)
+		}
+		dst.SetString(s)
+	case reflect.Float32, reflect.Float64:
+		n, ok := value.(float64)
+		if !ok {
+			return fmt.Errorf("want a number, got %v", value)
+		}
+		dst.SetFloat(n)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, ok := value.(float64)
+		if !ok || n != math.Trunc(n) {
+			return fmt.Errorf("want an integer, got %v", value)
+		}
+		dst.SetInt(int64(n))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, ok := value.(float64)
+		if !ok || n != math.Trunc(n) || n < 0 {
+			return fmt.Errorf("want a non-negative integer, got %v", value)
+		}
+		dst.SetUint(uint64(n))
+	default:
+		return fmt.Errorf("cannot sweep over %s", dst.Type())
+	}
+	return nil
+}
@@ -0,0 +1,173 @@
+package sweep
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"sort"
+
+	"infra/game/rng"
+)
+
+type Search string
+
+const (
+	// Grid plays every combination of every parameter's values.
+	Grid Search = "grid"
+	// Random plays Samples combinations, drawing each parameter independently.
+	Random Search = "random"
+)
+
+// Spec describes a parameter sweep, eg.
+//
+//	{
+//		"Search": "grid",
+//		"Replicates": 20,
+//		"Seed": 1,
+//		"Parameters": {
+//			"Game.Defection": {"Values": [true, false]},
+//			"Agents.SELFISH": {"Values": [10, 30, 50]},
+//			"Sanctions.FixedSanctionDuration": {"Values": [1, 5]},
+//			"Team3.SelfishPersonality": {"Values": [10, 25]}
+//		}
+//	}
+//
+// Parameter names are "<Target>.<Field>", where the targets are set up by the
+// caller (see Targets).
+type Spec struct {
+	Search Search
+	// Samples is the number of combinations drawn by a random search.
+	Samples int
+	// Replicates is the number of games played for each combination.
+	Replicates int
+	// Seed seeds the random search and the games. Every combination plays
+	// the same sequence of game seeds, so combinations are compared on
+	// common random numbers.
+	Seed       int64
+	Parameters map[string]Parameter
+}
+
+// Parameter is the set of values a parameter is swept over: either a list of
+// Values, or, for random search over numeric fields, the range [Min, Max].
+type Parameter struct {
+	Values []any
+	Min    *float64
+	Max    *float64
+}
+
+// Point is a single combination of parameter values.
+type Point map[string]any
+
+// Load reads a JSON sweep spec from path.
+func Load(path string) (Spec, error) {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return Spec{}, fmt.Errorf("sweep: %w", err)
+	}
+	var spec Spec
+	if err := json.Unmarshal(buf, &spec); err != nil {
+		return Spec{}, fmt.Errorf("sweep: failed to parse %s: %w", path, err)
+	}
+	return spec, spec.Validate()
+}
+
+func (s Spec) Validate() error {
+	if s.Replicates <= 0 {
+		return errors.New("sweep: Replicates must be positive")
+	}
+	if len(s.Parameters) == 0 {
+		return errors.New("sweep: no parameters to sweep")
+	}
+	switch s.Search {
+	case Grid:
+		for name, p := range s.Parameters {
+			if len(p.Values) == 0 {
+				return fmt.Errorf("sweep: grid parameter %q has no values", name)
+			}
+		}
+	case Random:
+		if s.Samples <= 0 {
+			return errors.New("sweep: random search needs a positive number of Samples")
+		}
+		for name, p := range s.Parameters {
+			isRange := p.Min != nil && p.Max != nil
+			if isRange == (len(p.Values) > 0) {
+				return fmt.Errorf("sweep: random parameter %q needs either Values or both Min and Max", name)
+			}
+			if isRange && *p.Min > *p.Max {
+				return fmt.Errorf("sweep: random parameter %q has Min greater than Max", name)
+			}
+		}
+	default:
+		return fmt.Errorf("sweep: unknown search %q, want %q or %q", s.Search, Grid, Random)
+	}
+	return nil
+}
+
+// Names returns the swept parameter names in a stable order.
+func (s Spec) Names() []string {
+	names := make([]string, 0, len(s.Parameters))
+	for name := range s.Parameters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Expand returns the combinations the sweep plays.
+func (s Spec) Expand() ([]Point, error) {
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+	if s.Search == Random {
+		return s.expandRandom(), nil
+	}
+	return s.expandGrid(), nil
+}
+
+func (s Spec) expandGrid() []Point {
+	points := []Point{{}}
+	for _, name := range s.Names() {
+		values := s.Parameters[name].Values
+		next := make([]Point, 0, len(points)*len(values))
+		for _, point := range points {
+			for _, value := range values {
+				p := make(Point, len(point)+1)
+				for k, v := range point {
+					p[k] = v
+				}
+				p[name] = value
+				next = append(next, p)
+			}
+		}
+		points = next
+	}
+	return points
+}
+
+func (s Spec) expandRandom() []Point {
+	r := rng.NewSource(s.Seed).Stream("sweep")
+	names := s.Names()
+	points := make([]Point, s.Samples)
+	for i := range points {
+		points[i] = make(Point, len(names))
+		for _, name := range names {
+			points[i][name] = s.Parameters[name].sample(r)
+		}
+	}
+	return points
+}
+
+func (p Parameter) sample(r *rand.Rand) any {
+	if len(p.Values) > 0 {
+		return p.Values[r.Intn(len(p.Values))]
+	}
+	// ranges over integral bounds are sampled as integers
+	if *p.Min == math.Trunc(*p.Min) && *p.Max == math.Trunc(*p.Max) {
+		return *p.Min + float64(r.Int63n(int64(*p.Max-*p.Min)+1))
+	}
+	return *p.Min + r.Float64()*(*p.Max-*p.Min)
+}
@@ -0,0 +1,129 @@
+package sweep
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"infra/batch"
+	"infra/engine"
+)
+
+// Build returns the game config and strategies to play a combination with,
+// usually by applying the point to a base config with Apply.
+type Build func(point Point) (engine.Config, engine.Registry, error)
+
+// Options describes how to run a sweep.
+type Options struct {
+	Spec Spec
+	// Workers bounds how many games of a combination run at once.
+	Workers int
+	// Dir is the run directory. Each combination is played as a batch in
+	// Dir/points/<index> and the results table is written to Dir/results.csv.
+	Dir   string
+	Build Build
+}
+
+// Row is the result of playing a single combination.
+type Row struct {
+	Index   int
+	Point   Point
+	Summary batch.Summary
+}
+
+// Run plays every combination of the sweep and tabulates the results, one row
+// per combination.
+func Run(ctx context.Context, opts Options) ([]Row, error) {
+	points, err := opts.Spec.Expand()
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]Row, 0, len(points))
+	for i, point := range points {
+		cfg, registry, err := opts.Build(point)
+		if err != nil {
+			return nil, fmt.Errorf("sweep: combination %d: %w", i, err)
+		}
+
+		pointDir := filepath.Join(opts.Dir, "points", strconv.Itoa(i))
+		summary, err := batch.Run(ctx, batch.Options{
+			Games:    opts.Spec.Replicates,
+			Workers:  opts.Workers,
+			Seed:     opts.Spec.Seed,
+			Dir:      pointDir,
+			Config:   cfg,
+			Registry: registry,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("sweep: combination %d: %w", i, err)
+		}
+		if err := writePoint(filepath.Join(pointDir, "point.json"), point); err != nil {
+			return nil, err
+		}
+		rows = append(rows, Row{Index: i, Point: point, Summary: summary})
+	}
+
+	if err := writeTable(filepath.Join(opts.Dir, "results.csv"), opts.Spec.Names(), rows); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+func writePoint(path string, point Point) error {
+	jsonBuf, err := json.MarshalIndent(point, "", "\t")
+	if err != nil {
+		return fmt.Errorf("sweep: failed to marshal combination: %w", err)
+	}
+	if err := os.WriteFile(path, jsonBuf, 0666); err != nil {
+		return fmt.Errorf("sweep: failed to write combination: %w", err)
+	}
+	return nil
+}
+
+func writeTable(path string, names []string, rows []Row) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("sweep: failed to create results table: %w", err)
+	}
+	defer file.Close()
+
+	w := csv.NewWriter(file)
+	header := append([]string{"point"}, names...)
+	header = append(header,
+		"games", "wins", "win rate", "win rate low", "win rate high",
+		"mean level", "mean level low", "mean level high",
+		"survivors mean", "survivors median", "survivors min", "survivors max",
+	)
+	_ = w.Write(header)
+
+	for _, row := range rows {
+		s := row.Summary
+		record := []string{strconv.Itoa(row.Index)}
+		for _, name := range names {
+			record = append(record, fmt.Sprint(row.Point[name]))
+		}
+		record = append(record,
+			strconv.Itoa(s.Games), strconv.Itoa(s.Wins),
+			formatFloat(s.WinRate), formatFloat(s.WinRateCI.Low), formatFloat(s.WinRateCI.High),
+			formatFloat(s.MeanLevel), formatFloat(s.MeanLevelCI.Low), formatFloat(s.MeanLevelCI.High),
+			formatFloat(s.Survivors.Mean), formatFloat(s.Survivors.Median),
+			strconv.Itoa(int(s.Survivors.Min)), strconv.Itoa(int(s.Survivors.Max)),
+		)
+		_ = w.Write(record)
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return fmt.Errorf("sweep: failed to write results table: %w", err)
+	}
+	return nil
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', 4, 64)
+}
@@ -0,0 +1,170 @@
+package sweep_test
+
+import (
+	"reflect"
+	"testing"
+
+	cmdline "infra/cmdLine"
+	"infra/config"
+	"infra/sweep"
+)
+
+func float(f float64) *float64 {
+	return &f
+}
+
+func TestExpand(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		spec       sweep.Spec
+		wantPoints int
+		wantErr    bool
+	}{
+		{
+			name: "grid is the cartesian product",
+			spec: sweep.Spec{Search: sweep.Grid, Replicates: 1, Parameters: map[string]sweep.Parameter{
+				"Game.Defection": {Values: []any{true, false}},
+				"Agents.SELFISH": {Values: []any{10.0, 20.0, 30.0}},
+			}},
+			wantPoints: 6,
+		},
+		{
+			name: "random draws samples",
+			spec: sweep.Spec{Search: sweep.Random, Samples: 5, Replicates: 1, Parameters: map[string]sweep.Parameter{
+				"Game.NumLevels": {Min: float(10), Max: float(60)},
+			}},
+			wantPoints: 5,
+		},
+		{
+			name: "grid needs values",
+			spec: sweep.Spec{Search: sweep.Grid, Replicates: 1, Parameters: map[string]sweep.Parameter{
+				"Game.NumLevels": {Min: float(10), Max: float(60)},
+			}},
+			wantErr: true,
+		},
+		{
+			name:    "unknown search",
+			spec:    sweep.Spec{Search: "annealing", Replicates: 1, Parameters: map[string]sweep.Parameter{"Game.NumLevels": {Values: []any{1.0}}}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			points, err := tt.spec.Expand()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Expand() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(points) != tt.wantPoints {
+				t.Errorf("Expand() returned %d points, want %d", len(points), tt.wantPoints)
+			}
+			again, _ := tt.spec.Expand()
+			if !reflect.DeepEqual(points, again) {
+				t.Errorf("Expand() is not reproducible: %v != %v", points, again)
+			}
+		})
+	}
+}
+
+func TestRandomStaysInRange(t *testing.T) {
+	t.Parallel()
+
+	spec := sweep.Spec{Search: sweep.Random, Samples: 100, Replicates: 1, Seed: 3, Parameters: map[string]sweep.Parameter{
+		"Game.NumLevels":           {Min: float(10), Max: float(12)},
+		"Game.ThresholdPercentage": {Min: float(0.1), Max: float(0.2)},
+	}}
+	points, err := spec.Expand()
+	if err != nil {
+		t.Fatalf("Expand() error = %v", err)
+	}
+	for _, point := range points {
+		levels := point["Game.NumLevels"].(float64)
+		if levels != 10 && levels != 11 && levels != 12 {
+			t.Errorf("NumLevels = %v, want an integer in [10, 12]", levels)
+		}
+		threshold := point["Game.ThresholdPercentage"].(float64)
+		if threshold < 0.1 || threshold > 0.2 {
+			t.Errorf("ThresholdPercentage = %v, want within [0.1, 0.2]", threshold)
+		}
+	}
+}
+
+func TestApply(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		point         sweep.Point
+		wantGame      config.GameConfig
+		wantSanctions cmdline.CmdLine
+		wantErr       bool
+	}{
+		{
+			name: "struct fields and map keys",
+			point: sweep.Point{
+				"Game.NumLevels":                  30.0,
+				"Game.ThresholdPercentage":        0.5,
+				"Game.Defection":                  true,
+				"Agents.SELFISH":                  12.0,
+				"Sanctions.FixedSanctionDuration": 4.0,
+			},
+			wantGame: config.GameConfig{
+				NumLevels:           30,
+				ThresholdPercentage: 0.5,
+				Defection:           true,
+				AgentQuantities:     map[string]uint{"SELFISH": 12},
+			},
+			wantSanctions: cmdline.CmdLine{FixedSanctionDuration: 4},
+		},
+		{
+			name:    "unknown field",
+			point:   sweep.Point{"Game.Levels": 30.0},
+			wantErr: true,
+		},
+		{
+			name:    "unknown target",
+			point:   sweep.Point{"Team9.Personality": 30.0},
+			wantErr: true,
+		},
+		{
+			name:    "wrong type",
+			point:   sweep.Point{"Game.Defection": 1.0},
+			wantErr: true,
+		},
+		{
+			name:    "negative quantity",
+			point:   sweep.Point{"Agents.SELFISH": -1.0},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var game config.GameConfig
+			var sanctions cmdline.CmdLine
+			err := sweep.Apply(tt.point, sweep.Targets{
+				"Game":      &game,
+				"Agents":    &game.AgentQuantities,
+				"Sanctions": &sanctions,
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Apply() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !reflect.DeepEqual(game, tt.wantGame) {
+				t.Errorf("Apply() game = %+v, want %+v", game, tt.wantGame)
+			}
+			if sanctions != tt.wantSanctions {
+				t.Errorf("Apply() sanctions = %+v, want %+v", sanctions, tt.wantSanctions)
+			}
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"infra/game/agent"
 	"infra/game/commons"
 	"infra/game/example"
+	"infra/game/rng"
 	"infra/game/stage/initialise"
 	"infra/game/state"
 )
@@ -23,7 +24,7 @@ var InitAgentMap = map[commons.ID]func() agent.Strategy{
 	// "CowardlyAgent": NewProbabilisticAgent(0.9, 0.05, 0.05),
 }
 
-func InitAgents(defaultStrategyMap map[commons.ID]func() agent.Strategy, gameConfig config.GameConfig, ptr *state.View) (numAgents uint, agentMap map[commons.ID]agent.Agent, agentStateMap map[commons.ID]state.AgentState, inventoryMap state.InventoryMap) {
+func InitAgents(defaultStrategyMap map[commons.ID]func() agent.Strategy, gameConfig config.GameConfig, ptr *state.View, source *rng.Source) (numAgents uint, agentMap map[commons.ID]agent.Agent, agentStateMap map[commons.ID]state.AgentState, inventoryMap state.InventoryMap) {
 	agentMap = make(map[commons.ID]agent.Agent)
 	agentStateMap = make(map[commons.ID]state.AgentState)
 	inventoryMap = state.InventoryMap{
@@ -34,11 +35,13 @@ func InitAgents(defaultStrategyMap map[commons.ID]func() agent.Strategy, gameCon
 	numAgents = 0
 
 	for agentName, strategy := range defaultStrategyMap {
-		expectedEnvName := "AGENT_" + agentName + "_QUANTITY"
-		quantity := config.EnvToUint(expectedEnvName, 100)
+		quantity, ok := gameConfig.AgentQuantities[agentName]
+		if !ok {
+			quantity = 100
+		}
 
 		numAgents += quantity
-		initialise.InstantiateAgent(gameConfig, agentMap, agentStateMap, quantity, strategy, agentName, ptr)
+		initialise.InstantiateAgent(gameConfig, agentMap, agentStateMap, quantity, strategy, agentName, ptr, source)
 	}
 
 	return
@@ -1,8 +1,6 @@
 package team0
 
 import (
-	"math/rand"
-
 	"infra/game/agent"
 	"infra/game/commons"
 	"infra/game/decision"
@@ -37,7 +35,7 @@ func NewProbabilisticAgent(pCower float32, pAttack float32, pDefend float32) *Pr
 }
 
 func (r ProbabilisticAgent) HandleFightMessage(m message.TaggedMessage, view *state.View, agent agent.BaseAgent, log *immutable.Map[commons.ID, decision.FightAction]) decision.FightAction {
-	dice := rand.Float32()
+	dice := agent.Rand().Float32()
 
 	fight := 0
 	for dice > r.fightDecisionCDF[fight] {
@@ -1,7 +1,6 @@
 package team3
 
 import (
-	cmdline "infra/cmdLine"
 	"infra/config"
 	"infra/game/agent"
 	"infra/game/commons"
@@ -20,18 +19,19 @@ type AgentThree struct {
 	fightRoundsHistory commons.ImmutableList[decision.ImmutableFightResult]
 	numAgents          int
 
-	personality    int
-	TSN            []commons.ID
-	reputationMap  map[commons.ID]float64
-	socialCap      map[commons.ID]int
-	w1Map          map[commons.ID]float64
-	w2Map          map[commons.ID]float64
-	pastHPMap      map[commons.ID]int
-	pastStaminaMap map[commons.ID]int
-	statsQueue     StatsQueue
-	changeInit     float64
-	alpha          float64
-	samplePercent  float64
+	personality       int
+	updatePersonality bool
+	TSN               []commons.ID
+	reputationMap     map[commons.ID]float64
+	socialCap         map[commons.ID]int
+	w1Map             map[commons.ID]float64
+	w2Map             map[commons.ID]float64
+	pastHPMap         map[commons.ID]int
+	pastStaminaMap    map[commons.ID]int
+	statsQueue        StatsQueue
+	changeInit        float64
+	alpha             float64
+	samplePercent     float64
 
 	uR                map[commons.ID]int
 	uP                map[commons.ID]int
@@ -53,7 +53,7 @@ type AgentThree struct {
 }
 
 // Update internal parameters at the end of each stage
-func (a *AgentThree) UpdateInternalState(baseAgent agent.BaseAgent, history *commons.ImmutableList[decision.ImmutableFightResult], votes *immutable.Map[decision.Intent, uint], log chan<- logging.AgentLog) {
+func (a *AgentThree) UpdateInternalState(baseAgent agent.BaseAgent, history *commons.ImmutableList[decision.ImmutableFightResult], votes *immutable.Map[decision.Intent, uint], _ *decision.ElectionResult, log chan<- logging.AgentLog) {
 	AS := baseAgent.AgentState()
 	view := baseAgent.View()
 
@@ -79,12 +79,11 @@ func (a *AgentThree) UpdateInternalState(baseAgent agent.BaseAgent, history *com
 		// Init SC (25)
 		a.InitSocialCapital(baseAgent)
 
-		// sloppy global access - consider refactor later
-		// also should be enforced by outer scope (i.e., main.go)
-		// this is the issue with having activeSanctionMap as a local var only
-		if cmdline.CmdLineInits.PersistentSanctions {
-			a.activeSanctionMap = sanctions.GlobalSanctionMap
-			a.sanctionHistory = sanctions.GlobalSanctionHistory
+		// share the game's sanction ledger so that sanctions survive a change of leader
+		if view.SanctionConfig().PersistentSanctions {
+			ledger := view.SanctionLedger()
+			a.activeSanctionMap = ledger.Active
+			a.sanctionHistory = ledger.History
 		}
 
 	}
@@ -105,8 +104,7 @@ func (a *AgentThree) UpdateInternalState(baseAgent agent.BaseAgent, history *com
 	a.UpdateTSN(baseAgent)
 
 	// if personality enabled, update it
-	enablePersonalityUpdate := config.EnvToBool("UPDATE_PERSONALITY", true)
-	if enablePersonalityUpdate {
+	if a.updatePersonality {
 		// update internal personality
 		a.UpdatePersonality(baseAgent)
 	}
@@ -157,38 +155,21 @@ func (a *AgentThree) UpdatePersonality(baseAgent agent.BaseAgent) {
 	a.changeInit = changeNow
 }
 
-func NewAgentThreeNeutral() agent.Strategy {
-	dis := config.EnvToUint("COLLECTIVE_PER", 50)
-	// dis, _ := strconv.ParseInt(os.Getenv("PASSIVE_PER"), 10, 0)
-	return &AgentThree{
-		utilityScore:      CreateUtility(),
-		uR:                CreateUtility(),
-		uP:                CreateUtility(),
-		uC:                CreateUtility(),
-		chairTolerance:    0,
-		proposalTolerance: make(map[commons.ID]int, 0),
-		personality:       int(dis),
-		reputationMap:     make(map[commons.ID]float64, 0),
-		w1Map:             make(map[commons.ID]float64, 0),
-		w2Map:             make(map[commons.ID]float64, 0),
-		pastHPMap:         make(map[commons.ID]int, 0),
-		pastStaminaMap:    make(map[commons.ID]int, 0),
-		socialCap:         make(map[commons.ID]int, 25),
-		sanctioned:        0,
-		statsQueue:        *makeStatsQueue(3),
-		changeInit:        0,
-		alpha:             5,
-		samplePercent:     0.25,
-		sanctionHistory:   make(map[commons.ID]([]int)),
-		activeSanctionMap: make(map[commons.ID]sanctions.SanctionActivity),
-		mutex:             sync.RWMutex{},
-		sanctionLength:    0,
+// Settings are the tunable parameters of team 3's agents, as set in the game
+// config.
+type Settings = config.Team3Settings
+
+// Strategies returns constructors for the selfish, collective and selfless
+// agents, keyed by their names in the agent map.
+func Strategies(s Settings) map[commons.ID]func() agent.Strategy {
+	return map[commons.ID]func() agent.Strategy{
+		"COLLECTIVE": func() agent.Strategy { return NewAgentThree(s.CollectivePersonality, s.UpdatePersonality) },
+		"SELFLESS":   func() agent.Strategy { return NewAgentThree(s.SelflessPersonality, s.UpdatePersonality) },
+		"SELFISH":    func() agent.Strategy { return NewAgentThree(s.SelfishPersonality, s.UpdatePersonality) },
 	}
 }
 
-func NewAgentThreePassive() agent.Strategy {
-	dis := config.EnvToUint("SELFLESS_PER", 75)
-	// dis, _ := strconv.ParseInt(os.Getenv("COLLECTIVE_PER"), 10, 0)
+func NewAgentThree(personality uint, updatePersonality bool) agent.Strategy {
 	return &AgentThree{
 		utilityScore:      CreateUtility(),
 		uR:                CreateUtility(),
@@ -196,7 +177,8 @@ func NewAgentThreePassive() agent.Strategy {
 		uC:                CreateUtility(),
 		chairTolerance:    0,
 		proposalTolerance: make(map[commons.ID]int, 0),
-		personality:       int(dis),
+		personality:       int(personality),
+		updatePersonality: updatePersonality,
 		reputationMap:     make(map[commons.ID]float64, 0),
 		w1Map:             make(map[commons.ID]float64, 0),
 		w2Map:             make(map[commons.ID]float64, 0),
@@ -214,31 +196,15 @@ func NewAgentThreePassive() agent.Strategy {
 		sanctionLength:    0,
 	}
 }
+
+func NewAgentThreeNeutral() agent.Strategy {
+	return Strategies(config.Default().Game.Team3)["COLLECTIVE"]()
+}
+
+func NewAgentThreePassive() agent.Strategy {
+	return Strategies(config.Default().Game.Team3)["SELFLESS"]()
+}
+
 func NewAgentThreeAggressive() agent.Strategy {
-	dis := config.EnvToUint("SELFISH_PER", 25)
-	// dis, _ := strconv.ParseInt(os.Getenv("SELFISH_PER"), 10, 0)
-	return &AgentThree{
-		utilityScore:      CreateUtility(),
-		uR:                CreateUtility(),
-		uP:                CreateUtility(),
-		uC:                CreateUtility(),
-		chairTolerance:    0,
-		proposalTolerance: make(map[commons.ID]int, 0),
-		personality:       int(dis),
-		reputationMap:     make(map[commons.ID]float64, 0),
-		w1Map:             make(map[commons.ID]float64, 0),
-		w2Map:             make(map[commons.ID]float64, 0),
-		pastHPMap:         make(map[commons.ID]int, 0),
-		pastStaminaMap:    make(map[commons.ID]int, 0),
-		socialCap:         make(map[commons.ID]int, 25),
-		sanctioned:        0,
-		statsQueue:        *makeStatsQueue(3),
-		changeInit:        0,
-		alpha:             5,
-		samplePercent:     0.25,
-		sanctionHistory:   make(map[commons.ID]([]int)),
-		activeSanctionMap: make(map[commons.ID]sanctions.SanctionActivity),
-		mutex:             sync.RWMutex{},
-		sanctionLength:    0,
-	}
+	return Strategies(config.Default().Game.Team3)["SELFISH"]()
 }
@@ -2,17 +2,16 @@ package team3
 
 import (
 	"infra/game/agent"
-	"time"
 
 	"infra/game/commons"
 	"math"
 	"sort"
 
 	"infra/game/decision"
+	"infra/game/message"
 	"infra/game/state"
 
 	// "infra/logging"
-	"math/rand"
 
 	"github.com/benbjohnson/immutable"
 )
@@ -25,7 +24,7 @@ type pair struct {
 // Handle No Confidence vote
 func (a *AgentThree) HandleConfidencePoll(baseAgent agent.BaseAgent) decision.Intent {
 	// decide whether to vote in the no-confidence vote based on personality
-	toVote := rand.Intn(100)
+	toVote := baseAgent.Rand().Intn(100)
 
 	if toVote < a.personality {
 		view := baseAgent.View()
@@ -86,7 +85,7 @@ func (a *AgentThree) HandleElectionBallot(baseAgent agent.BaseAgent, param *deci
 		return candidateArray[i].val > candidateArray[j].val
 	})
 	// should we vote?
-	makeVote := rand.Intn(100)
+	makeVote := baseAgent.Rand().Intn(100)
 	// if makeVote is lower than personality, then vote.
 	if makeVote < a.personality {
 		// Create Ballot
@@ -107,6 +106,49 @@ func (a *AgentThree) HandleElectionBallot(baseAgent agent.BaseAgent, param *deci
 	}
 }
 
+func (a *AgentThree) HandleRunoffBallot(_ agent.BaseAgent, param *decision.ElectionParams, firstBallot decision.Ballot) decision.Ballot {
+	return agent.DefaultRunoffBallot(param, firstBallot)
+}
+
+// Candidates promise to keep their manifestos' policies, and voters with a
+// collective or selfless personality endorse the candidate they rate highest.
+func (a *AgentThree) HandleCampaignInformation(m message.TaggedInformMessage[message.CampaignInform], baseAgent agent.BaseAgent, param *decision.ElectionParams) {
+	if _, ok := m.Message().(message.StartCampaign); !ok {
+		return
+	}
+	if manifesto, ok := param.CandidateList().Get(baseAgent.ID()); ok {
+		baseAgent.BroadcastBlockingMessage(a.promise(manifesto))
+		return
+	}
+	if a.personality < 50 {
+		return
+	}
+
+	var best pair
+	iterator := param.CandidateList().Iterator()
+	for !iterator.Done() {
+		id, _, _ := iterator.Next()
+		val := a.reputationMap[id] + float64(a.socialCap[id])
+		if best.id == "" || val > best.val || (val == best.val && id < best.id) {
+			best = pair{id, val}
+		}
+	}
+	if best.id != "" {
+		baseAgent.BroadcastBlockingMessage(message.Endorsement{Candidate: best.id})
+	}
+}
+
+func (a *AgentThree) HandleCampaignRequest(_ message.TaggedRequestMessage[message.CampaignRequest], baseAgent agent.BaseAgent, param *decision.ElectionParams) message.CampaignInform {
+	if manifesto, ok := param.CandidateList().Get(baseAgent.ID()); ok {
+		return a.promise(manifesto)
+	}
+	return nil
+}
+
+func (a *AgentThree) promise(manifesto decision.Manifesto) message.Promise {
+	return message.Promise{Policies: manifesto.Policies(), Statement: "I will keep to my manifesto"}
+}
+
 func (a *AgentThree) calcW1(state state.HiddenAgentState, id commons.ID) float64 {
 	w1 := a.w1Map[id]
 	currentHP := state.Hp
@@ -175,8 +217,7 @@ func (a *AgentThree) Reputation(baseAgent agent.BaseAgent) {
 
 	ids := commons.ImmutableMapKeys(vAS)
 	// get random shuffle of agent ids
-	rand.Seed(time.Now().UnixNano())
-	rand.Shuffle(len(ids), func(i, j int) { ids[i], ids[j] = ids[j], ids[i] })
+	baseAgent.Rand().Shuffle(len(ids), func(i, j int) { ids[i], ids[j] = ids[j], ids[i] })
 
 	productivity := 5.0
 	needs := 5.0
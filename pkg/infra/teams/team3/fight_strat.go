@@ -1,8 +1,6 @@
 package team3
 
 import (
-	"math/rand"
-
 	"infra/game/agent"
 	"infra/game/commons"
 	"infra/game/decision"
@@ -41,7 +39,7 @@ func (a *AgentThree) FightAction(
 	proposedAction decision.FightAction,
 	acceptedProposal message.Proposal[decision.FightAction],
 ) decision.FightAction {
-	disobey := rand.Intn(100)
+	disobey := baseAgent.Rand().Intn(100)
 	// if disobey value is lower than personality then do not defect
 	// lower personality values mean more selfish (therefore more likely to defect)
 	if disobey < a.personality {
@@ -71,7 +69,7 @@ func (a *AgentThree) HandleFightInformation(m message.TaggedInformMessage[messag
 	// fmt.Println(m)
 
 	// should i make a proposal (based on personality)
-	makesProposal := rand.Intn(100)
+	makesProposal := baseAgent.Rand().Intn(100)
 	// if makesProposal is lower than personality, then make proposal
 	// low personality scores mean more selfish,
 	if makesProposal < a.personality {
@@ -150,7 +148,7 @@ func (a *AgentThree) CurrentAction(baseAgent agent.BaseAgent) decision.FightActi
 // Vote on proposal
 func (a *AgentThree) HandleFightProposal(m message.Proposal[decision.FightAction], baseAgent agent.BaseAgent) decision.Intent {
 	// determine whether to vote based on personality.
-	intent := rand.Intn(100)
+	intent := baseAgent.Rand().Intn(100)
 
 	// rules := m.Rules()
 	// itr := rules.Iterator()
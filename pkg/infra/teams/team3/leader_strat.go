@@ -15,12 +15,13 @@ import (
 	statscalc "infra/statsCalc"
 
 	"github.com/benbjohnson/immutable"
+	"golang.org/x/exp/maps"
 )
 
 // Manifesto
-func (a *AgentThree) CreateManifesto(_ agent.BaseAgent) *decision.Manifesto {
+func (a *AgentThree) CreateManifesto(baseAgent agent.BaseAgent) *decision.Manifesto {
 	// Submit Manifesto?
-	submitManifesto := rand.Intn(100)
+	submitManifesto := baseAgent.Rand().Intn(100)
 	if submitManifesto < a.personality {
 		// Enter manifesto logic here for creating a manifesto
 		manifesto := decision.NewManifesto(false, false, 30, 50)
@@ -33,8 +34,8 @@ func (a *AgentThree) CreateManifesto(_ agent.BaseAgent) *decision.Manifesto {
 }
 
 // Leader function to grant the floor?
-func (a *AgentThree) HandleFightProposalRequest(_ message.Proposal[decision.FightAction], _ agent.BaseAgent, _ *immutable.Map[commons.ID, decision.FightAction]) bool {
-	switch rand.Intn(2) {
+func (a *AgentThree) HandleFightProposalRequest(_ message.Proposal[decision.FightAction], baseAgent agent.BaseAgent, _ *immutable.Map[commons.ID, decision.FightAction]) bool {
+	switch baseAgent.Rand().Intn(2) {
 	case 0:
 		return true
 	default:
@@ -56,7 +57,7 @@ func (a *AgentThree) FightResolution(baseAgent agent.BaseAgent, prop commons.Imm
 			action := a.FightActionNoProposal(baseAgent)
 			fightAction = action
 		} else {
-			switch rand.Intn(3) {
+			switch baseAgent.Rand().Intn(3) {
 			case 0:
 				fightAction = decision.Attack
 			case 1:
@@ -79,8 +80,8 @@ func (a *AgentThree) HandleLootRequest(m message.TaggedRequestMessage[message.Lo
 	panic("implement me")
 }
 
-func (a *AgentThree) HandleLootProposalRequest(_ message.Proposal[decision.LootAction], _ agent.BaseAgent) bool {
-	switch rand.Intn(2) {
+func (a *AgentThree) HandleLootProposalRequest(_ message.Proposal[decision.LootAction], baseAgent agent.BaseAgent) bool {
+	switch baseAgent.Rand().Intn(2) {
 	case 0:
 		return true
 	default:
@@ -93,13 +94,13 @@ func (a *AgentThree) LootAllocation(baseAgent agent.BaseAgent, proposal message.
 	view := baseAgent.View()
 	ids := commons.ImmutableMapKeys(view.AgentState())
 	iterator := baseAgent.Loot().Weapons().Iterator()
-	allocateRandomly(iterator, ids, lootAllocation)
+	allocateRandomly(baseAgent.Rand(), iterator, ids, lootAllocation)
 	iterator = baseAgent.Loot().Shields().Iterator()
-	allocateRandomly(iterator, ids, lootAllocation)
+	allocateRandomly(baseAgent.Rand(), iterator, ids, lootAllocation)
 	iterator = baseAgent.Loot().HpPotions().Iterator()
-	allocateRandomly(iterator, ids, lootAllocation)
+	allocateRandomly(baseAgent.Rand(), iterator, ids, lootAllocation)
 	iterator = baseAgent.Loot().StaminaPotions().Iterator()
-	allocateRandomly(iterator, ids, lootAllocation)
+	allocateRandomly(baseAgent.Rand(), iterator, ids, lootAllocation)
 	mMapped := make(map[commons.ID]immutable.SortedMap[commons.ItemID, struct{}])
 	for id, itemIDS := range lootAllocation {
 		mMapped[id] = commons.ListToImmutableSortedSet(itemIDS)
@@ -122,10 +123,10 @@ func (a *AgentThree) LootAllocation(baseAgent agent.BaseAgent, proposal message.
 // 	}
 // }
 
-func allocateRandomly(iterator commons.Iterator[state.Item], ids []commons.ID, lootAllocation map[commons.ID][]commons.ItemID) {
+func allocateRandomly(r *rand.Rand, iterator commons.Iterator[state.Item], ids []commons.ID, lootAllocation map[commons.ID][]commons.ItemID) {
 	for !iterator.Done() {
 		next, _ := iterator.Next()
-		toBeAllocated := ids[rand.Intn(len(ids))]
+		toBeAllocated := ids[r.Intn(len(ids))]
 		l, ok := lootAllocation[toBeAllocated]
 		if !ok {
 			l = make([]commons.ItemID, 0)
@@ -154,8 +155,8 @@ func (a *AgentThree) willSanctionConstant(agent agent.Agent) int {
 	return sanction
 }
 
-func (a *AgentThree) sanctioningGraduated(agent agent.Agent) int {
-	maxDur := cmdline.CmdLineInits.MaxGraduatedSanctionDuration
+func (a *AgentThree) sanctioningGraduated(agent agent.Agent, cmdParams cmdline.CmdLine) int {
+	maxDur := cmdParams.MaxGraduatedSanctionDuration
 	agentId := agent.ID()
 	prevSanctions := a.sanctionHistory[agentId]
 	sanctionDur := 1
@@ -167,8 +168,8 @@ func (a *AgentThree) sanctioningGraduated(agent agent.Agent) int {
 	return commons.Min(sanctionDur, maxDur)
 }
 
-func (a *AgentThree) sanctioningDynamic(agent agent.Agent) int {
-	initialDur := cmdline.CmdLineInits.FixedSanctionDuration
+func (a *AgentThree) sanctioningDynamic(agent agent.Agent, cmdParams cmdline.CmdLine, calc *statscalc.StatsCalc) int {
+	initialDur := cmdParams.FixedSanctionDuration
 	agentId := agent.ID()
 	prevSanctions := a.sanctionHistory[agentId]
 	if len(prevSanctions) == 0 {
@@ -181,8 +182,8 @@ func (a *AgentThree) sanctioningDynamic(agent agent.Agent) int {
 	state := agent.AgentState()
 	comparator := float64(state.Hp)
 
-	metricMean := statscalc.Calc.GetMean(metricToEqualise)
-	metricStdDev := statscalc.Calc.GetStdDev(metricToEqualise)
+	metricMean := calc.GetMean(metricToEqualise)
+	metricStdDev := calc.GetStdDev(metricToEqualise)
 
 	lowerThresh := metricMean - metricStdDev
 	upperThresh := metricMean + metricStdDev
@@ -230,16 +231,16 @@ func (a *AgentThree) updateSanctionMap(id commons.ID, sanction sanctions.Sanctio
 
 func (a *AgentThree) SortAgentsRep(prunedMap map[commons.ID]agent.Agent) []agent.Agent {
 	// extract keys of agents
-	var agents []agent.Agent
+	ids := maps.Keys(prunedMap)
+	sort.Strings(ids)
 
-	for _, ag := range prunedMap {
-		// if _, ok := m2[key]; ok {
-		agents = append(agents, ag)
-		// }
+	agents := make([]agent.Agent, 0, len(ids))
+	for _, id := range ids {
+		agents = append(agents, prunedMap[id])
 	}
 
 	// sort them according to (leaders, i.e. this agent) reputation, desc
-	sort.Slice(agents, func(i, j int) bool {
+	sort.SliceStable(agents, func(i, j int) bool {
 		return a.reputationMap[agents[i].ID()] > a.reputationMap[agents[j].ID()]
 	})
 
@@ -250,12 +251,18 @@ func (a *AgentThree) SortAgentsArray(agentMap map[commons.ID]agent.Agent) []agen
 	return a.SortAgentsRep(agentMap)
 }
 
-func (a *AgentThree) PruneAgentList(agentMap map[commons.ID]agent.Agent) map[commons.ID]agent.Agent {
+func (a *AgentThree) PruneAgentList(baseAgent agent.BaseAgent, agentMap map[commons.ID]agent.Agent) map[commons.ID]agent.Agent {
 
-	cmdParams := cmdline.CmdLineInits
+	view := baseAgent.View()
+	cmdParams := view.SanctionConfig()
+	calc := statscalc.MakeStatsCalc(agentMap)
+
+	ids := maps.Keys(agentMap)
+	sort.Strings(ids)
 
 	pruned := make(map[commons.ID]agent.Agent)
-	for id, agent := range agentMap {
+	for _, id := range ids {
+		agent := agentMap[id]
 
 		currentSanction, sanctionExists := a.activeSanctionMap[id]
 		if sanctionExists {
@@ -264,16 +271,16 @@ func (a *AgentThree) PruneAgentList(agentMap map[commons.ID]agent.Agent) map[com
 		}
 
 		// Compare to 50 in order to sanction
-		toSanctionOrNot := rand.Intn(100)
+		toSanctionOrNot := baseAgent.Rand().Intn(100)
 		if toSanctionOrNot > a.willSanctionConstant(agent) {
 			pruned[id] = agent
 		} else {
 			// agent has been pruned. Choose sanction duration
 			var sanctionDuration int
 			if cmdParams.DynamicSanctions {
-				sanctionDuration = a.sanctioningDynamic(agent)
+				sanctionDuration = a.sanctioningDynamic(agent, cmdParams, calc)
 			} else if cmdParams.GraduatedSanctions {
-				sanctionDuration = a.sanctioningGraduated(agent)
+				sanctionDuration = a.sanctioningGraduated(agent, cmdParams)
 			} else {
 				sanctionDuration = cmdParams.FixedSanctionDuration
 			}
@@ -7,7 +7,6 @@ import (
 	"infra/game/message"
 	"infra/game/message/proposal"
 	"infra/game/state"
-	"math/rand"
 	"sort"
 
 	"github.com/benbjohnson/immutable"
@@ -35,28 +34,28 @@ func (a *AgentThree) LootActionNoProposal(baseAgent agent.BaseAgent) immutable.S
 
 	for !weapons.Done() {
 		weapon, _ := weapons.Next()
-		if rand.Int()%2 == 0 {
+		if baseAgent.Rand().Int()%2 == 0 {
 			builder.Set(weapon.Id(), struct{}{})
 		}
 	}
 
 	for !shields.Done() {
 		shield, _ := shields.Next()
-		if rand.Int()%2 == 0 {
+		if baseAgent.Rand().Int()%2 == 0 {
 			builder.Set(shield.Id(), struct{}{})
 		}
 	}
 
 	for !hpPotions.Done() {
 		pot, _ := hpPotions.Next()
-		if rand.Int()%2 == 0 {
+		if baseAgent.Rand().Int()%2 == 0 {
 			builder.Set(pot.Id(), struct{}{})
 		}
 	}
 
 	for !staminaPotions.Done() {
 		pot, _ := staminaPotions.Next()
-		if rand.Int()%2 == 0 {
+		if baseAgent.Rand().Int()%2 == 0 {
 			builder.Set(pot.Id(), struct{}{})
 		}
 	}
@@ -80,7 +79,7 @@ func (a *AgentThree) HandleLootInformation(m message.TaggedInformMessage[message
 	switch m.Message().(type) {
 	case message.LootInform:
 		// Send Proposal?
-		sendProposal := rand.Intn(100)
+		sendProposal := baseAgent.Rand().Intn(100)
 		if sendProposal < a.personality {
 			// general and send a loot proposal
 			baseAgent.SendLootProposalToLeader(a.generateLootProposal(baseAgent))
@@ -93,7 +92,7 @@ func (a *AgentThree) HandleLootInformation(m message.TaggedInformMessage[message
 // forcibly call at start of loot phase to begin proceedings
 func (a *AgentThree) RequestLootProposal(baseAgent agent.BaseAgent) { // put your logic here now, instead
 	a.mutex.Lock()
-	sendProposal := rand.Intn(100)
+	sendProposal := baseAgent.Rand().Intn(100)
 	if sendProposal > a.personality {
 		a.mutex.Unlock()
 		return
@@ -103,13 +102,13 @@ func (a *AgentThree) RequestLootProposal(baseAgent agent.BaseAgent) { // put you
 	baseAgent.SendLootProposalToLeader(a.generateLootProposal(baseAgent))
 }
 
-func (a *AgentThree) HandleLootProposal(_ message.Proposal[decision.LootAction], _ agent.BaseAgent) decision.Intent {
+func (a *AgentThree) HandleLootProposal(_ message.Proposal[decision.LootAction], baseAgent agent.BaseAgent) decision.Intent {
 	// vote on the loot proposal
 	// do i vote?
-	toVote := rand.Intn(100)
+	toVote := baseAgent.Rand().Intn(100)
 	if toVote < a.personality {
 		// Enter logic for evaluating a loot proposal here
-		switch rand.Intn(2) {
+		switch baseAgent.Rand().Intn(2) {
 		case 0:
 			return decision.Positive
 		default:
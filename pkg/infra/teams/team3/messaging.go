@@ -4,7 +4,10 @@ import (
 	"infra/game/agent"
 	"infra/game/commons"
 	"infra/game/message"
+	"sort"
 	"sync"
+
+	"golang.org/x/exp/maps"
 )
 
 func agentInList(agentID commons.ID, messageList []commons.ID) bool {
@@ -35,7 +38,9 @@ func (a *AgentThree) CompileTrustMessage(agentMap map[commons.ID]agent.Agent) me
 	}
 
 	// Then fill remaining spots from the rest
-	for k := range agentMap {
+	ids := maps.Keys(agentMap)
+	sort.Strings(ids)
+	for _, k := range ids {
 		if i == num {
 			break
 		}
@@ -78,7 +83,11 @@ func (a *AgentThree) HandleTrustMessage(m message.TaggedMessage) {
 	// Gossip IS reputation map ---> one thread will read it, one thread will write it.
 	// Shallow copy introduced in Compile
 
-	for id, sentRep := range t.Gossip {
+	// in ID order, as the sender's own reputation can change part way through
+	gossipIDs := maps.Keys(t.Gossip)
+	sort.Strings(gossipIDs)
+	for _, id := range gossipIDs {
+		sentRep := t.Gossip[id]
 		ourRep, exists := a.reputationMap[id]
 		if exists {
 			diff := ourRep - sentRep
@@ -0,0 +1,133 @@
+package team3
+
+import (
+	"encoding/json"
+
+	"infra/game/agent"
+	"infra/game/commons"
+	sanctions "infra/sanctionUtils"
+)
+
+// snapshot is the part of AgentThree that carries over from one level to the
+// next. The last level's fight rounds are left out, as they are replaced
+// before they are next read.
+type snapshot struct {
+	AT                int
+	SH                int
+	NumAgents         int
+	Personality       int
+	UpdatePersonality bool
+	TSN               []commons.ID
+	ReputationMap     map[commons.ID]float64
+	SocialCap         map[commons.ID]int
+	W1Map             map[commons.ID]float64
+	W2Map             map[commons.ID]float64
+	PastHPMap         map[commons.ID]int
+	PastStaminaMap    map[commons.ID]int
+	StatsQueue        StatsQueue
+	ChangeInit        float64
+	Alpha             float64
+	SamplePercent     float64
+	UR                map[commons.ID]int
+	UP                map[commons.ID]int
+	UC                map[commons.ID]int
+	UtilityScore      map[commons.ID]int
+	ContactsLastRound map[commons.ID]bool
+	ChairTolerance    int
+	ProposalTolerance map[commons.ID]int
+	Sanctioned        int
+	SanctionHistory   map[commons.ID][]int
+	ActiveSanctionMap map[commons.ID]sanctions.SanctionActivity
+	SanctionLength    int
+}
+
+func (a *AgentThree) Snapshot(baseAgent agent.BaseAgent) ([]byte, error) {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+
+	return json.Marshal(snapshot{
+		AT:                a.AT,
+		SH:                a.SH,
+		NumAgents:         a.numAgents,
+		Personality:       a.personality,
+		UpdatePersonality: a.updatePersonality,
+		TSN:               a.TSN,
+		ReputationMap:     a.reputationMap,
+		SocialCap:         a.socialCap,
+		W1Map:             a.w1Map,
+		W2Map:             a.w2Map,
+		PastHPMap:         a.pastHPMap,
+		PastStaminaMap:    a.pastStaminaMap,
+		StatsQueue:        a.statsQueue,
+		ChangeInit:        a.changeInit,
+		Alpha:             a.alpha,
+		SamplePercent:     a.samplePercent,
+		UR:                a.uR,
+		UP:                a.uP,
+		UC:                a.uC,
+		UtilityScore:      a.utilityScore,
+		ContactsLastRound: a.contactsLastRound,
+		ChairTolerance:    a.chairTolerance,
+		ProposalTolerance: a.proposalTolerance,
+		Sanctioned:        a.sanctioned,
+		SanctionHistory:   a.sanctionHistory,
+		ActiveSanctionMap: a.activeSanctionMap,
+		SanctionLength:    a.sanctionLength,
+	})
+}
+
+func (a *AgentThree) Restore(baseAgent agent.BaseAgent, data []byte) error {
+	var s snapshot
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	a.AT = s.AT
+	a.SH = s.SH
+	a.numAgents = s.NumAgents
+	a.personality = s.Personality
+	a.updatePersonality = s.UpdatePersonality
+	a.TSN = s.TSN
+	a.reputationMap = orEmpty(s.ReputationMap)
+	a.socialCap = orEmpty(s.SocialCap)
+	a.w1Map = orEmpty(s.W1Map)
+	a.w2Map = orEmpty(s.W2Map)
+	a.pastHPMap = orEmpty(s.PastHPMap)
+	a.pastStaminaMap = orEmpty(s.PastStaminaMap)
+	a.statsQueue = s.StatsQueue
+	a.changeInit = s.ChangeInit
+	a.alpha = s.Alpha
+	a.samplePercent = s.SamplePercent
+	a.uR = orEmpty(s.UR)
+	a.uP = orEmpty(s.UP)
+	a.uC = orEmpty(s.UC)
+	a.utilityScore = orEmpty(s.UtilityScore)
+	a.contactsLastRound = s.ContactsLastRound
+	a.chairTolerance = s.ChairTolerance
+	a.proposalTolerance = orEmpty(s.ProposalTolerance)
+	a.sanctioned = s.Sanctioned
+	a.sanctionHistory = orEmpty(s.SanctionHistory)
+	a.activeSanctionMap = orEmpty(s.ActiveSanctionMap)
+	a.sanctionLength = s.SanctionLength
+
+	// persistent sanctions live in the game's ledger, which is restored with
+	// the rest of the game state
+	view := baseAgent.View()
+	if view.SanctionConfig().PersistentSanctions {
+		ledger := view.SanctionLedger()
+		a.activeSanctionMap = ledger.Active
+		a.sanctionHistory = ledger.History
+	}
+	return nil
+}
+
+// orEmpty makes sure a restored map can be written to.
+func orEmpty[V any](m map[commons.ID]V) map[commons.ID]V {
+	if m == nil {
+		return make(map[commons.ID]V)
+	}
+	return m
+}
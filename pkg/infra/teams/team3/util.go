@@ -5,8 +5,10 @@ import (
 	"infra/game/commons"
 	"infra/game/decision"
 	"infra/game/message"
+	"sort"
 
 	"github.com/benbjohnson/immutable"
+	"golang.org/x/exp/maps"
 )
 
 // Resource (trading) utility
@@ -112,9 +114,12 @@ func (a *AgentThree) RemoveFromTSN(id commons.ID) []commons.ID {
 
 // Update TSN
 func (a *AgentThree) UpdateTSN(baseAgent agent.BaseAgent) {
-	for id, rep := range a.reputationMap {
+	// in ID order, as the TSN order decides who gets trust messages first
+	ids := maps.Keys(a.reputationMap)
+	sort.Strings(ids)
+	for _, id := range ids {
 		// Add to TSN based on both reputation and SC
-		score := rep + float64(a.socialCap[id])
+		score := a.reputationMap[id] + float64(a.socialCap[id])
 		if score >= 125 {
 			a.AddToTSN(id)
 		} else {